@@ -0,0 +1,477 @@
+// Package config loads the server settings from, in increasing precedence,
+// built-in defaults, a YAML or TOML file, environment variables and flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+
+	// DefaultJWTSecret is only good for development; Validate rejects it in
+	// production.
+	DefaultJWTSecret = "BlackBox"
+)
+
+var (
+	ErrDefaultJWTSecret = errors.New("the default JWT secret must not be used in production; set JWT_SECRET")
+	ErrUnknownFormat    = errors.New("config file must end in .yaml, .yml or .toml")
+)
+
+type Config struct {
+	Environment string          `yaml:"environment" toml:"environment"`
+	HTTP        HTTPConfig      `yaml:"http" toml:"http"`
+	GRPC        GRPCConfig      `yaml:"grpc" toml:"grpc"`
+	Mongo       MongoConfig     `yaml:"mongo" toml:"mongo"`
+	JWT         JWTConfig       `yaml:"jwt" toml:"jwt"`
+	SMTP        SMTPConfig      `yaml:"smtp" toml:"smtp"`
+	OpenAPI     OpenAPIConfig   `yaml:"openapi" toml:"openapi"`
+	Log         LogConfig       `yaml:"log" toml:"log"`
+	Tracing     TracingConfig   `yaml:"tracing" toml:"tracing"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Login       LoginConfig     `yaml:"login" toml:"login"`
+	Policy      PolicyConfig    `yaml:"policy" toml:"policy"`
+}
+
+type HTTPConfig struct {
+	Addr string `yaml:"addr" toml:"addr"`
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// after SIGTERM.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// TrustedProxies may set X-Forwarded-For. With none, the client IP used
+	// for rate limiting is the peer address.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+type GRPCConfig struct {
+	Addr string `yaml:"addr" toml:"addr"`
+}
+
+type MongoConfig struct {
+	URI      string `yaml:"uri" toml:"uri"`
+	Database string `yaml:"database" toml:"database"`
+}
+
+// JWTConfig sets the lifetime of access tokens (Expiry) and of the refresh
+// tokens that renew them (RefreshExpiry). Tokens are signed with the HS256
+// Secret until Keys lists RS256 or EdDSA keys; a key replaced by a newer one
+// still verifies tokens for RotationGrace. Tokens are only accepted for
+// Issuer and Audience, with Leeway for clock skew between servers. The auth
+// middleware caches the users behind tokens for UserCacheTTL, so role
+// changes and disables reach other instances within that time.
+type JWTConfig struct {
+	Secret        string         `yaml:"secret" toml:"secret"`
+	Expiry        Duration       `yaml:"expiry" toml:"expiry"`
+	RefreshExpiry Duration       `yaml:"refresh_expiry" toml:"refresh_expiry"`
+	Keys          []JWTKeyConfig `yaml:"keys" toml:"keys"`
+	RotationGrace Duration       `yaml:"rotation_grace" toml:"rotation_grace"`
+	Issuer        string         `yaml:"issuer" toml:"issuer"`
+	Audience      string         `yaml:"audience" toml:"audience"`
+	Leeway        Duration       `yaml:"leeway" toml:"leeway"`
+	UserCacheTTL  Duration       `yaml:"user_cache_ttl" toml:"user_cache_ttl"`
+}
+
+// JWTKeyConfig is a PEM private key that signs tokens from ActivatesAt (zero
+// means straight away) until the next key activates.
+type JWTKeyConfig struct {
+	ID             string    `yaml:"id" toml:"id"`
+	Algorithm      string    `yaml:"algorithm" toml:"algorithm"`
+	PrivateKeyFile string    `yaml:"private_key_file" toml:"private_key_file"`
+	ActivatesAt    time.Time `yaml:"activates_at" toml:"activates_at"`
+}
+
+// SMTPConfig leaves email notifications off while Addr is empty.
+type SMTPConfig struct {
+	Addr     string `yaml:"addr" toml:"addr"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	From     string `yaml:"from" toml:"from"`
+}
+
+type OpenAPIConfig struct {
+	Validation bool `yaml:"validation" toml:"validation"`
+}
+
+// LogConfig sets the minimum level logged: debug, info, warn or error.
+type LogConfig struct {
+	Level slog.Level `yaml:"level" toml:"level"`
+}
+
+// TracingConfig picks where spans go. The OTLP exporter takes its endpoint
+// and headers from the standard OTEL_EXPORTER_OTLP_* variables.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+type RateLimitConfig struct {
+	Enabled  bool            `yaml:"enabled" toml:"enabled"`
+	Login    RateLimitPolicy `yaml:"login" toml:"login"`
+	Register RateLimitPolicy `yaml:"register" toml:"register"`
+	Refresh  RateLimitPolicy `yaml:"refresh" toml:"refresh"`
+	API      RateLimitPolicy `yaml:"api" toml:"api"`
+}
+
+// RateLimitPolicy allows bursts of Burst requests, refilled at Requests per
+// Per.
+type RateLimitPolicy struct {
+	Requests int      `yaml:"requests" toml:"requests"`
+	Per      Duration `yaml:"per" toml:"per"`
+	Burst    int      `yaml:"burst" toml:"burst"`
+}
+
+// LoginConfig slows down failed logins after FreeAttempts, and locks a
+// username or client address out for LockoutDuration once it reaches its
+// threshold within Window.
+type LoginConfig struct {
+	FreeAttempts    int      `yaml:"free_attempts" toml:"free_attempts"`
+	MaxDelay        Duration `yaml:"max_delay" toml:"max_delay"`
+	UserThreshold   int      `yaml:"user_threshold" toml:"user_threshold"`
+	IPThreshold     int      `yaml:"ip_threshold" toml:"ip_threshold"`
+	Window          Duration `yaml:"window" toml:"window"`
+	LockoutDuration Duration `yaml:"lockout_duration" toml:"lockout_duration"`
+}
+
+// PolicyConfig points at the task policy file, which is checked for changes
+// every ReloadInterval. Without a File, tasks are governed by roles alone.
+type PolicyConfig struct {
+	File           string   `yaml:"file" toml:"file"`
+	ReloadInterval Duration `yaml:"reload_interval" toml:"reload_interval"`
+}
+
+// Duration is a time.Duration written as "24h" or "90m" in config files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func Default() Config {
+	return Config{
+		Environment: EnvDevelopment,
+		HTTP:        HTTPConfig{Addr: ":8080", ShutdownTimeout: Duration(15 * time.Second)},
+		GRPC:        GRPCConfig{Addr: ":9090"},
+		Mongo:       MongoConfig{URI: "mongodb://localhost:27017", Database: "taskmanagerdb"},
+		JWT: JWTConfig{
+			Secret:        DefaultJWTSecret,
+			Expiry:        Duration(15 * time.Minute),
+			RefreshExpiry: Duration(7 * 24 * time.Hour),
+			RotationGrace: Duration(time.Hour),
+			Issuer:        "task-manager",
+			Audience:      "task-manager-api",
+			Leeway:        Duration(30 * time.Second),
+			UserCacheTTL:  Duration(5 * time.Second),
+		},
+		Log:     LogConfig{Level: slog.LevelInfo},
+		Tracing: TracingConfig{Exporter: TracingNone, SampleRatio: 1},
+		RateLimit: RateLimitConfig{
+			Enabled:  true,
+			Login:    RateLimitPolicy{Requests: 10, Per: Duration(time.Minute), Burst: 5},
+			Register: RateLimitPolicy{Requests: 10, Per: Duration(time.Hour), Burst: 5},
+			Refresh:  RateLimitPolicy{Requests: 30, Per: Duration(time.Minute), Burst: 10},
+			API:      RateLimitPolicy{Requests: 300, Per: Duration(time.Minute), Burst: 100},
+		},
+		Login: LoginConfig{
+			FreeAttempts:    3,
+			MaxDelay:        Duration(2 * time.Second),
+			UserThreshold:   10,
+			IPThreshold:     50,
+			Window:          Duration(15 * time.Minute),
+			LockoutDuration: Duration(15 * time.Minute),
+		},
+		Policy: PolicyConfig{ReloadInterval: Duration(10 * time.Second)},
+	}
+}
+
+func (c Config) IsProduction() bool {
+	return c.Environment == EnvProduction
+}
+
+func (c Config) Validate() error {
+	switch {
+	case c.Environment != EnvDevelopment && c.Environment != EnvProduction:
+		return fmt.Errorf("environment must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Environment)
+	case c.HTTP.Addr == "":
+		return errors.New("http.addr is required")
+	case c.HTTP.ShutdownTimeout <= 0:
+		return errors.New("http.shutdown_timeout must be positive")
+	case c.GRPC.Addr == "":
+		return errors.New("grpc.addr is required")
+	case c.Mongo.URI == "":
+		return errors.New("mongo.uri is required")
+	case c.Mongo.Database == "":
+		return errors.New("mongo.database is required")
+	case c.JWT.Secret == "":
+		return errors.New("jwt.secret is required")
+	case c.JWT.Expiry <= 0:
+		return errors.New("jwt.expiry must be positive")
+	case c.JWT.RefreshExpiry <= c.JWT.Expiry:
+		return errors.New("jwt.refresh_expiry must be longer than jwt.expiry")
+	case c.JWT.Issuer == "" || c.JWT.Audience == "":
+		return errors.New("jwt.issuer and jwt.audience are required")
+	case c.JWT.Leeway < 0 || c.JWT.Leeway > Duration(5*time.Minute):
+		return errors.New("jwt.leeway must be between 0 and 5m")
+	case c.JWT.UserCacheTTL < 0 || c.JWT.UserCacheTTL > c.JWT.Expiry:
+		return errors.New("jwt.user_cache_ttl must be between 0 and jwt.expiry")
+	case c.Tracing.Exporter != TracingNone && c.Tracing.Exporter != TracingStdout && c.Tracing.Exporter != TracingOTLP:
+		return fmt.Errorf("tracing.exporter must be %q, %q or %q, got %q", TracingNone, TracingStdout, TracingOTLP, c.Tracing.Exporter)
+	case c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1:
+		return errors.New("tracing.sample_ratio must be between 0 and 1")
+	case c.Login.FreeAttempts < 0 || c.Login.MaxDelay < 0:
+		return errors.New("login.free_attempts and login.max_delay must not be negative")
+	case c.Login.UserThreshold <= 0 || c.Login.IPThreshold <= 0:
+		return errors.New("login.user_threshold and login.ip_threshold must be positive")
+	case c.Login.Window <= 0 || c.Login.LockoutDuration <= 0:
+		return errors.New("login.window and login.lockout_duration must be positive")
+	case c.Policy.ReloadInterval <= 0:
+		return errors.New("policy.reload_interval must be positive")
+	case c.IsProduction() && len(c.JWT.Keys) == 0 && c.JWT.Secret == DefaultJWTSecret:
+		return ErrDefaultJWTSecret
+	}
+	if err := c.JWT.validateKeys(); err != nil {
+		return err
+	}
+	if c.RateLimit.Enabled {
+		for name, policy := range map[string]RateLimitPolicy{
+			"login":    c.RateLimit.Login,
+			"register": c.RateLimit.Register,
+			"refresh":  c.RateLimit.Refresh,
+			"api":      c.RateLimit.API,
+		} {
+			if policy.Requests <= 0 || policy.Per <= 0 || policy.Burst <= 0 {
+				return fmt.Errorf("rate_limit.%s needs positive requests, per and burst", name)
+			}
+		}
+	}
+	return nil
+}
+
+func (c JWTConfig) validateKeys() error {
+	if len(c.Keys) == 0 {
+		return nil
+	}
+	if c.RotationGrace < c.Expiry {
+		return errors.New("jwt.rotation_grace must be at least jwt.expiry, or rotated-out tokens fail early")
+	}
+	seen := make(map[string]bool, len(c.Keys))
+	for _, key := range c.Keys {
+		switch {
+		case key.ID == "":
+			return errors.New("jwt.keys need an id")
+		case seen[key.ID]:
+			return fmt.Errorf("jwt.keys: id %q is listed twice", key.ID)
+		case key.Algorithm != "RS256" && key.Algorithm != "EdDSA":
+			return fmt.Errorf("jwt.keys[%s].algorithm must be RS256 or EdDSA, got %q", key.ID, key.Algorithm)
+		case key.PrivateKeyFile == "":
+			return fmt.Errorf("jwt.keys[%s].private_key_file is required", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	return nil
+}
+
+// UsageError is returned for a command line that does not parse, or for
+// -h, in which case Err is flag.ErrHelp. Usage is what the flag package
+// would have printed.
+type UsageError struct {
+	Err   error
+	Usage string
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// Load builds the configuration for a command line (without the program
+// name) and an environment lookup such as os.Getenv. Flag parsing stops at
+// the first non-flag argument; the remaining arguments are returned.
+func Load(args []string, getenv func(string) string) (Config, []string, error) {
+	cfg := Default()
+
+	var usage strings.Builder
+	fs := flag.NewFlagSet("task-manager", flag.ContinueOnError)
+	fs.SetOutput(&usage)
+	configFile := fs.String("config", getenv("CONFIG_FILE"), "YAML or TOML config file")
+	environment := fs.String("env", "", "development or production")
+	httpAddr := fs.String("http-addr", "", "HTTP listen address")
+	grpcAddr := fs.String("grpc-addr", "", "gRPC listen address")
+	mongoURI := fs.String("mongo-uri", "", "MongoDB connection string")
+	mongoDatabase := fs.String("mongo-database", "", "MongoDB database name")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "how long to drain requests on shutdown")
+	jwtExpiry := fs.Duration("jwt-expiry", 0, "lifetime of access tokens")
+	openAPIValidation := fs.Bool("openapi-validation", false, "validate requests against the OpenAPI spec")
+	logLevel := fs.String("log-level", "", "debug, info, warn or error")
+	tracingExporter := fs.String("tracing-exporter", "", "none, stdout or otlp")
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, &UsageError{Err: err, Usage: usage.String()}
+	}
+
+	if *configFile != "" {
+		if err := loadFile(*configFile, &cfg); err != nil {
+			return cfg, nil, err
+		}
+	}
+
+	if err := applyEnv(&cfg, getenv); err != nil {
+		return cfg, nil, err
+	}
+
+	if *logLevel != "" {
+		if err := cfg.Log.Level.UnmarshalText([]byte(*logLevel)); err != nil {
+			return cfg, nil, fmt.Errorf("-log-level: %w", err)
+		}
+	}
+
+	// Secrets have no flags, since flags show up in process listings.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "env":
+			cfg.Environment = *environment
+		case "http-addr":
+			cfg.HTTP.Addr = *httpAddr
+		case "shutdown-timeout":
+			cfg.HTTP.ShutdownTimeout = Duration(*shutdownTimeout)
+		case "grpc-addr":
+			cfg.GRPC.Addr = *grpcAddr
+		case "mongo-uri":
+			cfg.Mongo.URI = *mongoURI
+		case "mongo-database":
+			cfg.Mongo.Database = *mongoDatabase
+		case "jwt-expiry":
+			cfg.JWT.Expiry = Duration(*jwtExpiry)
+		case "openapi-validation":
+			cfg.OpenAPI.Validation = *openAPIValidation
+		case "tracing-exporter":
+			cfg.Tracing.Exporter = *tracingExporter
+		}
+	})
+
+	return cfg, fs.Args(), cfg.Validate()
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	default:
+		return fmt.Errorf("%s: %w", path, ErrUnknownFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config, getenv func(string) string) error {
+	stringVars := map[string]*string{
+		"APP_ENV":          &cfg.Environment,
+		"HTTP_ADDR":        &cfg.HTTP.Addr,
+		"GRPC_ADDR":        &cfg.GRPC.Addr,
+		"MONGO_URI":        &cfg.Mongo.URI,
+		"MONGO_DATABASE":   &cfg.Mongo.Database,
+		"JWT_SECRET":       &cfg.JWT.Secret,
+		"JWT_ISSUER":       &cfg.JWT.Issuer,
+		"JWT_AUDIENCE":     &cfg.JWT.Audience,
+		"SMTP_ADDR":        &cfg.SMTP.Addr,
+		"SMTP_USERNAME":    &cfg.SMTP.Username,
+		"SMTP_PASSWORD":    &cfg.SMTP.Password,
+		"SMTP_FROM":        &cfg.SMTP.From,
+		"TRACING_EXPORTER": &cfg.Tracing.Exporter,
+		"POLICY_FILE":      &cfg.Policy.File,
+	}
+	for name, field := range stringVars {
+		if value := getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	durationVars := map[string]*Duration{
+		"HTTP_SHUTDOWN_TIMEOUT":  &cfg.HTTP.ShutdownTimeout,
+		"JWT_EXPIRY":             &cfg.JWT.Expiry,
+		"JWT_REFRESH_EXPIRY":     &cfg.JWT.RefreshExpiry,
+		"JWT_ROTATION_GRACE":     &cfg.JWT.RotationGrace,
+		"JWT_LEEWAY":             &cfg.JWT.Leeway,
+		"JWT_USER_CACHE_TTL":     &cfg.JWT.UserCacheTTL,
+		"LOGIN_LOCKOUT_DURATION": &cfg.Login.LockoutDuration,
+		"POLICY_RELOAD_INTERVAL": &cfg.Policy.ReloadInterval,
+	}
+	for name, field := range durationVars {
+		if value := getenv(name); value != "" {
+			if err := field.UnmarshalText([]byte(value)); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	if value := getenv("OPENAPI_VALIDATION"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("OPENAPI_VALIDATION: %w", err)
+		}
+		cfg.OpenAPI.Validation = enabled
+	}
+	if value := getenv("RATE_LIMIT_ENABLED"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("RATE_LIMIT_ENABLED: %w", err)
+		}
+		cfg.RateLimit.Enabled = enabled
+	}
+	if value := getenv("HTTP_TRUSTED_PROXIES"); value != "" {
+		cfg.HTTP.TrustedProxies = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if value := getenv("TRACING_SAMPLE_RATIO"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("TRACING_SAMPLE_RATIO: %w", err)
+		}
+		cfg.Tracing.SampleRatio = ratio
+	}
+	if value := getenv("LOG_LEVEL"); value != "" {
+		if err := cfg.Log.Level.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("LOG_LEVEL: %w", err)
+		}
+	}
+	return nil
+}
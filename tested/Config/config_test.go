@@ -0,0 +1,301 @@
+package config
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, args, err := Load(nil, env(nil))
+
+	require.NoError(t, err)
+	assert.Empty(t, args)
+	assert.Equal(t, Default(), cfg)
+	assert.Equal(t, "mongodb://localhost:27017", cfg.Mongo.URI)
+	assert.Equal(t, "taskmanagerdb", cfg.Mongo.Database)
+	assert.Equal(t, ":8080", cfg.HTTP.Addr)
+	assert.Equal(t, Duration(15*time.Minute), cfg.JWT.Expiry)
+	assert.Equal(t, Duration(7*24*time.Hour), cfg.JWT.RefreshExpiry)
+	assert.Equal(t, Duration(15*time.Second), cfg.HTTP.ShutdownTimeout)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+http:
+  addr: ":7000"
+grpc:
+  addr: ":7001"
+mongo:
+  uri: mongodb://file:27017
+  database: fromfile
+jwt:
+  secret: file-secret
+  expiry: 2h
+log:
+  level: error
+`)
+
+	cfg, _, err := Load(
+		[]string{"-config", path, "-http-addr", ":9000", "-log-level", "debug"},
+		env(map[string]string{"HTTP_ADDR": ":8000", "GRPC_ADDR": ":8001", "JWT_SECRET": "env-secret", "LOG_LEVEL": "warn"}),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, ":9000", cfg.HTTP.Addr, "flag beats env")
+	assert.Equal(t, ":8001", cfg.GRPC.Addr, "env beats file")
+	assert.Equal(t, "env-secret", cfg.JWT.Secret)
+	assert.Equal(t, "mongodb://file:27017", cfg.Mongo.URI, "file beats default")
+	assert.Equal(t, "fromfile", cfg.Mongo.Database)
+	assert.Equal(t, Duration(2*time.Hour), cfg.JWT.Expiry)
+	assert.Equal(t, slog.LevelDebug, cfg.Log.Level)
+}
+
+func TestLoad_TOMLFileFromEnv(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+environment = "production"
+
+[jwt]
+secret = "a-real-secret"
+expiry = "30m"
+
+[smtp]
+addr = "mail:25"
+
+[openapi]
+validation = true
+
+[log]
+level = "warn"
+
+[tracing]
+exporter = "otlp"
+sample_ratio = 0.25
+`)
+
+	cfg, _, err := Load(nil, env(map[string]string{"CONFIG_FILE": path}))
+
+	require.NoError(t, err)
+	assert.True(t, cfg.IsProduction())
+	assert.Equal(t, Duration(30*time.Minute), cfg.JWT.Expiry)
+	assert.Equal(t, "mail:25", cfg.SMTP.Addr)
+	assert.True(t, cfg.OpenAPI.Validation)
+	assert.Equal(t, slog.LevelWarn, cfg.Log.Level)
+	assert.Equal(t, TracingConfig{Exporter: TracingOTLP, SampleRatio: 0.25}, cfg.Tracing)
+	assert.Equal(t, ":8080", cfg.HTTP.Addr)
+}
+
+func TestLoad_UnknownKeysAreRejected(t *testing.T) {
+	yamlPath := writeFile(t, "config.yaml", "mongo:\n  url: mongodb://typo\n")
+	_, _, err := Load([]string{"-config", yamlPath}, env(nil))
+	assert.Error(t, err)
+
+	tomlPath := writeFile(t, "config.toml", "[mongo]\nurl = \"mongodb://typo\"\n")
+	_, _, err = Load([]string{"-config", tomlPath}, env(nil))
+	assert.Error(t, err)
+
+	jsonPath := writeFile(t, "config.json", "{}")
+	_, _, err = Load([]string{"-config", jsonPath}, env(nil))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestLoad_ProductionRefusesDefaultSecret(t *testing.T) {
+	_, _, err := Load([]string{"-env", "production"}, env(nil))
+	assert.ErrorIs(t, err, ErrDefaultJWTSecret)
+
+	cfg, _, err := Load([]string{"-env", "production"}, env(map[string]string{"JWT_SECRET": "s3cret"}))
+	assert.NoError(t, err)
+	assert.True(t, cfg.IsProduction())
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	for name, vars := range map[string]map[string]string{
+		"environment":        {"APP_ENV": "staging"},
+		"expiry":             {"JWT_EXPIRY": "tomorrow"},
+		"shutdown timeout":   {"HTTP_SHUTDOWN_TIMEOUT": "0s"},
+		"negative expiry":    {"JWT_EXPIRY": "-1h"},
+		"openapi validation": {"OPENAPI_VALIDATION": "maybe"},
+		"log level":          {"LOG_LEVEL": "loud"},
+		"tracing exporter":   {"TRACING_EXPORTER": "jaeger"},
+		"sample ratio":       {"TRACING_SAMPLE_RATIO": "2"},
+	} {
+		_, _, err := Load(nil, env(vars))
+		assert.Error(t, err, name)
+	}
+
+	_, _, err := Load([]string{"-mongo-database", ""}, env(nil))
+	assert.EqualError(t, err, "mongo.database is required")
+}
+
+func TestLoad_ReturnsRemainingArguments(t *testing.T) {
+	_, args, err := Load([]string{"-grpc-addr", ":1", "admin", "-username", "root"}, env(nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin", "-username", "root"}, args)
+}
+
+func TestLoad_RateLimits(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+http:
+  trusted_proxies: ["10.0.0.0/8"]
+rate_limit:
+  login:
+    requests: 3
+    per: 1m
+    burst: 2
+`)
+
+	cfg, _, err := Load([]string{"-config", path}, env(nil))
+	require.NoError(t, err)
+	assert.True(t, cfg.RateLimit.Enabled)
+	assert.Equal(t, RateLimitPolicy{Requests: 3, Per: Duration(time.Minute), Burst: 2}, cfg.RateLimit.Login)
+	assert.Equal(t, Default().RateLimit.API, cfg.RateLimit.API)
+	assert.Equal(t, Default().RateLimit.Refresh, cfg.RateLimit.Refresh)
+	assert.NotEqual(t, cfg.RateLimit.Login, cfg.RateLimit.Refresh)
+	assert.Equal(t, []string{"10.0.0.0/8"}, cfg.HTTP.TrustedProxies)
+
+	cfg, _, err = Load(nil, env(map[string]string{"HTTP_TRUSTED_PROXIES": "10.0.0.1, 10.0.0.2"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, cfg.HTTP.TrustedProxies)
+
+	zeroBurst := writeFile(t, "config.yaml", "rate_limit:\n  api:\n    burst: 0\n")
+	_, _, err = Load([]string{"-config", zeroBurst}, env(nil))
+	assert.EqualError(t, err, "rate_limit.api needs positive requests, per and burst")
+	zeroRefresh := writeFile(t, "config.yaml", "rate_limit:\n  refresh:\n    requests: 0\n")
+	_, _, err = Load([]string{"-config", zeroRefresh}, env(nil))
+	assert.EqualError(t, err, "rate_limit.refresh needs positive requests, per and burst")
+
+	cfg, _, err = Load([]string{"-config", zeroBurst}, env(map[string]string{"RATE_LIMIT_ENABLED": "false"}))
+	require.NoError(t, err)
+	assert.False(t, cfg.RateLimit.Enabled)
+}
+
+func TestLoad_Login(t *testing.T) {
+	path := writeFile(t, "config.yaml", "login:\n  user_threshold: 5\n  window: 5m\n")
+
+	cfg, _, err := Load([]string{"-config", path}, env(map[string]string{"LOGIN_LOCKOUT_DURATION": "1h"}))
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.Login.UserThreshold)
+	assert.Equal(t, Duration(5*time.Minute), cfg.Login.Window)
+	assert.Equal(t, Duration(time.Hour), cfg.Login.LockoutDuration)
+	assert.Equal(t, Default().Login.IPThreshold, cfg.Login.IPThreshold)
+
+	zeroThreshold := writeFile(t, "config.yaml", "login:\n  ip_threshold: 0\n")
+	_, _, err = Load([]string{"-config", zeroThreshold}, env(nil))
+	assert.EqualError(t, err, "login.user_threshold and login.ip_threshold must be positive")
+}
+
+func TestLoad_RefreshExpiryMustOutliveAccessTokens(t *testing.T) {
+	_, _, err := Load([]string{"-jwt-expiry", "200h"}, env(nil))
+	assert.EqualError(t, err, "jwt.refresh_expiry must be longer than jwt.expiry")
+
+	cfg, _, err := Load([]string{"-jwt-expiry", "200h"}, env(map[string]string{"JWT_REFRESH_EXPIRY": "720h"}))
+	require.NoError(t, err)
+	assert.Equal(t, Duration(720*time.Hour), cfg.JWT.RefreshExpiry)
+}
+
+func TestLoad_JWTKeys(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+environment: production
+jwt:
+  keys:
+    - id: 2024-01
+      algorithm: EdDSA
+      private_key_file: /etc/task-manager/2024-01.pem
+    - id: 2024-07
+      algorithm: RS256
+      private_key_file: /etc/task-manager/2024-07.pem
+      activates_at: 2024-07-01T00:00:00Z
+`)
+
+	cfg, _, err := Load([]string{"-config", path}, env(nil))
+	require.NoError(t, err, "keys replace the default secret in production")
+	require.Len(t, cfg.JWT.Keys, 2)
+	assert.Equal(t, JWTKeyConfig{
+		ID:             "2024-07",
+		Algorithm:      "RS256",
+		PrivateKeyFile: "/etc/task-manager/2024-07.pem",
+		ActivatesAt:    time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	}, cfg.JWT.Keys[1])
+	assert.Equal(t, Duration(time.Hour), cfg.JWT.RotationGrace)
+
+	_, _, err = Load([]string{"-config", path}, env(map[string]string{"JWT_ROTATION_GRACE": "5m"}))
+	assert.EqualError(t, err, "jwt.rotation_grace must be at least jwt.expiry, or rotated-out tokens fail early")
+
+	badAlgorithm := writeFile(t, "config.yaml", "jwt:\n  keys:\n    - id: a\n      algorithm: HS512\n      private_key_file: a.pem\n")
+	_, _, err = Load([]string{"-config", badAlgorithm}, env(nil))
+	assert.EqualError(t, err, `jwt.keys[a].algorithm must be RS256 or EdDSA, got "HS512"`)
+
+	duplicate := writeFile(t, "config.yaml", "jwt:\n  keys:\n    - {id: a, algorithm: EdDSA, private_key_file: a.pem}\n    - {id: a, algorithm: EdDSA, private_key_file: b.pem}\n")
+	_, _, err = Load([]string{"-config", duplicate}, env(nil))
+	assert.EqualError(t, err, `jwt.keys: id "a" is listed twice`)
+}
+
+func TestLoad_JWTIssuerAudienceAndLeeway(t *testing.T) {
+	cfg, _, err := Load(nil, env(map[string]string{"JWT_ISSUER": "https://auth.example.com", "JWT_AUDIENCE": "tasks", "JWT_LEEWAY": "10s"}))
+	require.NoError(t, err)
+	assert.Equal(t, "https://auth.example.com", cfg.JWT.Issuer)
+	assert.Equal(t, "tasks", cfg.JWT.Audience)
+	assert.Equal(t, Duration(10*time.Second), cfg.JWT.Leeway)
+
+	_, _, err = Load(nil, env(map[string]string{"JWT_LEEWAY": "1h"}))
+	assert.EqualError(t, err, "jwt.leeway must be between 0 and 5m")
+
+	emptyAudience := writeFile(t, "config.yaml", "jwt:\n  audience: \"\"\n")
+	_, _, err = Load([]string{"-config", emptyAudience}, env(nil))
+	assert.EqualError(t, err, "jwt.issuer and jwt.audience are required")
+}
+
+func TestLoad_Policy(t *testing.T) {
+	path := writeFile(t, "config.yaml", "policy:\n  file: /etc/task-manager/policies.yaml\n")
+
+	cfg, _, err := Load([]string{"-config", path}, env(map[string]string{"POLICY_RELOAD_INTERVAL": "1m"}))
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/task-manager/policies.yaml", cfg.Policy.File)
+	assert.Equal(t, Duration(time.Minute), cfg.Policy.ReloadInterval)
+
+	_, _, err = Load(nil, env(map[string]string{"POLICY_RELOAD_INTERVAL": "0s"}))
+	assert.EqualError(t, err, "policy.reload_interval must be positive")
+}
+
+func TestLoad_UserCacheTTL(t *testing.T) {
+	cfg, _, err := Load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, Duration(5*time.Second), cfg.JWT.UserCacheTTL)
+
+	cfg, _, err = Load(nil, env(map[string]string{"JWT_USER_CACHE_TTL": "0s"}))
+	require.NoError(t, err)
+	assert.Zero(t, cfg.JWT.UserCacheTTL, "zero turns the cache off")
+
+	_, _, err = Load(nil, env(map[string]string{"JWT_USER_CACHE_TTL": "1h"}))
+	assert.EqualError(t, err, "jwt.user_cache_ttl must be between 0 and jwt.expiry")
+}
+
+func TestLoad_ReturnsUsageForBadFlags(t *testing.T) {
+	_, _, err := Load([]string{"-h"}, env(nil))
+	var usageErr *UsageError
+	require.ErrorAs(t, err, &usageErr)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	assert.Contains(t, usageErr.Usage, "-http-addr")
+
+	_, _, err = Load([]string{"-no-such-flag"}, env(nil))
+	require.ErrorAs(t, err, &usageErr)
+	assert.EqualError(t, err, "flag provided but not defined: -no-such-flag")
+	assert.Contains(t, usageErr.Usage, "-mongo-uri")
+}
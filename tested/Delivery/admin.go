@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	domain "task-manager/Domain"
+
+	"golang.org/x/term"
+)
+
+const adminUsage = `usage: task-manager admin -username NAME [-email ADDRESS]
+
+Promotes NAME to an enabled Admin directly in the database, creating the user
+first when it does not exist. The password is only needed to create a user;
+it is read from $ADMIN_PASSWORD or a terminal prompt, never from a flag.
+`
+
+// runAdmin implements the "admin" subcommand and returns the exit code.
+func runAdmin(ctx context.Context, adminUseCase domain.AdminUseCase, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, adminUsage) }
+	username := fs.String("username", "", "")
+	email := fs.String("email", "", "")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *username == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" && term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(stderr, "Password (leave empty to promote an existing user): ")
+		entered, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(stderr)
+		if err != nil {
+			fmt.Fprintln(stderr, "admin:", err)
+			return 1
+		}
+		password = string(entered)
+	}
+
+	created, err := adminUseCase.CreateOrPromoteAdmin(ctx, *username, password, *email)
+	if err != nil {
+		fmt.Fprintln(stderr, "admin:", err)
+		return 1
+	}
+	if created {
+		fmt.Fprintf(stdout, "Created %s as Admin\n", *username)
+	} else {
+		fmt.Fprintf(stdout, "%s is an Admin\n", *username)
+	}
+	return 0
+}
+
+// bootstrapAdmin creates the first Admin from BOOTSTRAP_ADMIN_USERNAME,
+// BOOTSTRAP_ADMIN_PASSWORD and BOOTSTRAP_ADMIN_EMAIL. It does nothing once
+// an enabled Admin exists, so the variables can stay set across restarts.
+func bootstrapAdmin(ctx context.Context, adminUseCase domain.AdminUseCase) error {
+	username := os.Getenv("BOOTSTRAP_ADMIN_USERNAME")
+	if username == "" {
+		return nil
+	}
+
+	done, err := adminUseCase.BootstrapAdmin(ctx, username, os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"), os.Getenv("BOOTSTRAP_ADMIN_EMAIL"))
+	if err != nil {
+		return err
+	}
+	if done {
+		slog.InfoContext(ctx, "bootstrapped the first admin", "username", username)
+	}
+	return nil
+}
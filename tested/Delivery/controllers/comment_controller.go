@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	commentUseCase domain.CommentUseCase
+}
+
+func NewCommentController(commentUseCase domain.CommentUseCase) *CommentController {
+	return &CommentController{
+		commentUseCase: commentUseCase,
+	}
+}
+
+func (cc *CommentController) GetComments(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	comments, err := cc.commentUseCase.GetComments(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func (cc *CommentController) AddComment(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	comment, err := cc.commentUseCase.AddComment(c.Request.Context(), taskID, currentUsername(c), req.Body)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidCommentBody:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Comment body cannot be empty"})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
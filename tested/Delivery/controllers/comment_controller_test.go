@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCommentUseCase struct{ mock.Mock }
+
+func (m *MockCommentUseCase) AddComment(_ context.Context, taskID int, author, body string) (domain.Comment, error) {
+	args := m.Called(taskID, author, body)
+	return args.Get(0).(domain.Comment), args.Error(1)
+}
+
+func (m *MockCommentUseCase) GetComments(_ context.Context, taskID int) ([]domain.Comment, error) {
+	args := m.Called(taskID)
+	return args.Get(0).([]domain.Comment), args.Error(1)
+}
+
+func TestAddComment_UsesAuthenticatedAuthor(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockCommentUseCase)
+	ctrl := NewCommentController(mockUC)
+
+	mockUC.On("AddComment", 4, "alice", "hi").Return(domain.Comment{TaskID: 4, Author: "alice", Body: "hi"}, nil).Once()
+
+	r.POST("/tasks/:id/comments", func(c *gin.Context) {
+		infrastructure.SetCurrentUser(c, &infrastructure.Claims{Username: "alice"}, domain.Role{})
+	}, ctrl.AddComment)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks/4/comments", bytes.NewReader([]byte(`{"body":"hi"}`))))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestAddComment_EmptyBody(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockCommentUseCase)
+	ctrl := NewCommentController(mockUC)
+
+	mockUC.On("AddComment", 4, "", "").Return(domain.Comment{}, domain.ErrInvalidCommentBody).Once()
+
+	r.POST("/tasks/:id/comments", ctrl.AddComment)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks/4/comments", bytes.NewReader([]byte(`{"body":""}`))))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetComments_InvalidID(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockCommentUseCase)
+	ctrl := NewCommentController(mockUC)
+
+	r.GET("/tasks/:id/comments", ctrl.GetComments)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/abc/comments", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUC.AssertNotCalled(t, "GetComments", mock.Anything)
+}
+
+func TestGetComments_TaskNotFound(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockCommentUseCase)
+	ctrl := NewCommentController(mockUC)
+
+	mockUC.On("GetComments", 9).Return([]domain.Comment(nil), assert.AnError).Once()
+
+	r.GET("/tasks/:id/comments", ctrl.GetComments)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/9/comments", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUC.AssertExpectations(t)
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +21,7 @@ func NewTaskController(taskUseCase domain.TaskUseCase) *TaskController {
 }
 
 func (t *TaskController) GetTasks(c *gin.Context) {
-	tasks, err := t.taskUseCase.GetAllTasks()
+	tasks, err := t.taskUseCase.GetAllTasks(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
 		return
@@ -36,7 +38,7 @@ func (t *TaskController) GetTaskByID(c *gin.Context) {
 		return
 	}
 
-	task, err := t.taskUseCase.GetTaskByID(userID)
+	task, err := t.taskUseCase.GetTaskByID(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
@@ -52,13 +54,15 @@ func (t *TaskController) CreateTask(c *gin.Context) {
 		return
 	}
 
-	err := t.taskUseCase.CreateTask(newTask)
+	_, err := t.taskUseCase.CreateTask(c.Request.Context(), newTask)
 	if err != nil {
 		switch err {
 		case domain.ErrInvalidTaskTitle:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Task title cannot be empty"})
 		case domain.ErrInvalidTaskDescription:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Task description cannot be empty"})
+		case domain.ErrTaskForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed by task policy"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 		}
@@ -82,13 +86,15 @@ func (t *TaskController) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	task, err := t.taskUseCase.UpdateTask(userID, updatedTask)
+	task, err := t.taskUseCase.UpdateTask(c.Request.Context(), userID, updatedTask)
 	if err != nil {
 		switch err {
 		case domain.ErrInvalidTaskTitle:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Task title cannot be empty"})
 		case domain.ErrInvalidTaskDescription:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Task description cannot be empty"})
+		case domain.ErrTaskForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed by task policy"})
 		default:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		}
@@ -106,7 +112,11 @@ func (t *TaskController) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	err = t.taskUseCase.DeleteTask(userID)
+	err = t.taskUseCase.DeleteTask(c.Request.Context(), userID)
+	if err == domain.ErrTaskForbidden {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed by task policy"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
@@ -129,6 +139,7 @@ func (u *UserController) Register(c *gin.Context) {
 	var req struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
+		Email    string `json:"email"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -136,7 +147,7 @@ func (u *UserController) Register(c *gin.Context) {
 		return
 	}
 
-	err := u.userUseCase.RegisterUser(req.Username, req.Password)
+	err := u.userUseCase.RegisterUser(c.Request.Context(), req.Username, req.Password, req.Email)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -156,19 +167,80 @@ func (u *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := u.userUseCase.LoginUser(req.Username, req.Password)
+	tokens, err := u.userUseCase.LoginUser(c.Request.Context(), req.Username, req.Password, c.ClientIP())
+	if err == domain.ErrTooManyLoginAttempts {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err == domain.ErrUserDisabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, gin.H{"token": tokens.AccessToken, "refresh_token": tokens.RefreshToken})
+}
+
+func (u *UserController) RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	tokens, err := u.userUseCase.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err == domain.ErrInvalidRefreshToken {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": tokens.AccessToken, "refresh_token": tokens.RefreshToken})
+}
+
+func (u *UserController) Logout(c *gin.Context) {
+	if err := logout(c, u.userUseCase); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// logout ends the session of the token the auth middleware accepted.
+func logout(c *gin.Context, userUseCase domain.UserUseCase) error {
+	claims, ok := infrastructure.CurrentUser(c)
+	if !ok {
+		return errors.New("logout needs an authenticated caller")
+	}
+	return userUseCase.Logout(c.Request.Context(), claims.ID, claims.SessionID, claims.ExpiresAt.Time)
+}
+
+// currentUsername names the caller the auth middleware accepted.
+func currentUsername(c *gin.Context) string {
+	if claims, ok := infrastructure.CurrentUser(c); ok {
+		return claims.Username
+	}
+	return ""
 }
 
 func (u *UserController) PromoteUser(c *gin.Context) {
 	username := c.Param("username")
 
-	err := u.userUseCase.PromoteUser(username)
+	err := u.userUseCase.PromoteUser(c.Request.Context(), username)
+	if err == domain.ErrRoleEscalation {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -176,3 +248,97 @@ func (u *UserController) PromoteUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "User promoted to admin"})
 }
+
+func (u *UserController) UnlockUser(c *gin.Context) {
+	username := c.Param("username")
+
+	err := u.userUseCase.UnlockUser(c.Request.Context(), username)
+	if err == domain.ErrUserNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked"})
+}
+
+func (u *UserController) ListUsers(c *gin.Context) {
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative integer"})
+		return
+	}
+
+	page, err := u.userUseCase.ListUsers(c.Request.Context(), domain.UserQuery{Search: c.Query("q"), Offset: offset, Limit: limit})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (u *UserController) GetUser(c *gin.Context) {
+	user, err := u.userUseCase.GetUser(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		userError(c, err, "Failed to retrieve user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (u *UserController) DemoteUser(c *gin.Context) {
+	if err := u.userUseCase.DemoteUser(c.Request.Context(), c.Param("username")); err != nil {
+		userError(c, err, "Failed to demote user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User demoted to user"})
+}
+
+func (u *UserController) DisableUser(c *gin.Context) {
+	if err := u.userUseCase.DisableUser(c.Request.Context(), c.Param("username")); err != nil {
+		userError(c, err, "Failed to disable user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User disabled"})
+}
+
+func (u *UserController) EnableUser(c *gin.Context) {
+	if err := u.userUseCase.EnableUser(c.Request.Context(), c.Param("username")); err != nil {
+		userError(c, err, "Failed to enable user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User enabled"})
+}
+
+func (u *UserController) DeleteUser(c *gin.Context) {
+	if err := u.userUseCase.DeleteUser(c.Request.Context(), c.Param("username")); err != nil {
+		userError(c, err, "Failed to delete user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+func userError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case domain.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrLastAdmin:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -2,14 +2,18 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,42 +24,92 @@ type MockTaskUseCase struct{ mock.Mock }
 
 type MockUserUseCase struct{ mock.Mock }
 
-func (m *MockTaskUseCase) GetAllTasks() ([]domain.Task, error) {
+func (m *MockTaskUseCase) GetAllTasks(_ context.Context) ([]domain.Task, error) {
 	args := m.Called()
 	return args.Get(0).([]domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) GetTaskByID(userID int) (domain.Task, error) {
+func (m *MockTaskUseCase) GetTaskByID(_ context.Context, userID int) (domain.Task, error) {
 	args := m.Called(userID)
 	return args.Get(0).(domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) CreateTask(task domain.Task) error {
+func (m *MockTaskUseCase) CreateTask(_ context.Context, task domain.Task) (domain.Task, error) {
 	args := m.Called(task)
-	return args.Error(0)
+	return args.Get(0).(domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) UpdateTask(userID int, task domain.Task) (domain.Task, error) {
+func (m *MockTaskUseCase) UpdateTask(_ context.Context, userID int, task domain.Task) (domain.Task, error) {
 	args := m.Called(userID, task)
 	return args.Get(0).(domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) DeleteTask(userID int) error {
+func (m *MockTaskUseCase) DeleteTask(_ context.Context, userID int) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
-func (m *MockUserUseCase) RegisterUser(username, password string) error {
-	args := m.Called(username, password)
+func (m *MockUserUseCase) RegisterUser(_ context.Context, username, password, email string) error {
+	args := m.Called(username, password, email)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) LoginUser(_ context.Context, username, password, ip string) (domain.TokenPair, error) {
+	args := m.Called(username, password, ip)
+	return args.Get(0).(domain.TokenPair), args.Error(1)
+}
+
+func (m *MockUserUseCase) RefreshToken(_ context.Context, refreshToken string) (domain.TokenPair, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(domain.TokenPair), args.Error(1)
+}
+
+func (m *MockUserUseCase) Logout(_ context.Context, tokenID, sessionID string, expiresAt time.Time) error {
+	args := m.Called(tokenID, sessionID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) GetUser(_ context.Context, username string) (domain.User, error) {
+	args := m.Called(username)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) GetAllUsers(_ context.Context) ([]domain.User, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) PromoteUser(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) UnlockUser(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) ListUsers(_ context.Context, query domain.UserQuery) (domain.UserPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(domain.UserPage), args.Error(1)
+}
+
+func (m *MockUserUseCase) DemoteUser(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) DisableUser(_ context.Context, username string) error {
+	args := m.Called(username)
 	return args.Error(0)
 }
 
-func (m *MockUserUseCase) LoginUser(username, password string) (string, error) {
-	args := m.Called(username, password)
-	return args.String(0), args.Error(1)
+func (m *MockUserUseCase) EnableUser(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
 }
 
-func (m *MockUserUseCase) PromoteUser(username string) error {
+func (m *MockUserUseCase) DeleteUser(_ context.Context, username string) error {
 	args := m.Called(username)
 	return args.Error(0)
 }
@@ -121,7 +175,7 @@ func TestCreateTask_ValidationError(t *testing.T) {
 	ctrl := NewTaskController(mockUC)
 
 	body, _ := json.Marshal(map[string]any{"title": "", "description": "d"})
-	mockUC.On("CreateTask", mock.AnythingOfType("domain.Task")).Return(domain.ErrInvalidTaskTitle).Once()
+	mockUC.On("CreateTask", mock.AnythingOfType("domain.Task")).Return(domain.Task{}, domain.ErrInvalidTaskTitle).Once()
 
 	r.POST("/tasks", ctrl.CreateTask)
 	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
@@ -139,7 +193,7 @@ func TestCreateTask_Success(t *testing.T) {
 
 	payload := domain.Task{Title: "A", Description: "B"}
 	b, _ := json.Marshal(payload)
-	mockUC.On("CreateTask", mock.AnythingOfType("domain.Task")).Return(nil).Once()
+	mockUC.On("CreateTask", mock.AnythingOfType("domain.Task")).Return(domain.Task{UserID: 1}, nil).Once()
 
 	r.POST("/tasks", ctrl.CreateTask)
 	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b)))
@@ -202,6 +256,20 @@ func TestDeleteTask_Success(t *testing.T) {
 	mockUC.AssertExpectations(t)
 }
 
+func TestDeleteTask_DeniedByPolicy(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskController(mockUC)
+
+	mockUC.On("DeleteTask", 1).Return(domain.ErrTaskForbidden).Once()
+	r.DELETE("/tasks/:id", ctrl.DeleteTask)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/tasks/1", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
 func TestRegister_Success(t *testing.T) {
 	setupGin()
 	rec := httptest.NewRecorder()
@@ -209,9 +277,9 @@ func TestRegister_Success(t *testing.T) {
 	mockUC := new(MockUserUseCase)
 	ctrl := NewUserController(mockUC)
 
-	mockUC.On("RegisterUser", "bob", "secret").Return(nil).Once()
+	mockUC.On("RegisterUser", "bob", "secret", "bob@example.com").Return(nil).Once()
 	r.POST("/register", ctrl.Register)
-	body := []byte(`{"username":"bob","password":"secret"}`)
+	body := []byte(`{"username":"bob","password":"secret","email":"bob@example.com"}`)
 	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(body)))
 	assert.Equal(t, http.StatusOK, rec.Code)
 	mockUC.AssertExpectations(t)
@@ -224,7 +292,7 @@ func TestLogin_Success(t *testing.T) {
 	mockUC := new(MockUserUseCase)
 	ctrl := NewUserController(mockUC)
 
-	mockUC.On("LoginUser", "bob", "secret").Return("tok", nil).Once()
+	mockUC.On("LoginUser", "bob", "secret", "192.0.2.1").Return(domain.TokenPair{AccessToken: "tok", RefreshToken: "refresh"}, nil).Once()
 	r.POST("/login", ctrl.Login)
 	body := []byte(`{"username":"bob","password":"secret"}`)
 	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
@@ -239,7 +307,7 @@ func TestLogin_Invalid(t *testing.T) {
 	mockUC := new(MockUserUseCase)
 	ctrl := NewUserController(mockUC)
 
-	mockUC.On("LoginUser", "bob", "bad").Return("", assert.AnError).Once()
+	mockUC.On("LoginUser", "bob", "bad", "192.0.2.1").Return(domain.TokenPair{}, assert.AnError).Once()
 	r.POST("/login", ctrl.Login)
 	body := []byte(`{"username":"bob","password":"bad"}`)
 	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
@@ -247,6 +315,154 @@ func TestLogin_Invalid(t *testing.T) {
 	mockUC.AssertExpectations(t)
 }
 
+func TestRefreshToken(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	mockUC.On("RefreshToken", "old").Return(domain.TokenPair{AccessToken: "tok", RefreshToken: "new"}, nil).Once()
+	mockUC.On("RefreshToken", "reused").Return(domain.TokenPair{}, domain.ErrInvalidRefreshToken).Once()
+	r.POST("/token/refresh", ctrl.RefreshToken)
+
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewReader([]byte(`{"refresh_token":"old"}`))))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"token":"tok","refresh_token":"new"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewReader([]byte(`{"refresh_token":"reused"}`))))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestLogout_RevokesTheAuthenticatedToken(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUC.On("Logout", "jti-1", "sid-1", expiresAt).Return(nil).Once()
+	r.POST("/logout", func(c *gin.Context) {
+		infrastructure.SetCurrentUser(c, &infrastructure.Claims{
+			SessionID:        "sid-1",
+			RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(expiresAt)},
+		}, domain.Role{})
+	}, ctrl.Logout)
+
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/logout", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestLogin_Locked(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	mockUC.On("LoginUser", "bob", "secret", "192.0.2.1").Return(domain.TokenPair{}, domain.ErrTooManyLoginAttempts).Once()
+	r.POST("/login", ctrl.Login)
+	body := []byte(`{"username":"bob","password":"secret"}`)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestLogin_Disabled(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	mockUC.On("LoginUser", "bob", "secret", "192.0.2.1").Return(domain.TokenPair{}, domain.ErrUserDisabled).Once()
+	r.POST("/login", ctrl.Login)
+	body := []byte(`{"username":"bob","password":"secret"}`)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"error":"user is disabled"}`, rec.Body.String())
+}
+
+func TestListUsers(t *testing.T) {
+	setupGin()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	page := domain.UserPage{Users: []domain.User{{UserName: "bob", Role: "user"}}, Total: 3, Offset: 2, Limit: 1}
+	mockUC.On("ListUsers", domain.UserQuery{Search: "bo", Offset: 2, Limit: 1}).Return(page, nil).Once()
+	r.GET("/users", ctrl.ListUsers)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?q=bo&offset=2&limit=1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"users":[{"id":"000000000000000000000000","user_name":"bob","role":"user","disabled":false}],"total":3,"offset":2,"limit":1}`, rec.Body.String())
+
+	for _, query := range []string{"offset=-1", "limit=ten"} {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+	mockUC.AssertExpectations(t)
+}
+
+func TestUserManagement_Errors(t *testing.T) {
+	setupGin()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	mockUC.On("DemoteUser", "root").Return(domain.ErrLastAdmin).Once()
+	mockUC.On("DisableUser", "bob").Return(nil).Once()
+	mockUC.On("EnableUser", "ghost").Return(domain.ErrUserNotFound).Once()
+	mockUC.On("DeleteUser", "bob").Return(nil).Once()
+	mockUC.On("GetUser", "bob").Return(domain.User{UserName: "bob", Disabled: true}, nil).Once()
+	r.GET("/users/:username", ctrl.GetUser)
+	r.POST("/users/:username/demote", ctrl.DemoteUser)
+	r.POST("/users/:username/disable", ctrl.DisableUser)
+	r.POST("/users/:username/enable", ctrl.EnableUser)
+	r.DELETE("/users/:username", ctrl.DeleteUser)
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodPost, "/users/root/demote", http.StatusConflict},
+		{http.MethodPost, "/users/bob/disable", http.StatusOK},
+		{http.MethodPost, "/users/ghost/enable", http.StatusNotFound},
+		{http.MethodDelete, "/users/bob", http.StatusOK},
+		{http.MethodGet, "/users/bob", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.code, rec.Code, tc.method+" "+tc.path)
+	}
+	mockUC.AssertExpectations(t)
+}
+
+func TestUnlockUser(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserController(mockUC)
+
+	mockUC.On("UnlockUser", "bob").Return(nil).Once()
+	mockUC.On("UnlockUser", "ghost").Return(domain.ErrUserNotFound).Once()
+	r.POST("/unlock/:username", ctrl.UnlockUser)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/unlock/bob", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/unlock/ghost", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
 func TestPromoteUser_Success(t *testing.T) {
 	setupGin()
 	rec := httptest.NewRecorder()
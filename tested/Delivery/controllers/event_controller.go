@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+type EventController struct {
+	broker    *infrastructure.TaskEventBroker
+	auth      TokenRechecker
+	heartbeat time.Duration
+}
+
+// NewEventController checks the caller's token again on every heartbeat and
+// ends the stream once it no longer holds or expires, like the WebSocket.
+func NewEventController(broker *infrastructure.TaskEventBroker, auth TokenRechecker, heartbeat time.Duration) *EventController {
+	return &EventController{
+		broker:    broker,
+		auth:      auth,
+		heartbeat: heartbeat,
+	}
+}
+
+func (e *EventController) StreamTaskEvents(c *gin.Context) {
+	lastEventID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+
+	backlog, events, cancel := e.broker.Subscribe(lastEventID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, streamed := range backlog {
+		if canSeeTaskEvent(c, streamed.Event) {
+			renderTaskEvent(c, streamed)
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(e.heartbeat)
+	defer heartbeat.Stop()
+	claims, _ := infrastructure.CurrentUser(c)
+	var expired <-chan time.Time
+	if claims.ExpiresAt != nil {
+		expiry := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case streamed, ok := <-events:
+			if !ok {
+				return false
+			}
+			if canSeeTaskEvent(c, streamed.Event) {
+				renderTaskEvent(c, streamed)
+			}
+			return true
+		case <-expired:
+			return false
+		case <-heartbeat.C:
+			if _, err := e.auth.Recheck(c.Request.Context(), claims); err != nil {
+				return false
+			}
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// canSeeTaskEvent mirrors GET /tasks: any authenticated user may read every
+// task, but only task-scoped events are streamed.
+func canSeeTaskEvent(c *gin.Context, event domain.Event) bool {
+	return currentUsername(c) != "" && event.Task != nil
+}
+
+func renderTaskEvent(c *gin.Context, streamed infrastructure.StreamedEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(streamed.Seq, 10),
+		Event: streamed.Event.Type,
+		Data:  streamed.Event,
+	})
+}
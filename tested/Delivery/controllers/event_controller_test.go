@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startEventStream(t *testing.T, broker *infrastructure.TaskEventBroker, lastEventID string) *bufio.Reader {
+	return startEventStreamWith(t, broker, &stubRechecker{}, &infrastructure.Claims{Username: "bob"}, lastEventID)
+}
+
+func startEventStreamWith(t *testing.T, broker *infrastructure.TaskEventBroker, auth TokenRechecker, claims *infrastructure.Claims, lastEventID string) *bufio.Reader {
+	setupGin()
+	ctrl := NewEventController(broker, auth, 20*time.Millisecond)
+	r := gin.New()
+	r.GET("/tasks/events", func(c *gin.Context) {
+		infrastructure.SetCurrentUser(c, claims, domain.Role{})
+	}, ctrl.StreamTaskEvents)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/tasks/events", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewReader(resp.Body)
+}
+
+func readUntil(t *testing.T, reader *bufio.Reader, prefix string) string {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line)
+		}
+	}
+	t.Fatalf("no line starting with %q", prefix)
+	return ""
+}
+
+func TestStreamTaskEvents_ResumesAndStreams(t *testing.T) {
+	broker := infrastructure.NewTaskEventBroker(10)
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 1}))
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskUpdated, domain.Task{UserID: 1, Title: "renamed"}))
+
+	reader := startEventStream(t, broker, "1")
+
+	assert.Equal(t, "id:2", readUntil(t, reader, "id:"))
+	assert.Equal(t, "event:task.updated", readUntil(t, reader, "event:"))
+	assert.Contains(t, readUntil(t, reader, "data:"), `"title":"renamed"`)
+
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskDeleted, domain.Task{UserID: 1}))
+	assert.Equal(t, "id:3", readUntil(t, reader, "id:"))
+	assert.Equal(t, "event:task.deleted", readUntil(t, reader, "event:"))
+}
+
+func TestStreamTaskEvents_SendsHeartbeats(t *testing.T) {
+	reader := startEventStream(t, infrastructure.NewTaskEventBroker(10), "")
+	assert.Equal(t, ": heartbeat", readUntil(t, reader, ":"))
+}
+
+// drain reads the stream until the server ends it.
+func drain(t *testing.T, reader *bufio.Reader) {
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, reader)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the stream was not ended")
+	}
+}
+
+func TestStreamTaskEvents_EndsWhenTheTokenNoLongerHolds(t *testing.T) {
+	auth := &stubRechecker{}
+	reader := startEventStreamWith(t, infrastructure.NewTaskEventBroker(10), auth, &infrastructure.Claims{Username: "bob"}, "")
+	assert.Equal(t, ": heartbeat", readUntil(t, reader, ":"))
+
+	auth.set("", infrastructure.ErrTokenRevoked)
+	drain(t, reader)
+}
+
+func TestStreamTaskEvents_EndsWhenTheTokenExpires(t *testing.T) {
+	claims := &infrastructure.Claims{Username: "bob"}
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(100 * time.Millisecond))
+	reader := startEventStreamWith(t, infrastructure.NewTaskEventBroker(10), &stubRechecker{}, claims, "")
+	drain(t, reader)
+}
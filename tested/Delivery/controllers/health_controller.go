@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessCheck is one dependency that must be healthy before the server
+// should receive traffic.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthController struct {
+	checks  []ReadinessCheck
+	timeout time.Duration
+}
+
+func NewHealthController(timeout time.Duration, checks ...ReadinessCheck) *HealthController {
+	return &HealthController{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Liveness only shows that the process is serving requests.
+func (h *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness runs every check concurrently and answers 503 if any fails.
+func (h *HealthController) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	results := make(map[string]string, len(h.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check ReadinessCheck) {
+			defer wg.Done()
+			result := "ok"
+			if err := check.Check(ctx); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			results[check.Name] = result
+		}(check)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		if result != "ok" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": results})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": results})
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness(t *testing.T) {
+	setupGin()
+	ctrl := NewHealthController(time.Second, ReadinessCheck{Name: "mongo", Check: func(context.Context) error {
+		return errors.New("down")
+	}})
+	r := gin.New()
+	r.GET("/healthz", ctrl.Liveness)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestReadiness_AllChecksPass(t *testing.T) {
+	setupGin()
+	ok := func(context.Context) error { return nil }
+	ctrl := NewHealthController(time.Second, ReadinessCheck{Name: "mongo", Check: ok}, ReadinessCheck{Name: "migrations", Check: ok})
+	r := gin.New()
+	r.GET("/readyz", ctrl.Readiness)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ready","checks":{"mongo":"ok","migrations":"ok"}}`, rec.Body.String())
+}
+
+func TestReadiness_FailingOrSlowCheck(t *testing.T) {
+	setupGin()
+	ctrl := NewHealthController(20*time.Millisecond,
+		ReadinessCheck{Name: "mongo", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		ReadinessCheck{Name: "workers", Check: func(context.Context) error { return errors.New("worker grpc stopped: exited") }},
+		ReadinessCheck{Name: "migrations", Check: func(context.Context) error { return nil }},
+	)
+	r := gin.New()
+	r.GET("/readyz", ctrl.Readiness)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"unavailable","checks":{
+		"mongo":"context deadline exceeded",
+		"workers":"worker grpc stopped: exited",
+		"migrations":"ok"
+	}}`, rec.Body.String())
+}
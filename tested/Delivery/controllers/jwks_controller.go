@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSController publishes the public keys that verify access tokens, so
+// other services can check them without sharing a secret.
+type JWKSController struct {
+	keys *infrastructure.KeySet
+}
+
+func NewJWKSController(keys *infrastructure.KeySet) *JWKSController {
+	return &JWKSController{keys: keys}
+}
+
+// JWKS lists the current keys and those scheduled to take over. Verifiers
+// may cache it for a few minutes; keys are published well before they sign.
+func (j *JWKSController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, j.keys.PublicKeys())
+}
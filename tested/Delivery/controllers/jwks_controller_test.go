@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKS(t *testing.T) {
+	setupGin()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := infrastructure.NewKeySet(time.Hour, infrastructure.SigningKey{ID: "k1", Algorithm: infrastructure.AlgorithmEdDSA, Key: key})
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", NewJWKSController(keys).JWKS)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "public, max-age=300", rec.Header().Get("Cache-Control"))
+	var body infrastructure.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Keys, 1)
+	assert.Equal(t, "k1", body.Keys[0].ID)
+	assert.Equal(t, "EdDSA", body.Keys[0].Algorithm)
+	assert.Equal(t, "sig", body.Keys[0].Use)
+}
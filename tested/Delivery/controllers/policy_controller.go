@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyController lets admins try the task policies without touching any
+// task.
+type PolicyController struct {
+	engine      *infrastructure.PolicyEngine
+	taskUseCase domain.TaskUseCase
+}
+
+func NewPolicyController(engine *infrastructure.PolicyEngine, taskUseCase domain.TaskUseCase) *PolicyController {
+	return &PolicyController{
+		engine:      engine,
+		taskUseCase: taskUseCase,
+	}
+}
+
+// PolicySubject stands in for the claims of the caller being explained.
+type PolicySubject struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// PolicyExplainRequest names the task by TaskID, or describes it in Task. The
+// subject defaults to the caller; Update is the proposed task:update.
+type PolicyExplainRequest struct {
+	Subject *PolicySubject    `json:"subject"`
+	Action  domain.Permission `json:"action"`
+	TaskID  *int              `json:"task_id"`
+	Task    *domain.Task      `json:"task"`
+	Update  *domain.Task      `json:"update"`
+}
+
+// Explain reports what the policies would decide and why, as a dry run.
+func (p *PolicyController) Explain(c *gin.Context) {
+	var req PolicyExplainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+	if !slices.Contains([]domain.Permission{domain.PermissionTaskCreate, domain.PermissionTaskUpdate, domain.PermissionTaskDelete}, req.Action) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be task:create, task:update or task:delete"})
+		return
+	}
+
+	var resource domain.Task
+	switch {
+	case req.TaskID != nil:
+		task, err := p.taskUseCase.GetTaskByID(c.Request.Context(), *req.TaskID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		resource = task
+	case req.Task != nil:
+		resource = *req.Task
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "task_id or task is required"})
+		return
+	}
+
+	if req.Update != nil {
+		// as in UpdateTask, the creator cannot be changed
+		req.Update.CreatedBy = resource.CreatedBy
+	}
+
+	subject, _ := infrastructure.CurrentUser(c)
+	if req.Subject != nil {
+		subject = &infrastructure.Claims{Username: req.Subject.Username, Role: req.Subject.Role}
+	}
+
+	c.JSON(http.StatusOK, p.engine.Evaluate(infrastructure.PolicyRequest{
+		Subject:  subject,
+		Action:   req.Action,
+		Resource: resource,
+		Update:   req.Update,
+	}))
+}
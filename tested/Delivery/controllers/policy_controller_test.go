@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPolicyRouter(t *testing.T, tasks domain.TaskUseCase) *gin.Engine {
+	policies, err := infrastructure.ParsePolicies([]byte(`
+policies:
+  - name: only-creator-deletes
+    effect: deny
+    actions: [task:delete]
+    when: ["subject.username != resource.created_by"]
+  - name: assignee-keeps-due-date
+    effect: deny
+    actions: [task:update]
+    when: ["subject.username == resource.assignee"]
+    fields: [due_date]
+`))
+	require.NoError(t, err)
+
+	setupGin()
+	ctrl := NewPolicyController(infrastructure.NewPolicyEngine(policies), tasks)
+	r := gin.New()
+	r.POST("/policies/explain", func(c *gin.Context) {
+		infrastructure.SetCurrentUser(c, &infrastructure.Claims{Username: "alice", Role: domain.RoleAdmin}, domain.Role{})
+	}, ctrl.Explain)
+	return r
+}
+
+func TestPolicyController_ExplainStoredTask(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	tasks.On("GetTaskByID", 3).Return(domain.Task{UserID: 3, CreatedBy: "carol"}, nil).Twice()
+	tasks.On("GetTaskByID", 4).Return(domain.Task{}, domain.ErrTaskNotFound).Once()
+	r := newPolicyRouter(t, tasks)
+
+	// the caller is the subject unless one is given
+	rec := serveJSON(r, http.MethodPost, "/policies/explain", `{"action":"task:delete","task_id":3}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var decision infrastructure.PolicyDecision
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decision))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "only-creator-deletes", decision.Policy)
+	assert.Len(t, decision.Trace, 2)
+
+	rec = serveJSON(r, http.MethodPost, "/policies/explain", `{"subject":{"username":"carol","role":"user"},"action":"task:delete","task_id":3}`)
+	decision = infrastructure.PolicyDecision{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decision))
+	assert.True(t, decision.Allowed)
+	assert.Empty(t, decision.Policy)
+
+	rec = serveJSON(r, http.MethodPost, "/policies/explain", `{"action":"task:delete","task_id":4}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	tasks.AssertExpectations(t)
+	tasks.AssertNotCalled(t, "DeleteTask", 3)
+}
+
+func TestPolicyController_ExplainProposedUpdate(t *testing.T) {
+	r := newPolicyRouter(t, new(MockTaskUseCase))
+
+	rec := serveJSON(r, http.MethodPost, "/policies/explain", `{
+		"subject": {"username": "bob"},
+		"action": "task:update",
+		"task": {"title": "t", "status": "open", "assignee": "bob"},
+		"update": {"title": "t", "status": "open", "assignee": "bob", "due_date": "2030-01-01T00:00:00Z"}
+	}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"policy":"assignee-keeps-due-date"`)
+	assert.Contains(t, rec.Body.String(), `"allowed":false`)
+}
+
+func TestPolicyController_ExplainValidation(t *testing.T) {
+	r := newPolicyRouter(t, new(MockTaskUseCase))
+
+	rec := serveJSON(r, http.MethodPost, "/policies/explain", `{"action":"user:list","task_id":1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveJSON(r, http.MethodPost, "/policies/explain", `{"action":"task:delete"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
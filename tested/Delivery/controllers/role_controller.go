@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"net/http"
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleUseCase domain.RoleUseCase
+}
+
+func NewRoleController(roleUseCase domain.RoleUseCase) *RoleController {
+	return &RoleController{
+		roleUseCase: roleUseCase,
+	}
+}
+
+// GetPermissions lists the permission catalogue roles are built from.
+func (r *RoleController) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, domain.Permissions)
+}
+
+func (r *RoleController) GetRoles(c *gin.Context) {
+	roles, err := r.roleUseCase.GetAllRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (r *RoleController) CreateRole(c *gin.Context) {
+	var req struct {
+		Name        string              `json:"name"`
+		Permissions []domain.Permission `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	role, err := r.roleUseCase.CreateRole(c.Request.Context(), domain.Role{Name: req.Name, Permissions: req.Permissions})
+	if err != nil {
+		roleError(c, err, "Failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+func (r *RoleController) UpdateRole(c *gin.Context) {
+	var req struct {
+		Permissions []domain.Permission `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	role, err := r.roleUseCase.UpdateRole(c.Request.Context(), c.Param("name"), req.Permissions)
+	if err != nil {
+		roleError(c, err, "Failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (r *RoleController) DeleteRole(c *gin.Context) {
+	if err := r.roleUseCase.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		roleError(c, err, "Failed to delete role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+func (r *RoleController) AssignRole(c *gin.Context) {
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	err := r.roleUseCase.AssignRole(c.Request.Context(), c.Param("username"), req.Role)
+	if err == domain.ErrRoleNotFound {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		roleError(c, err, "Failed to assign role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned"})
+}
+
+func roleError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case domain.ErrInvalidRoleName, domain.ErrUnknownPermission:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case domain.ErrRoleEscalation:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case domain.ErrRoleNotFound, domain.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case domain.ErrRoleExists, domain.ErrRoleInUse, domain.ErrBuiltInRole, domain.ErrLastAdmin:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
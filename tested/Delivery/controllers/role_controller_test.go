@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleUseCase struct{ mock.Mock }
+
+func (m *MockRoleUseCase) GetRole(_ context.Context, name string) (domain.Role, error) {
+	args := m.Called(name)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleUseCase) GetAllRoles(_ context.Context) ([]domain.Role, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Role), args.Error(1)
+}
+
+func (m *MockRoleUseCase) CreateRole(_ context.Context, role domain.Role) (domain.Role, error) {
+	args := m.Called(role)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleUseCase) UpdateRole(_ context.Context, name string, permissions []domain.Permission) (domain.Role, error) {
+	args := m.Called(name, permissions)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleUseCase) DeleteRole(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockRoleUseCase) AssignRole(_ context.Context, username, role string) error {
+	args := m.Called(username, role)
+	return args.Error(0)
+}
+
+func newRoleRouter(uc domain.RoleUseCase) *gin.Engine {
+	setupGin()
+	ctrl := NewRoleController(uc)
+	r := gin.New()
+	r.GET("/permissions", ctrl.GetPermissions)
+	r.GET("/roles", ctrl.GetRoles)
+	r.POST("/roles", ctrl.CreateRole)
+	r.PUT("/roles/:name", ctrl.UpdateRole)
+	r.DELETE("/roles/:name", ctrl.DeleteRole)
+	r.PUT("/users/:username/role", ctrl.AssignRole)
+	return r
+}
+
+func serveJSON(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewReader([]byte(body))))
+	return rec
+}
+
+func TestRoleController_PermissionsAndList(t *testing.T) {
+	uc := new(MockRoleUseCase)
+	r := newRoleRouter(uc)
+	uc.On("GetAllRoles").Return([]domain.Role{{Name: "triager", Permissions: []domain.Permission{domain.PermissionTaskUpdate}}}, nil).Once()
+
+	rec := serveJSON(r, http.MethodGet, "/permissions", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"webhook:manage"`)
+
+	rec = serveJSON(r, http.MethodGet, "/roles", "")
+	assert.JSONEq(t, `[{"name":"triager","permissions":["task:update"],"built_in":false}]`, rec.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestRoleController_CreateUpdateDelete(t *testing.T) {
+	uc := new(MockRoleUseCase)
+	r := newRoleRouter(uc)
+	role := domain.Role{Name: "triager", Permissions: []domain.Permission{domain.PermissionTaskUpdate}}
+	uc.On("CreateRole", role).Return(role, nil).Once()
+	uc.On("CreateRole", domain.Role{Name: "bad", Permissions: []domain.Permission{"x"}}).Return(domain.Role{}, domain.ErrUnknownPermission).Once()
+	uc.On("UpdateRole", domain.RoleAdmin, []domain.Permission{}).Return(domain.Role{}, domain.ErrBuiltInRole).Once()
+	uc.On("DeleteRole", "triager").Return(domain.ErrRoleInUse).Once()
+	uc.On("DeleteRole", "ghost").Return(domain.ErrRoleNotFound).Once()
+
+	assert.Equal(t, http.StatusCreated, serveJSON(r, http.MethodPost, "/roles", `{"name":"triager","permissions":["task:update"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveJSON(r, http.MethodPost, "/roles", `{"name":"bad","permissions":["x"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveJSON(r, http.MethodPost, "/roles", `not json`).Code)
+	assert.Equal(t, http.StatusConflict, serveJSON(r, http.MethodPut, "/roles/Admin", `{"permissions":[]}`).Code)
+	assert.Equal(t, http.StatusConflict, serveJSON(r, http.MethodDelete, "/roles/triager", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodDelete, "/roles/ghost", "").Code)
+	uc.AssertExpectations(t)
+}
+
+func TestRoleController_AssignRole(t *testing.T) {
+	uc := new(MockRoleUseCase)
+	r := newRoleRouter(uc)
+	uc.On("AssignRole", "bob", "triager").Return(nil).Once()
+	uc.On("AssignRole", "bob", "ghost").Return(domain.ErrRoleNotFound).Once()
+	uc.On("AssignRole", "nobody", "triager").Return(domain.ErrUserNotFound).Once()
+	uc.On("AssignRole", "bob", "Admin").Return(domain.ErrRoleEscalation).Once()
+
+	assert.Equal(t, http.StatusOK, serveJSON(r, http.MethodPut, "/users/bob/role", `{"role":"triager"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveJSON(r, http.MethodPut, "/users/bob/role", `{"role":"ghost"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveJSON(r, http.MethodPut, "/users/nobody/role", `{"role":"triager"}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveJSON(r, http.MethodPut, "/users/bob/role", `{"role":"Admin"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveJSON(r, http.MethodPut, "/users/bob/role", `{}`).Code)
+	uc.AssertExpectations(t)
+}
@@ -0,0 +1,381 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The v2 API wraps every payload as {"data": ...}, reports failures as
+// {"error": {"code": ..., "message": ...}} and exposes tasks by string id.
+// It shares the v1 use cases; only the wire format differs.
+
+const (
+	V2ErrorInvalidRequest   = "invalid_request"
+	V2ErrorValidationFailed = "validation_failed"
+	V2ErrorNotFound         = "not_found"
+	V2ErrorUnauthorized     = "unauthorized"
+	V2ErrorForbidden        = "forbidden"
+	V2ErrorConflict         = "conflict"
+	V2ErrorTooManyAttempts  = "too_many_attempts"
+	V2ErrorInternal         = "internal"
+)
+
+type TaskV2 struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      string     `json:"status"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Project     string     `json:"project,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+}
+
+type TaskInputV2 struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+	Status      string     `json:"status"`
+	Assignee    string     `json:"assignee"`
+	Project     string     `json:"project"`
+}
+
+type CommentV2 struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserV2 struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Email    string `json:"email,omitempty"`
+}
+
+func NewTaskV2(task domain.Task) TaskV2 {
+	out := TaskV2{
+		ID:          strconv.Itoa(task.UserID),
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Assignee:    task.Assignee,
+		Project:     task.Project,
+		CreatedBy:   task.CreatedBy,
+	}
+	if !task.DueDate.IsZero() {
+		dueDate := task.DueDate
+		out.DueDate = &dueDate
+	}
+	return out
+}
+
+func (in TaskInputV2) toDomain() domain.Task {
+	task := domain.Task{
+		Title:       in.Title,
+		Description: in.Description,
+		Status:      in.Status,
+		Assignee:    in.Assignee,
+		Project:     in.Project,
+	}
+	if in.DueDate != nil {
+		task.DueDate = *in.DueDate
+	}
+	return task
+}
+
+func newCommentV2(comment domain.Comment) CommentV2 {
+	return CommentV2{
+		ID:        comment.ID.Hex(),
+		TaskID:    strconv.Itoa(comment.TaskID),
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+func respondV2(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, gin.H{"data": data})
+}
+
+func failV2(c *gin.Context, status int, code, message string) {
+	c.JSON(status, gin.H{"error": gin.H{"code": code, "message": message}})
+}
+
+// taskIDV2 parses a v2 task id. Ids are opaque strings to clients, so one
+// that cannot name a task is reported as not found rather than malformed.
+func taskIDV2(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		failV2(c, http.StatusNotFound, V2ErrorNotFound, "task not found")
+		return 0, false
+	}
+	return id, true
+}
+
+func failTaskV2(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrInvalidTaskTitle, domain.ErrInvalidTaskDescription:
+		failV2(c, http.StatusUnprocessableEntity, V2ErrorValidationFailed, err.Error())
+	case domain.ErrTaskNotFound:
+		failV2(c, http.StatusNotFound, V2ErrorNotFound, "task not found")
+	case domain.ErrTaskForbidden:
+		failV2(c, http.StatusForbidden, V2ErrorForbidden, err.Error())
+	default:
+		failV2(c, http.StatusInternalServerError, V2ErrorInternal, "failed to process task")
+	}
+}
+
+type TaskControllerV2 struct {
+	taskUseCase domain.TaskUseCase
+}
+
+func NewTaskControllerV2(taskUseCase domain.TaskUseCase) *TaskControllerV2 {
+	return &TaskControllerV2{
+		taskUseCase: taskUseCase,
+	}
+}
+
+func (t *TaskControllerV2) GetTasks(c *gin.Context) {
+	tasks, err := t.taskUseCase.GetAllTasks(c.Request.Context())
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, V2ErrorInternal, "failed to retrieve tasks")
+		return
+	}
+
+	out := make([]TaskV2, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, NewTaskV2(task))
+	}
+	respondV2(c, http.StatusOK, out)
+}
+
+func (t *TaskControllerV2) GetTask(c *gin.Context) {
+	id, ok := taskIDV2(c)
+	if !ok {
+		return
+	}
+
+	task, err := t.taskUseCase.GetTaskByID(c.Request.Context(), id)
+	if err != nil {
+		failV2(c, http.StatusNotFound, V2ErrorNotFound, "task not found")
+		return
+	}
+
+	respondV2(c, http.StatusOK, NewTaskV2(task))
+}
+
+func (t *TaskControllerV2) CreateTask(c *gin.Context) {
+	var in TaskInputV2
+	if err := c.ShouldBindJSON(&in); err != nil {
+		failV2(c, http.StatusBadRequest, V2ErrorInvalidRequest, "invalid JSON body")
+		return
+	}
+
+	task, err := t.taskUseCase.CreateTask(c.Request.Context(), in.toDomain())
+	if err != nil {
+		failTaskV2(c, err)
+		return
+	}
+
+	c.Header("Location", "/v2/tasks/"+strconv.Itoa(task.UserID))
+	respondV2(c, http.StatusCreated, NewTaskV2(task))
+}
+
+func (t *TaskControllerV2) UpdateTask(c *gin.Context) {
+	id, ok := taskIDV2(c)
+	if !ok {
+		return
+	}
+
+	var in TaskInputV2
+	if err := c.ShouldBindJSON(&in); err != nil {
+		failV2(c, http.StatusBadRequest, V2ErrorInvalidRequest, "invalid JSON body")
+		return
+	}
+
+	task, err := t.taskUseCase.UpdateTask(c.Request.Context(), id, in.toDomain())
+	if err != nil {
+		failTaskV2(c, err)
+		return
+	}
+
+	task.UserID = id
+	respondV2(c, http.StatusOK, NewTaskV2(task))
+}
+
+func (t *TaskControllerV2) DeleteTask(c *gin.Context) {
+	id, ok := taskIDV2(c)
+	if !ok {
+		return
+	}
+
+	if err := t.taskUseCase.DeleteTask(c.Request.Context(), id); err != nil {
+		failTaskV2(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type CommentControllerV2 struct {
+	commentUseCase domain.CommentUseCase
+}
+
+func NewCommentControllerV2(commentUseCase domain.CommentUseCase) *CommentControllerV2 {
+	return &CommentControllerV2{
+		commentUseCase: commentUseCase,
+	}
+}
+
+func (cc *CommentControllerV2) GetComments(c *gin.Context) {
+	id, ok := taskIDV2(c)
+	if !ok {
+		return
+	}
+
+	comments, err := cc.commentUseCase.GetComments(c.Request.Context(), id)
+	if err != nil {
+		failV2(c, http.StatusNotFound, V2ErrorNotFound, "task not found")
+		return
+	}
+
+	out := make([]CommentV2, 0, len(comments))
+	for _, comment := range comments {
+		out = append(out, newCommentV2(comment))
+	}
+	respondV2(c, http.StatusOK, out)
+}
+
+func (cc *CommentControllerV2) AddComment(c *gin.Context) {
+	id, ok := taskIDV2(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failV2(c, http.StatusBadRequest, V2ErrorInvalidRequest, "invalid JSON body")
+		return
+	}
+
+	comment, err := cc.commentUseCase.AddComment(c.Request.Context(), id, currentUsername(c), req.Body)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidCommentBody:
+			failV2(c, http.StatusUnprocessableEntity, V2ErrorValidationFailed, err.Error())
+		default:
+			failV2(c, http.StatusNotFound, V2ErrorNotFound, "task not found")
+		}
+		return
+	}
+
+	respondV2(c, http.StatusCreated, newCommentV2(comment))
+}
+
+type UserControllerV2 struct {
+	userUseCase domain.UserUseCase
+}
+
+func NewUserControllerV2(userUseCase domain.UserUseCase) *UserControllerV2 {
+	return &UserControllerV2{
+		userUseCase: userUseCase,
+	}
+}
+
+func (u *UserControllerV2) Register(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Email    string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failV2(c, http.StatusBadRequest, V2ErrorInvalidRequest, "invalid JSON body")
+		return
+	}
+
+	if err := u.userUseCase.RegisterUser(c.Request.Context(), req.Username, req.Password, req.Email); err != nil {
+		switch err {
+		case domain.ErrUsernameTaken:
+			failV2(c, http.StatusConflict, V2ErrorConflict, err.Error())
+		case domain.ErrInvalidEmail, domain.ErrPasswordTooShort:
+			failV2(c, http.StatusUnprocessableEntity, V2ErrorValidationFailed, err.Error())
+		default:
+			failV2(c, http.StatusInternalServerError, V2ErrorInternal, "failed to register user")
+		}
+		return
+	}
+
+	user, err := u.userUseCase.GetUser(c.Request.Context(), req.Username)
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, V2ErrorInternal, "failed to load registered user")
+		return
+	}
+
+	respondV2(c, http.StatusCreated, UserV2{Username: user.UserName, Role: user.Role, Email: user.Email})
+}
+
+func (u *UserControllerV2) Login(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failV2(c, http.StatusBadRequest, V2ErrorInvalidRequest, "invalid JSON body")
+		return
+	}
+
+	tokens, err := u.userUseCase.LoginUser(c.Request.Context(), req.Username, req.Password, c.ClientIP())
+	if err == domain.ErrTooManyLoginAttempts {
+		failV2(c, http.StatusTooManyRequests, V2ErrorTooManyAttempts, err.Error())
+		return
+	}
+	if err == domain.ErrUserDisabled {
+		failV2(c, http.StatusForbidden, V2ErrorForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		failV2(c, http.StatusUnauthorized, V2ErrorUnauthorized, "invalid credentials")
+		return
+	}
+
+	respondV2(c, http.StatusOK, gin.H{"token": tokens.AccessToken, "refresh_token": tokens.RefreshToken})
+}
+
+func (u *UserControllerV2) RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		failV2(c, http.StatusBadRequest, V2ErrorInvalidRequest, "refresh_token is required")
+		return
+	}
+
+	tokens, err := u.userUseCase.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err == domain.ErrInvalidRefreshToken {
+		failV2(c, http.StatusUnauthorized, V2ErrorUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, V2ErrorInternal, "failed to refresh token")
+		return
+	}
+
+	respondV2(c, http.StatusOK, gin.H{"token": tokens.AccessToken, "refresh_token": tokens.RefreshToken})
+}
+
+func (u *UserControllerV2) Logout(c *gin.Context) {
+	if err := logout(c, u.userUseCase); err != nil {
+		failV2(c, http.StatusInternalServerError, V2ErrorInternal, "failed to log out")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
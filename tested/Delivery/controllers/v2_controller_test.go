@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestV2GetTasks_EnvelopeWithStringIDs(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+
+	due := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockUC.On("GetAllTasks").Return([]domain.Task{{UserID: 1, Title: "A", DueDate: due}, {UserID: 2, Title: "B"}}, nil).Once()
+
+	r.GET("/v2/tasks", ctrl.GetTasks)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/tasks", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[
+		{"id":"1","title":"A","description":"","due_date":"2030-01-02T03:04:05Z","status":""},
+		{"id":"2","title":"B","description":"","status":""}
+	]}`, rec.Body.String())
+}
+
+func TestV2GetTasks_EmptyListIsNotNull(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+
+	mockUC.On("GetAllTasks").Return([]domain.Task(nil), nil).Once()
+
+	r.GET("/v2/tasks", ctrl.GetTasks)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/tasks", nil))
+
+	assert.JSONEq(t, `{"data":[]}`, rec.Body.String())
+}
+
+func TestV2CreateTask_ReturnsCreatedTask(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+
+	mockUC.On("CreateTask", domain.Task{Title: "Ship", Description: "it"}).
+		Return(domain.Task{UserID: 12, Title: "Ship", Description: "it", Status: "open"}, nil).Once()
+
+	r.POST("/v2/tasks", ctrl.CreateTask)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/tasks", bytes.NewReader([]byte(`{"title":"Ship","description":"it"}`))))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/v2/tasks/12", rec.Header().Get("Location"))
+	assert.JSONEq(t, `{"data":{"id":"12","title":"Ship","description":"it","status":"open"}}`, rec.Body.String())
+	mockUC.AssertExpectations(t)
+}
+
+func TestV2CreateTask_ValidationError(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+
+	mockUC.On("CreateTask", mock.Anything).Return(domain.Task{}, domain.ErrInvalidTaskTitle).Once()
+
+	r.POST("/v2/tasks", ctrl.CreateTask)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/tasks", bytes.NewReader([]byte(`{"description":"it"}`))))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"validation_failed","message":"task title cannot be empty"}}`, rec.Body.String())
+}
+
+func TestV2GetTask_UnknownIDIsNotFound(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+
+	r.GET("/v2/tasks/:id", ctrl.GetTask)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/tasks/abc", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"not_found","message":"task not found"}}`, rec.Body.String())
+	mockUC.AssertNotCalled(t, "GetTaskByID", mock.Anything)
+}
+
+func TestV2UpdateTask_ReturnsTaskWithRequestedID(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+
+	input := domain.Task{Title: "t", Description: "d", Status: "done"}
+	mockUC.On("UpdateTask", 3, input).Return(input, nil).Once()
+
+	r.PUT("/v2/tasks/:id", ctrl.UpdateTask)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/v2/tasks/3", bytes.NewReader([]byte(`{"title":"t","description":"d","status":"done"}`))))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"id":"3","title":"t","description":"d","status":"done"}}`, rec.Body.String())
+}
+
+func TestV2DeleteTask(t *testing.T) {
+	setupGin()
+	mockUC := new(MockTaskUseCase)
+	ctrl := NewTaskControllerV2(mockUC)
+	mockUC.On("DeleteTask", 3).Return(nil).Once()
+	mockUC.On("DeleteTask", 4).Return(domain.ErrTaskNotFound).Once()
+
+	r := gin.New()
+	r.DELETE("/v2/tasks/:id", ctrl.DeleteTask)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v2/tasks/3", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v2/tasks/4", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestV2AddComment_StringTaskID(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockCommentUseCase)
+	ctrl := NewCommentControllerV2(mockUC)
+
+	created := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUC.On("AddComment", 4, "alice", "hi").Return(domain.Comment{TaskID: 4, Author: "alice", Body: "hi", CreatedAt: created}, nil).Once()
+
+	r.POST("/v2/tasks/:id/comments", func(c *gin.Context) {
+		infrastructure.SetCurrentUser(c, &infrastructure.Claims{Username: "alice"}, domain.Role{})
+	}, ctrl.AddComment)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/tasks/4/comments", bytes.NewReader([]byte(`{"body":"hi"}`))))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"data":{"id":"000000000000000000000000","task_id":"4","author":"alice","body":"hi","created_at":"2030-01-01T00:00:00Z"}}`, rec.Body.String())
+}
+
+func TestV2Register_Conflict(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserControllerV2(mockUC)
+
+	mockUC.On("RegisterUser", "bob", "secret123", "").Return(domain.ErrUsernameTaken).Once()
+
+	r.POST("/v2/register", ctrl.Register)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/register", bytes.NewReader([]byte(`{"username":"bob","password":"secret123"}`))))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"conflict","message":"username is taken"}}`, rec.Body.String())
+}
+
+func TestV2Register_ReturnsUser(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserControllerV2(mockUC)
+
+	mockUC.On("RegisterUser", "bob", "secret123", "bob@example.com").Return(nil).Once()
+	mockUC.On("GetUser", "bob").Return(domain.User{UserName: "bob", Role: "user", Email: "bob@example.com"}, nil).Once()
+
+	r.POST("/v2/register", ctrl.Register)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/register",
+		bytes.NewReader([]byte(`{"username":"bob","password":"secret123","email":"bob@example.com"}`))))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"data":{"username":"bob","role":"user","email":"bob@example.com"}}`, rec.Body.String())
+}
+
+func TestV2Login(t *testing.T) {
+	setupGin()
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserControllerV2(mockUC)
+	mockUC.On("LoginUser", "bob", "secret123", "192.0.2.1").Return(domain.TokenPair{AccessToken: "jwt", RefreshToken: "refresh"}, nil).Once()
+	mockUC.On("LoginUser", "bob", "wrong", "192.0.2.1").Return(domain.TokenPair{}, domain.ErrInvalidCredentials).Once()
+	mockUC.On("LoginUser", "eve", "guess", "192.0.2.1").Return(domain.TokenPair{}, domain.ErrTooManyLoginAttempts).Once()
+
+	r := gin.New()
+	r.POST("/v2/login", ctrl.Login)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/login", bytes.NewReader([]byte(`{"username":"bob","password":"secret123"}`))))
+	assert.JSONEq(t, `{"data":{"token":"jwt","refresh_token":"refresh"}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/login", bytes.NewReader([]byte(`{"username":"bob","password":"wrong"}`))))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"unauthorized","message":"invalid credentials"}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/login", bytes.NewReader([]byte(`{"username":"eve","password":"guess"}`))))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"too_many_attempts","message":"too many failed login attempts, try again later"}}`, rec.Body.String())
+}
+
+func TestV2RefreshTokenAndLogout(t *testing.T) {
+	setupGin()
+	mockUC := new(MockUserUseCase)
+	ctrl := NewUserControllerV2(mockUC)
+	mockUC.On("RefreshToken", "old").Return(domain.TokenPair{AccessToken: "jwt", RefreshToken: "new"}, nil).Once()
+	mockUC.On("RefreshToken", "reused").Return(domain.TokenPair{}, domain.ErrInvalidRefreshToken).Once()
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUC.On("Logout", "jti-1", "sid-1", expiresAt).Return(nil).Once()
+
+	r := gin.New()
+	r.POST("/v2/token/refresh", ctrl.RefreshToken)
+	r.POST("/v2/logout", func(c *gin.Context) {
+		infrastructure.SetCurrentUser(c, &infrastructure.Claims{
+			SessionID:        "sid-1",
+			RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(expiresAt)},
+		}, domain.Role{})
+	}, ctrl.Logout)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/token/refresh", bytes.NewReader([]byte(`{"refresh_token":"old"}`))))
+	assert.JSONEq(t, `{"data":{"token":"jwt","refresh_token":"new"}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/token/refresh", bytes.NewReader([]byte(`{"refresh_token":"reused"}`))))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"unauthorized","message":"invalid refresh token"}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/token/refresh", bytes.NewReader([]byte(`{}`))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/logout", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookUseCase domain.WebhookUseCase
+}
+
+func NewWebhookController(webhookUseCase domain.WebhookUseCase) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+func (w *WebhookController) CreateWebhook(c *gin.Context) {
+	var req struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	webhook, err := w.webhookUseCase.CreateWebhook(c.Request.Context(), domain.Webhook{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidWebhookURL, domain.ErrInvalidWebhookSecret, domain.ErrInvalidWebhookEvent:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		}
+		return
+	}
+
+	webhook.Secret = ""
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (w *WebhookController) GetWebhooks(c *gin.Context) {
+	webhooks, err := w.webhookUseCase.GetAllWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (w *WebhookController) DeleteWebhook(c *gin.Context) {
+	err := w.webhookUseCase.DeleteWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch err {
+		case domain.ErrWebhookNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func (w *WebhookController) GetDeadLetters(c *gin.Context) {
+	deliveries, err := w.webhookUseCase.GetDeadLetters(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (w *WebhookController) RedeliverDeadLetter(c *gin.Context) {
+	err := w.webhookUseCase.RedeliverDeadLetter(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch err {
+		case domain.ErrDeadLetterNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+		case domain.ErrWebhookNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		default:
+			// The error can carry the receiver's address or response, which
+			// stays in the logs.
+			infrastructure.LoggerFrom(c.Request.Context()).Warn("webhook redelivery failed", "dead_letter_id", c.Param("id"), "error", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Redelivery failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook redelivered successfully"})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookUseCase struct{ mock.Mock }
+
+func (m *MockWebhookUseCase) CreateWebhook(_ context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) GetAllWebhooks(_ context.Context) ([]domain.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) DeleteWebhook(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUseCase) GetDeadLetters(_ context.Context) ([]domain.WebhookDelivery, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) RedeliverDeadLetter(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestCreateWebhook_Success(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockWebhookUseCase)
+	ctrl := NewWebhookController(mockUC)
+
+	created := domain.Webhook{URL: "https://example.com/hook", Secret: "s", Events: []string{domain.EventTaskCreated}}
+	mockUC.On("CreateWebhook", created).Return(created, nil).Once()
+
+	r.POST("/webhooks", ctrl.CreateWebhook)
+	body := []byte(`{"url":"https://example.com/hook","secret":"s","events":["task.created"]}`)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var got domain.Webhook
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Empty(t, got.Secret)
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreateWebhook_ValidationError(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockWebhookUseCase)
+	ctrl := NewWebhookController(mockUC)
+
+	mockUC.On("CreateWebhook", mock.AnythingOfType("domain.Webhook")).Return(domain.Webhook{}, domain.ErrInvalidWebhookURL).Once()
+
+	r.POST("/webhooks", ctrl.CreateWebhook)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader([]byte(`{"url":"x","secret":"s"}`))))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetWebhooks_HidesSecrets(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockWebhookUseCase)
+	ctrl := NewWebhookController(mockUC)
+
+	mockUC.On("GetAllWebhooks").Return([]domain.Webhook{{URL: "https://example.com", Secret: "s"}}, nil).Once()
+
+	r.GET("/webhooks", ctrl.GetWebhooks)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+	mockUC.AssertExpectations(t)
+}
+
+func TestDeleteWebhook_NotFound(t *testing.T) {
+	setupGin()
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	mockUC := new(MockWebhookUseCase)
+	ctrl := NewWebhookController(mockUC)
+
+	mockUC.On("DeleteWebhook", "abc").Return(domain.ErrWebhookNotFound).Once()
+
+	r.DELETE("/webhooks/:id", ctrl.DeleteWebhook)
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/webhooks/abc", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestRedeliverDeadLetter(t *testing.T) {
+	setupGin()
+	mockUC := new(MockWebhookUseCase)
+	ctrl := NewWebhookController(mockUC)
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.POST("/webhooks/dead-letters/:id/redeliver", ctrl.RedeliverDeadLetter)
+
+	mockUC.On("RedeliverDeadLetter", "ok").Return(nil).Once()
+	mockUC.On("RedeliverDeadLetter", "down").Return(assert.AnError).Once()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/dead-letters/ok/redeliver", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/dead-letters/down/redeliver", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.JSONEq(t, `{"error":"Redelivery failed"}`, rec.Body.String())
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,347 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsTopicAllTasks = "tasks"
+	wsTopicTask     = "task:"
+	wsTopicProject  = "project:"
+
+	WSErrorBadRequest       = "bad_request"
+	WSErrorUnknownType      = "unknown_type"
+	WSErrorInvalidTopic     = "invalid_topic"
+	WSErrorForbidden        = "forbidden"
+	WSErrorValidationFailed = "validation_failed"
+	WSErrorNotFound         = "not_found"
+	WSErrorInternal         = "internal"
+
+	// wsMaxMessageSize caps a client message; requests are a few short
+	// fields, so anything larger closes the connection with 1009.
+	wsMaxMessageSize = 4096
+)
+
+// WSRequest is a client message. ID is echoed back in the acknowledgement.
+type WSRequest struct {
+	ID     string       `json:"id"`
+	Type   string       `json:"type"`
+	Topic  string       `json:"topic,omitempty"`
+	TaskID int          `json:"task_id,omitempty"`
+	Task   *domain.Task `json:"task,omitempty"`
+}
+
+type WSError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WSResponse is either an "ack" for a WSRequest or an "event" for a
+// subscribed topic.
+type WSResponse struct {
+	Type  string        `json:"type"`
+	ID    string        `json:"id,omitempty"`
+	OK    bool          `json:"ok,omitempty"`
+	Error *WSError      `json:"error,omitempty"`
+	Task  *domain.Task  `json:"task,omitempty"`
+	Topic string        `json:"topic,omitempty"`
+	Event *domain.Event `json:"event,omitempty"`
+}
+
+// TokenRechecker re-validates the claims a WebSocket was opened with.
+// infrastructure.AuthMiddleware implements it.
+type TokenRechecker interface {
+	Recheck(ctx context.Context, claims *infrastructure.Claims) (domain.Role, error)
+}
+
+type WebSocketController struct {
+	taskUseCase domain.TaskUseCase
+	broker      *infrastructure.TaskEventBroker
+	auth        TokenRechecker
+	upgrader    websocket.Upgrader
+	pingPeriod  time.Duration
+}
+
+// NewWebSocketController checks the caller's token again before every
+// message and on every ping, and closes the connection once it is revoked,
+// its user disabled, deleted or changed, or it expires.
+func NewWebSocketController(
+	taskUseCase domain.TaskUseCase,
+	broker *infrastructure.TaskEventBroker,
+	auth TokenRechecker,
+	pingPeriod time.Duration,
+) *WebSocketController {
+	return &WebSocketController{
+		taskUseCase: taskUseCase,
+		broker:      broker,
+		auth:        auth,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+		pingPeriod: pingPeriod,
+	}
+}
+
+type wsSession struct {
+	conn       *websocket.Conn
+	claims     *infrastructure.Claims
+	role       domain.Role
+	send       chan WSResponse
+	writerDone chan struct{}
+
+	mu     sync.Mutex
+	topics map[string]bool
+}
+
+func (w *WebSocketController) Connect(c *gin.Context) {
+	conn, err := w.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// A client that stops answering pings is dropped once pongWait passes
+	// without a pong, instead of holding the connection open forever.
+	pongWait := 2 * w.pingPeriod
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	claims, _ := infrastructure.CurrentUser(c)
+	session := &wsSession{
+		conn:       conn,
+		claims:     claims,
+		role:       infrastructure.CurrentRole(c),
+		send:       make(chan WSResponse, 64),
+		writerDone: make(chan struct{}),
+		topics:     map[string]bool{},
+	}
+
+	_, events, cancel := w.broker.Subscribe(0)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go w.writeLoop(c.Request.Context(), session, events, done)
+
+	for {
+		var req WSRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+				return
+			}
+			if !session.reply(WSResponse{Type: "ack"}.fail(WSErrorBadRequest, "malformed message")) {
+				return
+			}
+			continue
+		}
+		role, err := w.auth.Recheck(c.Request.Context(), claims)
+		if err != nil {
+			session.close(err)
+			return
+		}
+		session.role = role
+		if !session.reply(w.handle(c.Request.Context(), session, req)) {
+			return
+		}
+	}
+}
+
+func (w *WebSocketController) writeLoop(ctx context.Context, session *wsSession, events <-chan infrastructure.StreamedEvent, done <-chan struct{}) {
+	defer close(session.writerDone)
+	ping := time.NewTicker(w.pingPeriod)
+	defer ping.Stop()
+	var expired <-chan time.Time
+	if session.claims.ExpiresAt != nil {
+		expiry := time.NewTimer(time.Until(session.claims.ExpiresAt.Time))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		select {
+		case response := <-session.send:
+			if err := session.conn.WriteJSON(response); err != nil {
+				session.conn.Close()
+				return
+			}
+		case streamed, ok := <-events:
+			if !ok {
+				// The broker dropped us for falling behind.
+				session.conn.Close()
+				return
+			}
+			if topic, matched := session.matchingTopic(streamed.Event); matched {
+				event := streamed.Event
+				if err := session.conn.WriteJSON(WSResponse{Type: "event", Topic: topic, Event: &event}); err != nil {
+					session.conn.Close()
+					return
+				}
+			}
+		case <-expired:
+			session.close(infrastructure.ErrTokenExpired)
+			return
+		case <-ping.C:
+			// Listeners that never send a message are dropped on a ping.
+			if _, err := w.auth.Recheck(ctx, session.claims); err != nil {
+				session.close(err)
+				return
+			}
+			if err := session.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				session.conn.Close()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func (w *WebSocketController) handle(ctx context.Context, session *wsSession, req WSRequest) WSResponse {
+	ack := WSResponse{Type: "ack", ID: req.ID}
+
+	switch req.Type {
+	case "subscribe", "unsubscribe":
+		if !validTopic(req.Topic) {
+			return ack.fail(WSErrorInvalidTopic, "topic must be tasks, task:<id> or project:<name>")
+		}
+		session.mu.Lock()
+		if req.Type == "subscribe" {
+			session.topics[req.Topic] = true
+		} else {
+			delete(session.topics, req.Topic)
+		}
+		session.mu.Unlock()
+
+	case "create_task":
+		if !session.role.Can(domain.PermissionTaskCreate) {
+			return ack.fail(WSErrorForbidden, "missing permission "+string(domain.PermissionTaskCreate))
+		}
+		if req.Task == nil {
+			return ack.fail(WSErrorBadRequest, "task is required")
+		}
+		task, err := w.taskUseCase.CreateTask(ctx, *req.Task)
+		if err != nil {
+			return ack.fromTaskError(err, WSErrorInternal)
+		}
+		ack.Task = &task
+
+	case "update_task":
+		if !session.role.Can(domain.PermissionTaskUpdate) {
+			return ack.fail(WSErrorForbidden, "missing permission "+string(domain.PermissionTaskUpdate))
+		}
+		if req.Task == nil {
+			return ack.fail(WSErrorBadRequest, "task is required")
+		}
+		task, err := w.taskUseCase.UpdateTask(ctx, req.TaskID, *req.Task)
+		if err != nil {
+			return ack.fromTaskError(err, WSErrorNotFound)
+		}
+		ack.Task = &task
+
+	case "delete_task":
+		if !session.role.Can(domain.PermissionTaskDelete) {
+			return ack.fail(WSErrorForbidden, "missing permission "+string(domain.PermissionTaskDelete))
+		}
+		if err := w.taskUseCase.DeleteTask(ctx, req.TaskID); err != nil {
+			return ack.fromTaskError(err, WSErrorNotFound)
+		}
+
+	default:
+		return ack.fail(WSErrorUnknownType, "unknown message type")
+	}
+
+	ack.OK = true
+	return ack
+}
+
+func (r WSResponse) fail(code, message string) WSResponse {
+	r.OK = false
+	r.Error = &WSError{Code: code, Message: message}
+	return r
+}
+
+func (r WSResponse) fromTaskError(err error, fallback string) WSResponse {
+	switch err {
+	case domain.ErrInvalidTaskTitle, domain.ErrInvalidTaskDescription:
+		return r.fail(WSErrorValidationFailed, err.Error())
+	case domain.ErrTaskForbidden:
+		return r.fail(WSErrorForbidden, err.Error())
+	}
+	if fallback == WSErrorNotFound {
+		return r.fail(WSErrorNotFound, "task not found")
+	}
+	return r.fail(fallback, "failed to process task")
+}
+
+// reply queues a message for the writer and reports false once the writer
+// has given up on the connection.
+func (s *wsSession) reply(response WSResponse) bool {
+	select {
+	case s.send <- response:
+		return true
+	case <-s.writerDone:
+		return false
+	}
+}
+
+// close tells the client why its token no longer holds and drops the
+// connection. Gorilla allows WriteControl and Close alongside the writer.
+func (s *wsSession) close(reason error) {
+	message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason.Error())
+	s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	s.conn.Close()
+}
+
+func (s *wsSession) matchingTopic(event domain.Event) (string, bool) {
+	if event.Task == nil {
+		return "", false
+	}
+
+	candidates := []string{wsTopicAllTasks, wsTopicTask + strconv.Itoa(event.Task.UserID)}
+	if event.Task.Project != "" {
+		candidates = append(candidates, wsTopicProject+event.Task.Project)
+	}
+	if event.Previous != nil && event.Previous.Project != "" {
+		candidates = append(candidates, wsTopicProject+event.Previous.Project)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, topic := range candidates {
+		if s.topics[topic] {
+			return topic, true
+		}
+	}
+	return "", false
+}
+
+func validTopic(topic string) bool {
+	switch {
+	case topic == wsTopicAllTasks:
+		return true
+	case strings.HasPrefix(topic, wsTopicTask):
+		_, err := strconv.Atoi(strings.TrimPrefix(topic, wsTopicTask))
+		return err == nil
+	case strings.HasPrefix(topic, wsTopicProject):
+		return strings.TrimPrefix(topic, wsTopicProject) != ""
+	}
+	return false
+}
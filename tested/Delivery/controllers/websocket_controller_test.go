@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRechecker resolves the token's role, or another role once demoted,
+// until err is set.
+type stubRechecker struct {
+	mu      sync.Mutex
+	demoted string
+	err     error
+}
+
+func (s *stubRechecker) set(demoted string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.demoted, s.err = demoted, err
+}
+
+func (s *stubRechecker) Recheck(_ context.Context, claims *infrastructure.Claims) (domain.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return domain.Role{}, s.err
+	}
+	name := claims.Role
+	if s.demoted != "" {
+		name = s.demoted
+	}
+	role, _ := domain.BuiltInRole(name)
+	return role, nil
+}
+
+func dialWebSocket(t *testing.T, uc domain.TaskUseCase, broker *infrastructure.TaskEventBroker, role string) *websocket.Conn {
+	return dialWebSocketWith(t, uc, broker, &stubRechecker{}, &infrastructure.Claims{Username: "bob", Role: role})
+}
+
+func dialWebSocketWith(t *testing.T, uc domain.TaskUseCase, broker *infrastructure.TaskEventBroker, auth TokenRechecker, claims *infrastructure.Claims) *websocket.Conn {
+	return dialWebSocketController(t, NewWebSocketController(uc, broker, auth, time.Minute), claims)
+}
+
+func dialWebSocketController(t *testing.T, ctrl *WebSocketController, claims *infrastructure.Claims) *websocket.Conn {
+	setupGin()
+	r := gin.New()
+	r.GET("/ws", func(c *gin.Context) {
+		resolved, _ := domain.BuiltInRole(claims.Role)
+		infrastructure.SetCurrentUser(c, claims, resolved)
+	}, ctrl.Connect)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func exchange(t *testing.T, conn *websocket.Conn, req WSRequest) WSResponse {
+	require.NoError(t, conn.WriteJSON(req))
+	return readResponse(t, conn)
+}
+
+func readResponse(t *testing.T, conn *websocket.Conn) WSResponse {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var resp WSResponse
+	require.NoError(t, conn.ReadJSON(&resp))
+	return resp
+}
+
+func TestWebSocket_SubscribeAndReceiveEvents(t *testing.T) {
+	broker := infrastructure.NewTaskEventBroker(10)
+	conn := dialWebSocket(t, new(MockTaskUseCase), broker, "user")
+
+	ack := exchange(t, conn, WSRequest{ID: "1", Type: "subscribe", Topic: "project:apollo"})
+	assert.Equal(t, "ack", ack.Type)
+	assert.Equal(t, "1", ack.ID)
+	assert.True(t, ack.OK)
+
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 1, Project: "gemini"}))
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 2, Project: "apollo"}))
+
+	event := readResponse(t, conn)
+	assert.Equal(t, "event", event.Type)
+	assert.Equal(t, "project:apollo", event.Topic)
+	assert.Equal(t, 2, event.Event.Task.UserID)
+
+	ack = exchange(t, conn, WSRequest{ID: "2", Type: "unsubscribe", Topic: "project:apollo"})
+	assert.True(t, ack.OK)
+	ack = exchange(t, conn, WSRequest{ID: "3", Type: "subscribe", Topic: "task:7"})
+	assert.True(t, ack.OK)
+
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskUpdated, domain.Task{UserID: 3, Project: "apollo"}))
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskDeleted, domain.Task{UserID: 7}))
+
+	event = readResponse(t, conn)
+	assert.Equal(t, "task:7", event.Topic)
+	assert.Equal(t, domain.EventTaskDeleted, event.Event.Type)
+}
+
+func TestWebSocket_ErrorCodes(t *testing.T) {
+	conn := dialWebSocket(t, new(MockTaskUseCase), infrastructure.NewTaskEventBroker(10), "user")
+
+	ack := exchange(t, conn, WSRequest{ID: "1", Type: "subscribe", Topic: "task:abc"})
+	assert.False(t, ack.OK)
+	assert.Equal(t, WSErrorInvalidTopic, ack.Error.Code)
+
+	ack = exchange(t, conn, WSRequest{ID: "2", Type: "launch"})
+	assert.Equal(t, WSErrorUnknownType, ack.Error.Code)
+
+	ack = exchange(t, conn, WSRequest{ID: "3", Type: "delete_task", TaskID: 1})
+	assert.Equal(t, WSErrorForbidden, ack.Error.Code)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{not json")))
+	ack = readResponse(t, conn)
+	assert.Equal(t, WSErrorBadRequest, ack.Error.Code)
+}
+
+func TestWebSocket_AdminMutationsGoThroughUseCase(t *testing.T) {
+	uc := new(MockTaskUseCase)
+	conn := dialWebSocket(t, uc, infrastructure.NewTaskEventBroker(10), "Admin")
+
+	uc.On("CreateTask", domain.Task{Title: "", Description: "d"}).Return(domain.Task{}, domain.ErrInvalidTaskTitle).Once()
+	ack := exchange(t, conn, WSRequest{ID: "1", Type: "create_task", Task: &domain.Task{Description: "d"}})
+	assert.Equal(t, WSErrorValidationFailed, ack.Error.Code)
+
+	updated := domain.Task{Title: "t", Description: "d", Status: "done"}
+	uc.On("UpdateTask", 4, updated).Return(updated, nil).Once()
+	ack = exchange(t, conn, WSRequest{ID: "2", Type: "update_task", TaskID: 4, Task: &updated})
+	assert.True(t, ack.OK)
+	assert.Equal(t, "done", ack.Task.Status)
+
+	uc.On("DeleteTask", 5).Return(assert.AnError).Once()
+	ack = exchange(t, conn, WSRequest{ID: "3", Type: "delete_task", TaskID: 5})
+	assert.Equal(t, WSErrorNotFound, ack.Error.Code)
+
+	ack = exchange(t, conn, WSRequest{ID: "4", Type: "update_task", TaskID: 4})
+	assert.Equal(t, WSErrorBadRequest, ack.Error.Code)
+
+	uc.AssertExpectations(t)
+	uc.AssertNumberOfCalls(t, "UpdateTask", 1)
+}
+
+func TestWebSocket_RechecksTheTokenBeforeEachMessage(t *testing.T) {
+	uc := new(MockTaskUseCase)
+	auth := &stubRechecker{}
+	conn := dialWebSocketWith(t, uc, infrastructure.NewTaskEventBroker(10), auth, &infrastructure.Claims{Username: "root", Role: domain.RoleAdmin})
+
+	auth.set(domain.RoleUser, nil)
+	ack := exchange(t, conn, WSRequest{ID: "1", Type: "delete_task", TaskID: 5})
+	assert.Equal(t, WSErrorForbidden, ack.Error.Code, "a demotion applies to an open connection")
+
+	auth.set("", domain.ErrUserDisabled)
+	require.NoError(t, conn.WriteJSON(WSRequest{ID: "2", Type: "delete_task", TaskID: 5}))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	assert.Equal(t, domain.ErrUserDisabled.Error(), closeErr.Text)
+	uc.AssertNotCalled(t, "DeleteTask", mock.Anything)
+}
+
+func TestWebSocket_ClosesWhenTheTokenExpires(t *testing.T) {
+	claims := &infrastructure.Claims{Username: "bob", Role: domain.RoleUser}
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(100 * time.Millisecond))
+	conn := dialWebSocketWith(t, new(MockTaskUseCase), infrastructure.NewTaskEventBroker(10), &stubRechecker{}, claims)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	assert.Equal(t, infrastructure.ErrTokenExpired.Error(), closeErr.Text)
+}
+
+func TestWebSocket_ClosesOnOversizedMessages(t *testing.T) {
+	conn := dialWebSocket(t, new(MockTaskUseCase), infrastructure.NewTaskEventBroker(10), "user")
+
+	require.NoError(t, conn.WriteJSON(WSRequest{ID: strings.Repeat("x", wsMaxMessageSize), Type: "subscribe", Topic: "tasks"}))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseMessageTooBig, closeErr.Code)
+}
+
+func TestWebSocket_DropsClientsThatStopAnsweringPings(t *testing.T) {
+	ctrl := NewWebSocketController(new(MockTaskUseCase), infrastructure.NewTaskEventBroker(10), &stubRechecker{}, 20*time.Millisecond)
+	claims := &infrastructure.Claims{Username: "bob", Role: domain.RoleUser}
+
+	// Answering pings keeps the connection open past several deadlines.
+	alive := dialWebSocketController(t, ctrl, claims)
+	alive.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, _, err := alive.ReadMessage()
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout(), "the server should not have closed the connection")
+
+	silent := dialWebSocketController(t, ctrl, claims)
+	silent.SetPingHandler(func(string) error { return nil })
+	silent.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = silent.ReadMessage()
+	require.Error(t, err)
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "the server kept a silent client")
+	}
+}
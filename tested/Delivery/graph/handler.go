@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"net/http"
+
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type Handler struct {
+	schema graphql.Schema
+	limits Limits
+}
+
+func NewHandler(schema graphql.Schema, limits Limits) *Handler {
+	return &Handler{
+		schema: schema,
+		limits: limits,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Serve expects the caller to have gone through JWTAuthMiddleware; the
+// username and role it stored are handed to the resolvers.
+func (h *Handler) Serve(c *gin.Context) {
+	var req request
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"message": "request body must contain a query"}}})
+		return
+	}
+
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"message": err.Error()}}})
+		return
+	}
+	if err := h.limits.Check(document, req.Variables); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"message": err.Error()}}})
+		return
+	}
+
+	var username string
+	if claims, ok := infrastructure.CurrentUser(c); ok {
+		username = claims.Username
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        WithCaller(c.Request.Context(), username, infrastructure.CurrentRole(c)),
+	})
+
+	c.JSON(http.StatusOK, result)
+}
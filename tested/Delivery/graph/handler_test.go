@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type gqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func doGraphQL(t *testing.T, tasks *MockTaskUseCase, users *MockUserUseCase, role, query string, variables map[string]interface{}) (int, gqlResponse) {
+	gin.SetMode(gin.TestMode)
+	schema, err := NewSchema(tasks, users)
+	require.NoError(t, err)
+	handler := NewHandler(schema, Limits{MaxDepth: 4, MaxComplexity: 200})
+
+	r := gin.New()
+	r.POST("/graphql", func(c *gin.Context) {
+		resolved, _ := domain.BuiltInRole(role)
+		infrastructure.SetCurrentUser(c, &infrastructure.Claims{Username: "bob", Role: role}, resolved)
+	}, handler.Serve)
+
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	var resp gqlResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestGraphQL_TasksFilteringAndPagination(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	tasks.On("GetAllTasks").Return([]domain.Task{
+		{UserID: 1, Title: "Write docs", Status: "open", Project: "apollo"},
+		{UserID: 2, Title: "Fix bug", Status: "done", Project: "apollo"},
+		{UserID: 3, Title: "Write tests", Status: "open", Project: "apollo"},
+		{UserID: 4, Title: "Write code", Status: "open", Project: "gemini"},
+	}, nil).Once()
+
+	code, resp := doGraphQL(t, tasks, new(MockUserUseCase), "user",
+		`query($limit: Int) { tasks(status: "open", project: "apollo", search: "write", limit: $limit, offset: 1) { totalCount items { id title } } }`,
+		map[string]interface{}{"limit": 1})
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"totalCount":2,"items":[{"id":3,"title":"Write tests"}]}`, string(resp.Data["tasks"]))
+}
+
+func TestGraphQL_MutationsRequirePermission(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	users := new(MockUserUseCase)
+
+	_, resp := doGraphQL(t, tasks, users, "user", `mutation { deleteTask(id: 1) }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "missing permission task:delete", resp.Errors[0].Message)
+	tasks.AssertNotCalled(t, "DeleteTask", 1)
+
+	_, resp = doGraphQL(t, tasks, users, "user", `{ users { totalCount } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "missing permission user:list", resp.Errors[0].Message)
+}
+
+func TestGraphQL_AdminMutations(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	users := new(MockUserUseCase)
+
+	updated := domain.Task{Title: "t", Description: "d", Status: "done"}
+	tasks.On("UpdateTask", 7, updated).Return(updated, nil).Once()
+	tasks.On("CreateTask", domain.Task{Title: "", Description: "d"}).Return(domain.Task{}, domain.ErrInvalidTaskTitle).Once()
+	tasks.On("CreateTask", domain.Task{Title: "t", Description: "d"}).Return(domain.Task{UserID: 9, Title: "t", Description: "d", CreatedBy: "bob"}, nil).Once()
+	users.On("PromoteUser", "carol").Return(nil).Once()
+
+	_, resp := doGraphQL(t, tasks, users, "Admin",
+		`mutation { updateTask(id: 7, input: {title: "t", description: "d", status: "done"}) { id status } promoteUser(username: "carol") }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"id":7,"status":"done"}`, string(resp.Data["updateTask"]))
+	assert.Equal(t, "true", string(resp.Data["promoteUser"]))
+
+	_, resp = doGraphQL(t, tasks, users, "Admin", `mutation { createTask(input: {title: "", description: "d"}) { id } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, domain.ErrInvalidTaskTitle.Error(), resp.Errors[0].Message)
+
+	_, resp = doGraphQL(t, tasks, users, "Admin", `mutation { createTask(input: {title: "t", description: "d"}) { id title createdBy } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"id":9,"title":"t","createdBy":"bob"}`, string(resp.Data["createTask"]))
+
+	tasks.AssertExpectations(t)
+	users.AssertExpectations(t)
+}
+
+func TestGraphQL_UserLookupIsSelfOrAdmin(t *testing.T) {
+	users := new(MockUserUseCase)
+	users.On("GetUser", "bob").Return(domain.User{UserName: "bob", Role: "user"}, nil).Once()
+
+	_, resp := doGraphQL(t, new(MockTaskUseCase), users, "user", `{ user(username: "bob") { username role } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"username":"bob","role":"user"}`, string(resp.Data["user"]))
+
+	_, resp = doGraphQL(t, new(MockTaskUseCase), users, "user", `{ user(username: "alice") { username } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	users.AssertNotCalled(t, "GetUser", "alice")
+}
+
+func TestGraphQL_UsersArePagedByTheUseCase(t *testing.T) {
+	users := new(MockUserUseCase)
+	users.On("ListUsers", domain.UserQuery{Search: "ali", Offset: 0, Limit: 5}).Return(domain.UserPage{
+		Users: []domain.User{{UserName: "alice"}},
+		Total: 7,
+	}, nil).Once()
+
+	_, resp := doGraphQL(t, new(MockTaskUseCase), users, "Admin", `{ users(search: "ali", limit: 5, offset: -3) { totalCount items { username } } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"totalCount":7,"items":[{"username":"alice"}]}`, string(resp.Data["users"]))
+	users.AssertExpectations(t)
+}
+
+func TestGraphQL_LookupsHideRepositoryErrors(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	users := new(MockUserUseCase)
+	tasks.On("GetTaskByID", 1).Return(domain.Task{}, domain.ErrTaskNotFound).Once()
+	tasks.On("GetTaskByID", 2).Return(domain.Task{}, errors.New("connection to mongo-0:27017 reset")).Once()
+	users.On("GetUser", "ghost").Return(domain.User{}, domain.ErrUserNotFound).Once()
+	users.On("GetUser", "alice").Return(domain.User{}, errors.New("connection to mongo-0:27017 reset")).Once()
+
+	_, resp := doGraphQL(t, tasks, users, "Admin", `{ missing: task(id: 1) { id } ghost: user(username: "ghost") { username } }`, nil)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, "null", string(resp.Data["missing"]))
+	assert.Equal(t, "null", string(resp.Data["ghost"]))
+
+	_, resp = doGraphQL(t, tasks, users, "Admin", `{ broken: task(id: 2) { id } alice: user(username: "alice") { username } }`, nil)
+	require.Len(t, resp.Errors, 2)
+	for _, e := range resp.Errors {
+		assert.Equal(t, ErrInternal.Error(), e.Message)
+	}
+	tasks.AssertExpectations(t)
+	users.AssertExpectations(t)
+}
+
+func TestGraphQL_DepthAndComplexityLimits(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	tasks.On("GetAllTasks").Return([]domain.Task{}, nil)
+
+	// fragments count towards depth like inline selections
+	code, resp := doGraphQL(t, tasks, new(MockUserUseCase), "Admin",
+		`query { tasks { items { ...fields } } } fragment fields on Task { id ... on Task { title } }`, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, resp.Errors)
+
+	// each default page costs 1 + 2*20 = 41
+	code, _ = doGraphQL(t, tasks, new(MockUserUseCase), "Admin",
+		`{ a: tasks { items { id } } b: tasks { items { id } } c: tasks { items { id } } d: tasks { items { id } } }`, nil)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, resp = doGraphQL(t, tasks, new(MockUserUseCase), "Admin",
+		`{ a: tasks { items { id } } b: tasks { items { id } } c: tasks { items { id } } d: tasks { items { id } } e: tasks { items { id } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrQueryTooComplex.Error(), resp.Errors[0].Message)
+
+	code, resp = doGraphQL(t, tasks, new(MockUserUseCase), "Admin",
+		`{ tasks(limit: 100) { items { id title description status } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrQueryTooComplex.Error(), resp.Errors[0].Message)
+
+	code, resp = doGraphQL(t, tasks, new(MockUserUseCase), "Admin",
+		`{ __schema { types { fields { type { ofType { name } } } } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrQueryTooDeep.Error(), resp.Errors[0].Message)
+}
@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const MaxPageSize = 100
+
+var (
+	ErrQueryTooDeep    = errors.New("query exceeds the maximum depth")
+	ErrQueryTooComplex = errors.New("query exceeds the maximum complexity")
+)
+
+// Limits bounds what a single GraphQL request may ask for. Depth counts
+// nested selection sets; complexity counts fields, multiplying everything
+// below a paginated field by the page size it requests.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type queryAnalyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+func (l Limits) Check(document *ast.Document, variables map[string]interface{}) error {
+	analyzer := queryAnalyzer{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		visiting:  map[string]bool{},
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			analyzer.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		depth, complexity := analyzer.measure(operation.SelectionSet)
+		if l.MaxDepth > 0 && depth > l.MaxDepth {
+			return ErrQueryTooDeep
+		}
+		if l.MaxComplexity > 0 && complexity > l.MaxComplexity {
+			return ErrQueryTooComplex
+		}
+	}
+	return nil
+}
+
+func (a queryAnalyzer) measure(selectionSet *ast.SelectionSet) (int, int) {
+	if selectionSet == nil {
+		return 0, 0
+	}
+
+	maxDepth, complexity := 0, 0
+	for _, selection := range selectionSet.Selections {
+		var depth, cost int
+		switch node := selection.(type) {
+		case *ast.Field:
+			childDepth, childCost := a.measure(node.SelectionSet)
+			depth = childDepth + 1
+			cost = 1 + childCost*a.pageSize(node)
+		case *ast.InlineFragment:
+			depth, cost = a.measure(node.SelectionSet)
+		case *ast.FragmentSpread:
+			name := node.Name.Value
+			fragment, ok := a.fragments[name]
+			if !ok || a.visiting[name] {
+				continue
+			}
+			a.visiting[name] = true
+			depth, cost = a.measure(fragment.SelectionSet)
+			delete(a.visiting, name)
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		complexity += cost
+	}
+	return maxDepth, complexity
+}
+
+func (a queryAnalyzer) pageSize(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		limit := defaultPageSize
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			limit, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch v := a.variables[value.Name.Value].(type) {
+			case float64:
+				limit = int(v)
+			case int:
+				limit = v
+			}
+		}
+		if limit <= 0 || limit > MaxPageSize {
+			limit = MaxPageSize
+		}
+		return limit
+	}
+	if field.Name.Value == "tasks" || field.Name.Value == "users" {
+		return defaultPageSize
+	}
+	return 1
+}
@@ -0,0 +1,412 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/graphql-go/graphql"
+)
+
+var (
+	ErrAuthenticationRequired = errors.New("authentication required")
+	ErrPermissionDenied       = errors.New("missing permission")
+	ErrInternal               = errors.New("internal error")
+)
+
+const defaultPageSize = 20
+
+type contextKey string
+
+const (
+	usernameKey contextKey = "username"
+	roleKey     contextKey = "role"
+)
+
+// WithCaller stores the authenticated caller so resolvers can apply the same
+// checks as the Gin routes.
+func WithCaller(ctx context.Context, username string, role domain.Role) context.Context {
+	ctx = context.WithValue(ctx, usernameKey, username)
+	return context.WithValue(ctx, roleKey, role)
+}
+
+func callerFrom(ctx context.Context) (string, domain.Role) {
+	username, _ := ctx.Value(usernameKey).(string)
+	role, _ := ctx.Value(roleKey).(domain.Role)
+	return username, role
+}
+
+func requireCaller(ctx context.Context) (string, error) {
+	username, _ := callerFrom(ctx)
+	if username == "" {
+		return "", ErrAuthenticationRequired
+	}
+	return username, nil
+}
+
+func requirePermission(ctx context.Context, permission domain.Permission) error {
+	if _, err := requireCaller(ctx); err != nil {
+		return err
+	}
+	if _, role := callerFrom(ctx); !role.Can(permission) {
+		return fmt.Errorf("%w %s", ErrPermissionDenied, permission)
+	}
+	return nil
+}
+
+// internalError logs err and hides it behind ErrInternal, so repository
+// failures never reach the client verbatim.
+func internalError(ctx context.Context, field string, err error) error {
+	infrastructure.LoggerFrom(ctx).Error("graphql resolver failed", "field", field, "error", err)
+	return ErrInternal
+}
+
+type taskPage struct {
+	Items      []domain.Task `json:"items"`
+	TotalCount int           `json:"totalCount"`
+}
+
+type userPage struct {
+	Items      []domain.User `json:"items"`
+	TotalCount int           `json:"totalCount"`
+}
+
+// NewSchema builds the GraphQL schema over the task and user use cases.
+func NewSchema(taskUseCase domain.TaskUseCase, userUseCase domain.UserUseCase) (graphql.Schema, error) {
+	taskType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.Task).UserID, nil
+				},
+			},
+			"title":       &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"status":      &graphql.Field{Type: graphql.String},
+			"assignee":    &graphql.Field{Type: graphql.String},
+			"project":     &graphql.Field{Type: graphql.String},
+			"createdBy": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.Task).CreatedBy, nil
+				},
+			},
+			"dueDate": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					task := p.Source.(domain.Task)
+					if task.DueDate.IsZero() {
+						return nil, nil
+					}
+					return task.DueDate, nil
+				},
+			},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.User).ID.Hex(), nil
+				},
+			},
+			"username": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(domain.User).UserName, nil
+				},
+			},
+			"role":     &graphql.Field{Type: graphql.String},
+			"email":    &graphql.Field{Type: graphql.String},
+			"disabled": &graphql.Field{Type: graphql.Boolean},
+		},
+	})
+
+	taskPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaskPage",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType)))},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	userPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserPage",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType)))},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	taskInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TaskInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"status":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"assignee":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"project":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"dueDate":     &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+	}
+	taskListArgs := graphql.FieldConfigArgument{
+		"status":   &graphql.ArgumentConfig{Type: graphql.String},
+		"assignee": &graphql.ArgumentConfig{Type: graphql.String},
+		"project":  &graphql.ArgumentConfig{Type: graphql.String},
+		"search":   &graphql.ArgumentConfig{Type: graphql.String},
+	}
+	userListArgs := graphql.FieldConfigArgument{
+		"search": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+	for name, arg := range pageArgs {
+		taskListArgs[name] = arg
+		userListArgs[name] = arg
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"tasks": &graphql.Field{
+				Type: graphql.NewNonNull(taskPageType),
+				Args: taskListArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if _, err := requireCaller(p.Context); err != nil {
+						return nil, err
+					}
+					tasks, err := taskUseCase.GetAllTasks(p.Context)
+					if err != nil {
+						return nil, internalError(p.Context, "tasks", err)
+					}
+					filtered := filterTasks(tasks, p.Args)
+					return taskPage{Items: paginate(filtered, p.Args), TotalCount: len(filtered)}, nil
+				},
+			},
+			"task": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if _, err := requireCaller(p.Context); err != nil {
+						return nil, err
+					}
+					task, err := taskUseCase.GetTaskByID(p.Context, p.Args["id"].(int))
+					switch {
+					case errors.Is(err, domain.ErrTaskNotFound):
+						return nil, nil
+					case err != nil:
+						return nil, internalError(p.Context, "task", err)
+					}
+					return task, nil
+				},
+			},
+			"me": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					username, err := requireCaller(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					user, err := userUseCase.GetUser(p.Context, username)
+					if err != nil {
+						return nil, internalError(p.Context, "me", err)
+					}
+					return user, nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"username": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					username, err := requireCaller(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					requested := p.Args["username"].(string)
+					if requested != username {
+						if err := requirePermission(p.Context, domain.PermissionUserList); err != nil {
+							return nil, err
+						}
+					}
+					user, err := userUseCase.GetUser(p.Context, requested)
+					switch {
+					case errors.Is(err, domain.ErrUserNotFound):
+						return nil, nil
+					case err != nil:
+						return nil, internalError(p.Context, "user", err)
+					}
+					return user, nil
+				},
+			},
+			"users": &graphql.Field{
+				Type: graphql.NewNonNull(userPageType),
+				Args: userListArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requirePermission(p.Context, domain.PermissionUserList); err != nil {
+						return nil, err
+					}
+					query := domain.UserQuery{}
+					query.Search, _ = p.Args["search"].(string)
+					query.Limit, _ = p.Args["limit"].(int)
+					query.Offset, _ = p.Args["offset"].(int)
+					query.Offset = max(query.Offset, 0)
+					page, err := userUseCase.ListUsers(p.Context, query)
+					if err != nil {
+						return nil, internalError(p.Context, "users", err)
+					}
+					return userPage{Items: page.Users, TotalCount: int(page.Total)}, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(taskInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requirePermission(p.Context, domain.PermissionTaskCreate); err != nil {
+						return nil, err
+					}
+					return taskUseCase.CreateTask(p.Context, taskFromInput(p.Args["input"]))
+				},
+			},
+			"updateTask": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(taskInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requirePermission(p.Context, domain.PermissionTaskUpdate); err != nil {
+						return nil, err
+					}
+					id := p.Args["id"].(int)
+					task, err := taskUseCase.UpdateTask(p.Context, id, taskFromInput(p.Args["input"]))
+					if err != nil {
+						return nil, err
+					}
+					task.UserID = id
+					return task, nil
+				},
+			},
+			"deleteTask": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requirePermission(p.Context, domain.PermissionTaskDelete); err != nil {
+						return nil, err
+					}
+					if err := taskUseCase.DeleteTask(p.Context, p.Args["id"].(int)); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+			"promoteUser": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"username": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requirePermission(p.Context, domain.PermissionUserPromote); err != nil {
+						return nil, err
+					}
+					if err := userUseCase.PromoteUser(p.Context, p.Args["username"].(string)); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func taskFromInput(raw interface{}) domain.Task {
+	input, _ := raw.(map[string]interface{})
+	task := domain.Task{}
+	task.Title, _ = input["title"].(string)
+	task.Description, _ = input["description"].(string)
+	task.Status, _ = input["status"].(string)
+	task.Assignee, _ = input["assignee"].(string)
+	task.Project, _ = input["project"].(string)
+	if dueDate, ok := input["dueDate"].(time.Time); ok {
+		task.DueDate = dueDate
+	}
+	return task
+}
+
+func filterTasks(tasks []domain.Task, args map[string]interface{}) []domain.Task {
+	status, _ := args["status"].(string)
+	assignee, _ := args["assignee"].(string)
+	project, _ := args["project"].(string)
+	search, _ := args["search"].(string)
+	search = strings.ToLower(search)
+
+	filtered := []domain.Task{}
+	for _, task := range tasks {
+		if status != "" && task.Status != status {
+			continue
+		}
+		if assignee != "" && task.Assignee != assignee {
+			continue
+		}
+		if project != "" && task.Project != project {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(task.Title), search) &&
+			!strings.Contains(strings.ToLower(task.Description), search) {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+	return filtered
+}
+
+func paginate[T any](items []T, args map[string]interface{}) []T {
+	limit, _ := args["limit"].(int)
+	offset, _ := args["offset"].(int)
+	if limit <= 0 || limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
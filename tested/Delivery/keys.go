@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	config "task-manager/Config"
+	infrastructure "task-manager/Infrastructure"
+)
+
+// signingKeys loads the configured RS256/EdDSA keys, falling back to the
+// HS256 secret while none are listed.
+func signingKeys(cfg config.JWTConfig) (*infrastructure.KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return infrastructure.NewKeySet(0, infrastructure.SigningKey{Algorithm: infrastructure.AlgorithmHS256, Key: []byte(cfg.Secret)})
+	}
+	keys := make([]infrastructure.SigningKey, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		pem, err := os.ReadFile(key.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := infrastructure.ParseSigningKey(key.ID, key.Algorithm, pem, key.ActivatesAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, parsed)
+	}
+	return infrastructure.NewKeySet(time.Duration(cfg.RotationGrace), keys...)
+}
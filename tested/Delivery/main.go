@@ -2,47 +2,402 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	config "task-manager/Config"
 	"task-manager/Delivery/controllers"
+	"task-manager/Delivery/graph"
+	"task-manager/Delivery/openapi"
 	"task-manager/Delivery/routers"
+	"task-manager/Delivery/rpc"
+	domain "task-manager/Domain"
 	infrastructure "task-manager/Infrastructure"
 	repositories "task-manager/Repositories"
 	usecases "task-manager/Usecases"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"google.golang.org/grpc"
 )
 
 func main() {
+	// exitCode is set when setup fails once resources are held or a server
+	// fails once running, and only acted on after the deferred cleanup below
+	// it has run.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+	// fail replaces fatal once there is deferred cleanup: callers return
+	// straight after it, so the cleanup still runs.
+	fail := func(msg string, err error) {
+		slog.Error(msg, "error", err)
+		exitCode = 1
+	}
+
+	cfg, args, err := config.Load(os.Args[1:], os.Getenv)
+	var usageErr *config.UsageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprint(os.Stderr, usageErr.Usage)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+	logger := infrastructure.NewLogger(os.Stderr, cfg.Log.Level)
+	slog.SetDefault(logger)
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	shutdownTracing, err := infrastructure.SetupTracing(context.Background(), infrastructure.TracingConfig{
+		Exporter:    cfg.Tracing.Exporter,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Stdout:      os.Stdout,
+	})
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
 
-	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(cfg.Mongo.URI).SetMonitor(otelmongo.NewMonitor()))
 	if err != nil {
-		log.Fatal("Failed to connect to MongoDB:", err)
+		fatal("failed to connect to MongoDB", err)
 	}
 	defer client.Disconnect(context.TODO())
 
-	tasksCollection := client.Database("taskmanagerdb").Collection("tasks")
-	usersCollection := client.Database("taskmanagerdb").Collection("users")
+	database := client.Database(cfg.Mongo.Database)
+	tasksCollection := database.Collection("tasks")
+	usersCollection := database.Collection("users")
+	webhooksCollection := database.Collection("webhooks")
+	deadLettersCollection := database.Collection("webhook_dead_letters")
+	commentsCollection := database.Collection("comments")
+	loginAttemptsCollection := database.Collection("login_attempts")
+	refreshTokensCollection := database.Collection("refresh_tokens")
+	revokedTokensCollection := database.Collection("revoked_tokens")
+	rolesCollection := database.Collection("roles")
 
-	jwtService := infrastructure.NewJWTService()
+	migrator := repositories.NewMigrator(database, repositories.Migrations)
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), time.Minute)
+	err = migrator.Migrate(migrateCtx)
+	cancelMigrate()
+	if err != nil {
+		fail("failed to migrate the database", err)
+		return
+	}
+
+	metrics := infrastructure.NewMetrics()
+	tokenDenylist := repositories.InstrumentTokenDenylist(repositories.NewTokenDenylist(revokedTokensCollection), metrics.ObserveMongo)
+
+	keySet, err := signingKeys(cfg.JWT)
+	if err != nil {
+		fail("failed to load the JWT signing keys", err)
+		return
+	}
+	jwtService := infrastructure.NewKeySetJWTService(keySet, infrastructure.JWTOptions{
+		Expiry:   time.Duration(cfg.JWT.Expiry),
+		Issuer:   cfg.JWT.Issuer,
+		Audience: cfg.JWT.Audience,
+		Leeway:   time.Duration(cfg.JWT.Leeway),
+	})
 	passwordService := infrastructure.NewPasswordService()
-	authMiddleware := infrastructure.NewAuthMiddleware(jwtService)
 
-	taskRepository := repositories.NewTaskRepository(tasksCollection)
-	userRepository := repositories.NewUserRepository(usersCollection)
+	taskRepository := repositories.InstrumentTaskRepository(repositories.NewTaskRepository(tasksCollection), metrics.ObserveMongo)
+	userRepository := repositories.InstrumentUserRepository(repositories.NewUserRepository(usersCollection), metrics.ObserveMongo)
+	webhookRepository := repositories.InstrumentWebhookRepository(repositories.NewWebhookRepository(webhooksCollection, deadLettersCollection), metrics.ObserveMongo)
+	commentRepository := repositories.InstrumentCommentRepository(repositories.NewCommentRepository(commentsCollection), metrics.ObserveMongo)
+	loginAttemptRepository := repositories.InstrumentLoginAttemptRepository(repositories.NewLoginAttemptRepository(loginAttemptsCollection), metrics.ObserveMongo)
+	refreshTokenRepository := repositories.InstrumentRefreshTokenRepository(repositories.NewRefreshTokenRepository(refreshTokensCollection), metrics.ObserveMongo)
+	roleRepository := repositories.InstrumentRoleRepository(repositories.NewRoleRepository(rolesCollection), metrics.ObserveMongo)
+
+	if len(args) > 0 && args[0] == "admin" {
+		// No subscribers: the process exits before webhooks could be delivered.
+		adminUseCase := usecases.NewAdminUseCase(userRepository, passwordService, infrastructure.NewEventBus())
+		code := runAdmin(context.Background(), adminUseCase, args[1:], os.Stdout, os.Stderr)
+		client.Disconnect(context.TODO())
+		os.Exit(code)
+	}
+
+	webhookDispatcher := infrastructure.NewWebhookDispatcher(
+		webhookRepository,
+		&http.Client{Timeout: 10 * time.Second},
+		5,
+		time.Second,
+		4,
+		1000,
+	)
+
+	workers := infrastructure.NewWorkers()
+	defer workers.Stop()
+	workers.Go("webhook-deliveries", webhookDispatcher.Run)
+	taskEventBroker := infrastructure.NewTaskEventBroker(1000)
+	eventBus := infrastructure.NewEventBus(webhookDispatcher, taskEventBroker)
+
+	if cfg.SMTP.Addr != "" {
+		notifier := infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{
+			Addr:     cfg.SMTP.Addr,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		}, 100)
+		defer notifier.Close()
+
+		notificationUseCase := usecases.NewNotificationUseCase(userRepository, taskRepository, notifier)
+		eventBus.Subscribe(notificationUseCase)
 
-	taskUseCase := usecases.NewTaskUseCase(taskRepository)
-	userUseCase := usecases.NewUserUseCase(userRepository, passwordService, jwtService)
+		workers.Go("due-date-reminders", func(ctx context.Context) error {
+			ticker := time.NewTicker(15 * time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					if err := notificationUseCase.SendDueDateReminders(ctx, 24*time.Hour); err != nil {
+						slog.Error("failed to send due date reminders", "error", err)
+					}
+				}
+			}
+		})
+	}
+
+	workers.Go("task-metrics", func(ctx context.Context) error {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			tasks, err := taskRepository.GetAllTasks(ctx)
+			if err != nil {
+				slog.Error("failed to collect task metrics", "error", err)
+			} else {
+				metrics.RecordTasks(tasks, time.Now())
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+
+	if err := bootstrapAdmin(context.Background(), usecases.NewAdminUseCase(userRepository, passwordService, eventBus)); err != nil {
+		fail("failed to bootstrap the first admin", err)
+		return
+	}
+
+	policyEngine := infrastructure.NewPolicyEngine(nil)
+	if cfg.Policy.File != "" {
+		policies, err := infrastructure.LoadPolicyFile(cfg.Policy.File)
+		if err != nil {
+			fail("failed to load the task policies", err)
+			return
+		}
+		policyEngine = infrastructure.NewPolicyEngine(policies)
+		workers.Go("policy-reload", func(ctx context.Context) error {
+			return policyEngine.Watch(ctx, cfg.Policy.File, time.Duration(cfg.Policy.ReloadInterval))
+		})
+	}
+
+	taskUseCase := usecases.TraceTaskUseCase(usecases.NewTaskUseCase(taskRepository, eventBus, policyEngine))
+	loginGuard := usecases.NewLoginGuard(loginAttemptRepository, domain.LoginPolicy{
+		FreeAttempts:    cfg.Login.FreeAttempts,
+		MaxDelay:        time.Duration(cfg.Login.MaxDelay),
+		UserThreshold:   cfg.Login.UserThreshold,
+		IPThreshold:     cfg.Login.IPThreshold,
+		Window:          time.Duration(cfg.Login.Window),
+		LockoutDuration: time.Duration(cfg.Login.LockoutDuration),
+	})
+	userUseCase := usecases.TraceUserUseCase(usecases.NewUserUseCase(
+		userRepository, passwordService, jwtService, eventBus, metrics, loginGuard,
+		refreshTokenRepository, tokenDenylist, time.Duration(cfg.JWT.RefreshExpiry),
+	))
+	commentUseCase := usecases.TraceCommentUseCase(usecases.NewCommentUseCase(commentRepository, taskRepository, eventBus))
+	webhookUseCase := usecases.TraceWebhookUseCase(usecases.NewWebhookUseCase(webhookRepository, webhookDispatcher))
+	roleUseCase := usecases.TraceRoleUseCase(usecases.NewRoleUseCase(roleRepository, userRepository, eventBus))
+	userCache := infrastructure.NewUserCache(userRepository, time.Duration(cfg.JWT.UserCacheTTL))
+	authMiddleware := infrastructure.NewAuthMiddleware(jwtService, tokenDenylist, roleUseCase, userCache)
 
 	taskController := controllers.NewTaskController(taskUseCase)
 	userController := controllers.NewUserController(userUseCase)
+	commentController := controllers.NewCommentController(commentUseCase)
+	webhookController := controllers.NewWebhookController(webhookUseCase)
+	eventController := controllers.NewEventController(taskEventBroker, authMiddleware, 15*time.Second)
+	wsController := controllers.NewWebSocketController(taskUseCase, taskEventBroker, authMiddleware, 30*time.Second)
+	taskControllerV2 := controllers.NewTaskControllerV2(taskUseCase)
+	userControllerV2 := controllers.NewUserControllerV2(userUseCase)
+	commentControllerV2 := controllers.NewCommentControllerV2(commentUseCase)
+	healthController := controllers.NewHealthController(2*time.Second,
+		controllers.ReadinessCheck{Name: "mongo", Check: func(ctx context.Context) error { return client.Ping(ctx, nil) }},
+		controllers.ReadinessCheck{Name: "migrations", Check: migrator.Check},
+		controllers.ReadinessCheck{Name: "workers", Check: workers.Check},
+	)
+
+	graphSchema, err := graph.NewSchema(taskUseCase, userUseCase)
+	if err != nil {
+		fail("failed to build GraphQL schema", err)
+		return
+	}
+	graphHandler := graph.NewHandler(graphSchema, graph.Limits{MaxDepth: 6, MaxComplexity: 1000})
 
-	router := routers.NewRouter(taskController, userController, authMiddleware)
+	apiSpec, err := openapi.Load()
+	if err != nil {
+		fail("failed to load OpenAPI spec", err)
+		return
+	}
+	var specValidator gin.HandlerFunc
+	if cfg.OpenAPI.Validation {
+		specValidator = openapi.NewValidator(apiSpec, openapi.ValidatorOptions{
+			ValidateResponses: gin.Mode() == gin.DebugMode,
+		})
+	}
+
+	var rateLimits routers.RateLimits
+	var grpcInterceptors []grpc.UnaryServerInterceptor
+	if cfg.RateLimit.Enabled {
+		limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), authMiddleware.Caller)
+		policy := func(name string, p config.RateLimitPolicy) infrastructure.RateLimitPolicy {
+			return infrastructure.RateLimitPolicy{Name: name, Requests: p.Requests, Per: time.Duration(p.Per), Burst: p.Burst}
+		}
+		login := policy("login", cfg.RateLimit.Login)
+		register := policy("register", cfg.RateLimit.Register)
+		refresh := policy("refresh", cfg.RateLimit.Refresh)
+		rateLimits = routers.RateLimits{
+			Login:    limiter.Limit(login),
+			Register: limiter.Limit(register),
+			Refresh:  limiter.Limit(refresh),
+			API:      limiter.Limit(policy("api", cfg.RateLimit.API)),
+		}
+		// The same buckets, so switching transports buys no extra attempts.
+		grpcInterceptors = append(grpcInterceptors, rpc.RateLimitInterceptor(limiter, rpc.RateLimits{
+			"/taskmanager.v1.UserService/Login":        login,
+			"/taskmanager.v1.UserService/RegisterUser": register,
+			"/taskmanager.v1.UserService/RefreshToken": refresh,
+		}))
+	}
+
+	router := routers.NewRouter(
+		taskController,
+		userController,
+		commentController,
+		webhookController,
+		eventController,
+		wsController,
+		taskControllerV2,
+		userControllerV2,
+		commentControllerV2,
+		healthController,
+		controllers.NewJWKSController(keySet),
+		controllers.NewRoleController(roleUseCase),
+		controllers.NewPolicyController(policyEngine, taskUseCase),
+		graphHandler,
+		openapi.NewHandler(),
+		specValidator,
+		infrastructure.RequestLogger(logger),
+		metrics,
+		rateLimits,
+		authMiddleware,
+	)
 
 	app := router.SetupRoutes()
+	if err := app.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		fail("invalid trusted proxies", err)
+		return
+	}
+
+	shutdownTimeout := time.Duration(cfg.HTTP.ShutdownTimeout)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	grpcListener, err := net.Listen("tcp", cfg.GRPC.Addr)
+	if err != nil {
+		fail("failed to listen for gRPC", err)
+		return
+	}
+	// Either server failing shuts down both; one slot each so neither blocks.
+	serveErr := make(chan error, 2)
+	grpcServer := rpc.NewServer(taskUseCase, userUseCase, authMiddleware, grpcInterceptors...)
+	workers.Go("grpc", func(ctx context.Context) error {
+		go func() {
+			<-ctx.Done()
+			stopGRPC(grpcServer, shutdownTimeout)
+		}()
+		slog.Info("gRPC server starting", "addr", cfg.GRPC.Addr)
+		// Serve returns nil once stopped, so an error is a failure.
+		err := grpcServer.Serve(grpcListener)
+		if err != nil {
+			serveErr <- fmt.Errorf("gRPC server: %w", err)
+		}
+		return err
+	})
+
+	server := &http.Server{Addr: cfg.HTTP.Addr, Handler: app}
+	// Event streams never finish on their own, so end them when draining
+	// starts instead of waiting out the timeout.
+	server.RegisterOnShutdown(taskEventBroker.Close)
+	go func() {
+		slog.Info("HTTP server starting", "addr", cfg.HTTP.Addr)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		fail("server failed", err)
+	}
+	// A second signal kills the process without waiting for the drain.
+	stop()
+	slog.Info("shutting down", "drain_timeout", shutdownTimeout.String())
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("drain timed out, closing remaining connections", "error", err)
+		server.Close()
+	}
+	workers.Stop()
 
-	log.Println("Server starting on port 8080...")
-	if err := app.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Flushing spans gets its own budget so a slow drain does not lose the
+	// traces that explain it.
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
 	}
 }
+
+// stopGRPC lets in-flight RPCs finish, then cuts them off after timeout.
+func stopGRPC(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		server.Stop()
+	}
+}
+
+// fatal logs err and exits. Deferred cleanup does not run, so it is only
+// used before anything needs cleaning up; see fail in main.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// Mismatch describes a request or response that does not match the spec.
+// Status is zero for requests.
+type Mismatch struct {
+	Method string
+	Path   string
+	Status int
+	Err    error
+}
+
+func (m Mismatch) String() string {
+	if m.Status == 0 {
+		return fmt.Sprintf("request %s %s does not match the spec: %v", m.Method, m.Path, m.Err)
+	}
+	return fmt.Sprintf("response %d to %s %s does not match the spec: %v", m.Status, m.Method, m.Path, m.Err)
+}
+
+type ValidatorOptions struct {
+	// ValidateResponses checks JSON responses as well. Responses are still
+	// sent unchanged; mismatches are only reported. Meant for development.
+	ValidateResponses bool
+	// OnMismatch is called for every mismatch and defaults to a slog warning.
+	OnMismatch func(Mismatch)
+}
+
+// NewValidator returns a middleware that rejects requests not matching the
+// spec with 400. Authentication is left to the auth middleware, and routes
+// missing from the spec are passed through untouched.
+func NewValidator(doc *openapi3.T, opts ValidatorOptions) gin.HandlerFunc {
+	report := opts.OnMismatch
+	if report == nil {
+		report = func(m Mismatch) {
+			slog.Warn("openapi mismatch", "method", m.Method, "path", m.Path, "status", m.Status, "error", m.Err)
+		}
+	}
+
+	return func(c *gin.Context) {
+		route, ok := findRoute(doc, c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		filterOptions := &openapi3filter.Options{
+			AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams(c),
+			Route:      route,
+			Options:    filterOptions,
+		}
+
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			report(Mismatch{Method: c.Request.Method, Path: route.Path, Err: err})
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "request does not match the API specification",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		// Streams and upgrades have no JSON body worth checking.
+		if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "application/json") {
+			return
+		}
+
+		output := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.Status(),
+			Header:                 recorder.Header(),
+			Options:                filterOptions,
+		}
+		output.SetBodyBytes(recorder.body.Bytes())
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), output); err != nil {
+			report(Mismatch{Method: c.Request.Method, Path: route.Path, Status: recorder.Status(), Err: err})
+		}
+	}
+}
+
+func findRoute(doc *openapi3.T, c *gin.Context) (*routers.Route, bool) {
+	if c.FullPath() == "" {
+		return nil, false
+	}
+
+	path := SpecPath(c.FullPath())
+	item := doc.Paths.Value(path)
+	if item == nil {
+		return nil, false
+	}
+	operation := item.GetOperation(c.Request.Method)
+	if operation == nil {
+		return nil, false
+	}
+
+	return &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  item,
+		Method:    c.Request.Method,
+		Operation: operation,
+	}, true
+}
+
+func pathParams(c *gin.Context) map[string]string {
+	params := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = param.Value
+	}
+	return params
+}
+
+// responseRecorder passes writes through while keeping a copy of the body.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
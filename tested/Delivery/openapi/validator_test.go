@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newValidatedEngine(t *testing.T, validateResponses bool, loginResponse gin.H) (*gin.Engine, *[]Mismatch) {
+	gin.SetMode(gin.TestMode)
+	doc, err := Load()
+	require.NoError(t, err)
+
+	var mismatches []Mismatch
+	r := gin.New()
+	r.Use(NewValidator(doc, ValidatorOptions{
+		ValidateResponses: validateResponses,
+		OnMismatch:        func(m Mismatch) { mismatches = append(mismatches, m) },
+	}))
+	r.POST("/login", func(c *gin.Context) { c.JSON(http.StatusOK, loginResponse) })
+	r.GET("/tasks/:id", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"}) })
+	r.GET("/undocumented", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return r, &mismatches
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLoad_SpecIsValid(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	assert.NotNil(t, doc.Paths.Value("/tasks/{id}"))
+}
+
+func TestSpecPath(t *testing.T) {
+	assert.Equal(t, "/tasks/{id}/comments", SpecPath("/tasks/:id/comments"))
+	assert.Equal(t, "/files/{path}", SpecPath("/files/*path"))
+	assert.Equal(t, "/login", SpecPath("/login"))
+}
+
+func TestValidator_RejectsInvalidRequests(t *testing.T) {
+	r, mismatches := newValidatedEngine(t, false, gin.H{"token": "jwt"})
+
+	rec := serve(r, http.MethodPost, "/login", `{"username":"bob"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "password")
+	require.Len(t, *mismatches, 1)
+	assert.Equal(t, "/login", (*mismatches)[0].Path)
+	assert.Zero(t, (*mismatches)[0].Status)
+
+	rec = serve(r, http.MethodGet, "/tasks/abc", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestValidator_PassesValidAndUndocumentedRequests(t *testing.T) {
+	r, mismatches := newValidatedEngine(t, true, gin.H{"token": "jwt", "refresh_token": "refresh"})
+
+	rec := serve(r, http.MethodPost, "/login", `{"username":"bob","password":"secret123"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"token":"jwt","refresh_token":"refresh"}`, rec.Body.String())
+
+	rec = serve(r, http.MethodGet, "/tasks/7", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(r, http.MethodGet, "/undocumented", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, *mismatches)
+}
+
+func TestValidator_ReportsResponseMismatchesWithoutChangingThem(t *testing.T) {
+	r, mismatches := newValidatedEngine(t, true, gin.H{"jwt": 42})
+
+	rec := serve(r, http.MethodPost, "/login", `{"username":"bob","password":"secret123"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"jwt":42}`, rec.Body.String())
+	require.Len(t, *mismatches, 1)
+	assert.Equal(t, http.StatusOK, (*mismatches)[0].Status)
+	assert.Contains(t, (*mismatches)[0].String(), "token")
+}
+
+func TestValidator_SkipsResponsesOutsideDevMode(t *testing.T) {
+	r, mismatches := newValidatedEngine(t, false, gin.H{"jwt": 42})
+
+	rec := serve(r, http.MethodPost, "/login", `{"username":"bob","password":"secret123"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, *mismatches)
+}
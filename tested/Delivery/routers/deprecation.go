@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The unversioned paths became aliases of /v1 on legacyDeprecatedAt and will
+// be removed at legacySunsetAt.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+// deprecatedAlias marks responses with the Deprecation (RFC 9745) and Sunset
+// (RFC 8594) headers and links to the same path under successorPrefix.
+func deprecatedAlias(successorPrefix string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(legacyDeprecatedAt.Unix(), 10)
+	sunset := legacySunsetAt.Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunset)
+		c.Header("Link", "<"+successorPrefix+c.Request.URL.Path+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
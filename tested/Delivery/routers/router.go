@@ -2,51 +2,213 @@ package routers
 
 import (
 	"task-manager/Delivery/controllers"
+	"task-manager/Delivery/graph"
+	"task-manager/Delivery/openapi"
+	domain "task-manager/Domain"
 	infrastructure "task-manager/Infrastructure"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// RateLimits holds the limiter for each group of routes; nil leaves the group
+// unlimited. The /v1, /v2 and unversioned routes share one budget.
+type RateLimits struct {
+	// Login, Register and Refresh are keyed by client IP.
+	Login    gin.HandlerFunc
+	Register gin.HandlerFunc
+	Refresh  gin.HandlerFunc
+	// API runs before authentication, so floods of bad tokens are limited
+	// too. It is keyed by the token's user, or by client IP without a
+	// validly signed token.
+	API gin.HandlerFunc
+}
+
+func (l RateLimits) orUnlimited() RateLimits {
+	unlimited := func(c *gin.Context) { c.Next() }
+	for _, handler := range []*gin.HandlerFunc{&l.Login, &l.Register, &l.Refresh, &l.API} {
+		if *handler == nil {
+			*handler = unlimited
+		}
+	}
+	return l
+}
+
 type Router struct {
-	taskController *controllers.TaskController
-	userController *controllers.UserController
-	authMiddleware *infrastructure.AuthMiddleware
+	taskController      *controllers.TaskController
+	userController      *controllers.UserController
+	commentController   *controllers.CommentController
+	webhookController   *controllers.WebhookController
+	eventController     *controllers.EventController
+	wsController        *controllers.WebSocketController
+	taskControllerV2    *controllers.TaskControllerV2
+	userControllerV2    *controllers.UserControllerV2
+	commentControllerV2 *controllers.CommentControllerV2
+	healthController    *controllers.HealthController
+	jwksController      *controllers.JWKSController
+	roleController      *controllers.RoleController
+	policyController    *controllers.PolicyController
+	graphHandler        *graph.Handler
+	docsHandler         *openapi.Handler
+	specValidator       gin.HandlerFunc
+	requestLogger       gin.HandlerFunc
+	metrics             *infrastructure.Metrics
+	rateLimits          RateLimits
+	authMiddleware      *infrastructure.AuthMiddleware
 }
 
 func NewRouter(
 	taskController *controllers.TaskController,
 	userController *controllers.UserController,
+	commentController *controllers.CommentController,
+	webhookController *controllers.WebhookController,
+	eventController *controllers.EventController,
+	wsController *controllers.WebSocketController,
+	taskControllerV2 *controllers.TaskControllerV2,
+	userControllerV2 *controllers.UserControllerV2,
+	commentControllerV2 *controllers.CommentControllerV2,
+	healthController *controllers.HealthController,
+	jwksController *controllers.JWKSController,
+	roleController *controllers.RoleController,
+	policyController *controllers.PolicyController,
+	graphHandler *graph.Handler,
+	docsHandler *openapi.Handler,
+	specValidator gin.HandlerFunc,
+	requestLogger gin.HandlerFunc,
+	metrics *infrastructure.Metrics,
+	rateLimits RateLimits,
 	authMiddleware *infrastructure.AuthMiddleware,
 ) *Router {
 	return &Router{
-		taskController: taskController,
-		userController: userController,
-		authMiddleware: authMiddleware,
+		taskController:      taskController,
+		userController:      userController,
+		commentController:   commentController,
+		webhookController:   webhookController,
+		eventController:     eventController,
+		wsController:        wsController,
+		taskControllerV2:    taskControllerV2,
+		userControllerV2:    userControllerV2,
+		commentControllerV2: commentControllerV2,
+		healthController:    healthController,
+		jwksController:      jwksController,
+		roleController:      roleController,
+		policyController:    policyController,
+		graphHandler:        graphHandler,
+		docsHandler:         docsHandler,
+		specValidator:       specValidator,
+		requestLogger:       requestLogger,
+		metrics:             metrics,
+		rateLimits:          rateLimits.orUnlimited(),
+		authMiddleware:      authMiddleware,
 	}
 }
 
 func (r *Router) SetupRoutes() *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	// Tracing comes first so the request ID and log lines can carry the
+	// trace ID. Without a configured provider the spans are no-ops.
+	router.Use(otelgin.Middleware(infrastructure.ServiceName))
+	// requestLogger is optional so tests can run quietly; it goes first so
+	// every later middleware sees the request ID.
+	if r.requestLogger != nil {
+		router.Use(r.requestLogger)
+	}
+	router.Use(r.metrics.Middleware(), infrastructure.Recovery())
+	// specValidator is optional; nil disables OpenAPI validation.
+	if r.specValidator != nil {
+		router.Use(r.specValidator)
+	}
+
+	router.GET("/healthz", r.healthController.Liveness)
+	router.GET("/readyz", r.healthController.Readiness)
+	router.GET("/metrics", gin.WrapH(r.metrics.Handler()))
+	router.GET("/.well-known/jwks.json", r.jwksController.JWKS)
+	router.GET("/openapi.json", r.docsHandler.Spec)
+	router.GET("/docs", r.docsHandler.Docs)
+
+	r.registerV1(router.Group("/v1"))
+	r.registerV1(router.Group("", deprecatedAlias("/v1")))
+	r.registerV2(router.Group("/v2"))
+
+	return router
+}
 
-	router.POST("/register", r.userController.Register)
-	router.POST("/login", r.userController.Login)
+// registerV1 mounts the original API. It is used for both /v1 and the
+// deprecated unversioned aliases, so the two can never drift apart.
+func (r *Router) registerV1(api *gin.RouterGroup) {
+	api.POST("/register", r.rateLimits.Register, r.userController.Register)
+	api.POST("/login", r.rateLimits.Login, r.userController.Login)
+	api.POST("/token/refresh", r.rateLimits.Refresh, r.userController.RefreshToken)
+	api.POST("/logout", r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware(), r.userController.Logout)
+	api.GET("/ws", r.rateLimits.API, r.authMiddleware.WebSocketAuthMiddleware(), r.wsController.Connect)
+	api.POST("/graphql", r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware(), r.graphHandler.Serve)
 
-	tasks := router.Group("/tasks")
-	tasks.Use(r.authMiddleware.JWTAuthMiddleware())
+	tasks := api.Group("/tasks")
+	tasks.Use(r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware())
 	{
 		tasks.GET("", r.taskController.GetTasks)
+		tasks.GET("/events", r.eventController.StreamTaskEvents)
 		tasks.GET("/:id", r.taskController.GetTaskByID)
+		tasks.GET("/:id/comments", r.commentController.GetComments)
+		tasks.POST("/:id/comments", r.commentController.AddComment)
 	}
 
-	admin := router.Group("/")
-	admin.Use(r.authMiddleware.JWTAuthMiddleware())
-	admin.Use(r.authMiddleware.AdminOnly())
+	can := r.authMiddleware.RequirePermission
+	protected := api.Group("")
+	protected.Use(r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware())
 	{
-		admin.POST("/tasks", r.taskController.CreateTask)
-		admin.PUT("/tasks/:id", r.taskController.UpdateTask)
-		admin.DELETE("/tasks/:id", r.taskController.DeleteTask)
-		admin.POST("/promote/:username", r.userController.PromoteUser)
+		protected.POST("/tasks", can(domain.PermissionTaskCreate), r.taskController.CreateTask)
+		protected.PUT("/tasks/:id", can(domain.PermissionTaskUpdate), r.taskController.UpdateTask)
+		protected.DELETE("/tasks/:id", can(domain.PermissionTaskDelete), r.taskController.DeleteTask)
+		protected.POST("/promote/:username", can(domain.PermissionUserPromote), r.userController.PromoteUser)
+		protected.POST("/unlock/:username", can(domain.PermissionUserUnlock), r.userController.UnlockUser)
+		protected.PUT("/users/:username/role", can(domain.PermissionUserPromote), r.roleController.AssignRole)
+		protected.GET("/users", can(domain.PermissionUserList), r.userController.ListUsers)
+		protected.GET("/users/:username", can(domain.PermissionUserList), r.userController.GetUser)
+		protected.POST("/users/:username/demote", can(domain.PermissionUserPromote), r.userController.DemoteUser)
+		protected.POST("/users/:username/disable", can(domain.PermissionUserManage), r.userController.DisableUser)
+		protected.POST("/users/:username/enable", can(domain.PermissionUserManage), r.userController.EnableUser)
+		protected.DELETE("/users/:username", can(domain.PermissionUserManage), r.userController.DeleteUser)
+
+		protected.GET("/permissions", can(domain.PermissionRoleManage), r.roleController.GetPermissions)
+		protected.GET("/roles", can(domain.PermissionRoleManage), r.roleController.GetRoles)
+		protected.POST("/roles", can(domain.PermissionRoleManage), r.roleController.CreateRole)
+		protected.PUT("/roles/:name", can(domain.PermissionRoleManage), r.roleController.UpdateRole)
+		protected.DELETE("/roles/:name", can(domain.PermissionRoleManage), r.roleController.DeleteRole)
+		protected.POST("/policies/explain", can(domain.PermissionPolicyExplain), r.policyController.Explain)
+
+		webhooks := protected.Group("/webhooks", can(domain.PermissionWebhookManage))
+		webhooks.GET("", r.webhookController.GetWebhooks)
+		webhooks.POST("", r.webhookController.CreateWebhook)
+		webhooks.DELETE("/:id", r.webhookController.DeleteWebhook)
+		webhooks.GET("/dead-letters", r.webhookController.GetDeadLetters)
+		webhooks.POST("/dead-letters/:id/redeliver", r.webhookController.RedeliverDeadLetter)
 	}
+}
 
-	return router
+// registerV2 mounts the v2 API, which shares the v1 use cases but has its
+// own response shapes. Routes not ported yet are only available under /v1.
+func (r *Router) registerV2(api *gin.RouterGroup) {
+	api.POST("/register", r.rateLimits.Register, r.userControllerV2.Register)
+	api.POST("/login", r.rateLimits.Login, r.userControllerV2.Login)
+	api.POST("/token/refresh", r.rateLimits.Refresh, r.userControllerV2.RefreshToken)
+	api.POST("/logout", r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware(), r.userControllerV2.Logout)
+
+	tasks := api.Group("/tasks")
+	tasks.Use(r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware())
+	{
+		tasks.GET("", r.taskControllerV2.GetTasks)
+		tasks.GET("/:id", r.taskControllerV2.GetTask)
+		tasks.GET("/:id/comments", r.commentControllerV2.GetComments)
+		tasks.POST("/:id/comments", r.commentControllerV2.AddComment)
+	}
+
+	can := r.authMiddleware.RequirePermission
+	protected := api.Group("")
+	protected.Use(r.rateLimits.API, r.authMiddleware.JWTAuthMiddleware())
+	{
+		protected.POST("/tasks", can(domain.PermissionTaskCreate), r.taskControllerV2.CreateTask)
+		protected.PUT("/tasks/:id", can(domain.PermissionTaskUpdate), r.taskControllerV2.UpdateTask)
+		protected.DELETE("/tasks/:id", can(domain.PermissionTaskDelete), r.taskControllerV2.DeleteTask)
+	}
 }
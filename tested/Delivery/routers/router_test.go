@@ -0,0 +1,200 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/Delivery/controllers"
+	"task-manager/Delivery/graph"
+	"task-manager/Delivery/openapi"
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestEngine wires the real routes around controllers without use cases;
+// the tests here only exercise routing, never the handlers' use cases.
+func newTestEngine() *gin.Engine {
+	return newTestEngineWith(RateLimits{})
+}
+
+func newTestEngineWith(rateLimits RateLimits) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	broker := infrastructure.NewTaskEventBroker(10)
+	router := NewRouter(
+		controllers.NewTaskController(nil),
+		controllers.NewUserController(nil),
+		controllers.NewCommentController(nil),
+		controllers.NewWebhookController(nil),
+		controllers.NewEventController(broker, nil, time.Second),
+		controllers.NewWebSocketController(nil, broker, nil, time.Second),
+		controllers.NewTaskControllerV2(nil),
+		controllers.NewUserControllerV2(nil),
+		controllers.NewCommentControllerV2(nil),
+		controllers.NewHealthController(time.Second),
+		controllers.NewJWKSController(nil),
+		controllers.NewRoleController(nil),
+		controllers.NewPolicyController(nil, nil),
+		graph.NewHandler(graphql.Schema{}, graph.Limits{}),
+		openapi.NewHandler(),
+		nil,
+		nil,
+		infrastructure.NewMetrics(),
+		rateLimits,
+		infrastructure.NewAuthMiddleware(infrastructure.NewJWTService([]byte("test-secret"), time.Hour), infrastructure.NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, noUsers{}),
+	)
+	return router.SetupRoutes()
+}
+
+// noUsers knows no users, so every token is rejected after its signature.
+type noUsers struct{}
+
+func (noUsers) GetUserByUsername(context.Context, string) (domain.User, error) {
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func TestSetupRoutes_EveryRouteIsInTheSpec(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	routed := map[string]bool{}
+	for _, route := range newTestEngine().Routes() {
+		path := openapi.SpecPath(route.Path)
+		routed[route.Method+" "+path] = true
+
+		item := doc.Paths.Value(path)
+		if assert.NotNil(t, item, "%s is not in the spec", path) {
+			assert.NotNil(t, item.GetOperation(route.Method), "%s %s is not in the spec", route.Method, path)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, routed[method+" "+path], "%s %s is in the spec but not routed", method, path)
+		}
+	}
+}
+
+func TestSetupRoutes_ServesSpecAndDocs(t *testing.T) {
+	engine := newTestEngine()
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html"))
+	assert.Contains(t, rec.Body.String(), "/openapi.json")
+}
+
+func TestSetupRoutes_UnversionedPathsAreDeprecatedAliases(t *testing.T) {
+	engine := newTestEngine()
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/7", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "@1792368000", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/tasks/7>; rel="successor-version"`, rec.Header().Get("Link"))
+
+	for _, path := range []string{"/v1/tasks/7", "/v2/tasks/7"} {
+		rec = httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+		assert.Empty(t, rec.Header().Get("Deprecation"), path)
+		assert.Empty(t, rec.Header().Get("Sunset"), path)
+	}
+}
+
+func TestSetupRoutes_ContinuesIncomingTraces(t *testing.T) {
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tasks/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	newTestEngine().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "/v1/tasks/:id", spans[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+}
+
+func TestSetupRoutes_VersionsShareOneLoginBudget(t *testing.T) {
+	limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), nil)
+	engine := newTestEngineWith(RateLimits{
+		Login:   limiter.Limit(infrastructure.RateLimitPolicy{Name: "login", Requests: 1, Per: time.Minute, Burst: 2}),
+		Refresh: limiter.Limit(infrastructure.RateLimitPolicy{Name: "refresh", Requests: 1, Per: time.Minute, Burst: 1}),
+	})
+
+	codes := []int{}
+	for _, path := range []string{"/login", "/v1/login", "/v2/login"} {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader("{")))
+		codes = append(codes, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusTooManyRequests}, codes)
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/register", strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "register has its own budget")
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/token/refresh", strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "refresh has its own budget")
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/token/refresh", strings.NewReader("{")))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestSetupRoutes_LimitsInvalidTokensBeforeAuthentication(t *testing.T) {
+	jwtService := infrastructure.NewJWTService([]byte("test-secret"), time.Hour)
+	auth := infrastructure.NewAuthMiddleware(jwtService, infrastructure.NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, nil)
+	limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), auth.Caller)
+	engine := newTestEngineWith(RateLimits{
+		API: limiter.Limit(infrastructure.RateLimitPolicy{Name: "api", Requests: 1, Per: time.Minute, Burst: 1}),
+	})
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/tasks", nil)
+		req.Header.Set("Authorization", "token "+token)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("garbage"))
+	assert.Equal(t, http.StatusTooManyRequests, get("more-garbage"), "bad tokens share the client IP's budget")
+
+	// A validly signed token draws from its user's budget instead, even
+	// though this engine then rejects the unknown user.
+	token, err := jwtService.GenerateToken(domain.User{UserName: "bob"}, "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, get(token))
+	assert.Equal(t, http.StatusTooManyRequests, get(token))
+}
@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// access is what an RPC requires of its caller: nothing, a valid token, or
+// a valid token whose role holds permission.
+type access struct {
+	public     bool
+	permission domain.Permission
+}
+
+var (
+	accessPublic        = access{public: true}
+	accessAuthenticated = access{}
+)
+
+func requires(permission domain.Permission) access {
+	return access{permission: permission}
+}
+
+// methodAccess lists the access level of every RPC. Methods missing from the
+// map are rejected so that new RPCs cannot be exposed by accident.
+var methodAccess = map[string]access{
+	"/taskmanager.v1.TaskService/GetAllTasks": accessAuthenticated,
+	"/taskmanager.v1.TaskService/GetTask":     accessAuthenticated,
+	"/taskmanager.v1.TaskService/CreateTask":  requires(domain.PermissionTaskCreate),
+	"/taskmanager.v1.TaskService/UpdateTask":  requires(domain.PermissionTaskUpdate),
+	"/taskmanager.v1.TaskService/DeleteTask":  requires(domain.PermissionTaskDelete),
+
+	"/taskmanager.v1.UserService/RegisterUser": accessPublic,
+	"/taskmanager.v1.UserService/Login":        accessPublic,
+	"/taskmanager.v1.UserService/RefreshToken": accessPublic,
+	"/taskmanager.v1.UserService/Logout":       accessAuthenticated,
+	"/taskmanager.v1.UserService/GetUser":      accessAuthenticated,
+	"/taskmanager.v1.UserService/GetAllUsers":  requires(domain.PermissionUserList),
+	"/taskmanager.v1.UserService/PromoteUser":  requires(domain.PermissionUserPromote),
+}
+
+type callerKey struct{}
+
+type caller struct {
+	username string
+	role     domain.Role
+}
+
+func callerFrom(ctx context.Context) caller {
+	c, _ := ctx.Value(callerKey{}).(caller)
+	return c
+}
+
+// Authenticator checks a token exactly as the HTTP API does; it is
+// implemented by *infrastructure.AuthMiddleware.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*infrastructure.Claims, domain.Role, error)
+}
+
+// AuthInterceptor validates the "authorization: token <jwt>" metadata with
+// the same checks as the HTTP middleware.
+func AuthInterceptor(auth Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		access, known := methodAccess[info.FullMethod]
+		if !known {
+			return nil, status.Error(codes.PermissionDenied, "method is not exposed")
+		}
+		if access.public {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || !strings.HasPrefix(values[0], "token ") {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+		}
+
+		claims, role, err := auth.Authenticate(ctx, strings.TrimPrefix(values[0], "token "))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if access.permission != "" && !role.Can(access.permission) {
+			return nil, status.Error(codes.PermissionDenied, "missing permission "+string(access.permission))
+		}
+
+		ctx = infrastructure.WithClaims(ctx, claims)
+		return handler(context.WithValue(ctx, callerKey{}, caller{username: claims.Username, role: role}), req)
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTaskUseCase mocks domain.TaskUseCase
+type MockTaskUseCase struct{ mock.Mock }
+
+func (m *MockTaskUseCase) GetAllTasks(_ context.Context) ([]domain.Task, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) GetTaskByID(_ context.Context, userID int) (domain.Task, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) CreateTask(_ context.Context, task domain.Task) (domain.Task, error) {
+	args := m.Called(task)
+	return args.Get(0).(domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) UpdateTask(_ context.Context, userID int, task domain.Task) (domain.Task, error) {
+	args := m.Called(userID, task)
+	return args.Get(0).(domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) DeleteTask(_ context.Context, userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// MockUserUseCase mocks the domain.UserUseCase methods the gRPC server calls; the
+// embedded nil interface panics if anything else is called.
+type MockUserUseCase struct {
+	mock.Mock
+	domain.UserUseCase
+}
+
+func (m *MockUserUseCase) RegisterUser(_ context.Context, username, password, email string) error {
+	args := m.Called(username, password, email)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) LoginUser(_ context.Context, username, password, ip string) (domain.TokenPair, error) {
+	args := m.Called(username, password, ip)
+	return args.Get(0).(domain.TokenPair), args.Error(1)
+}
+
+func (m *MockUserUseCase) RefreshToken(_ context.Context, refreshToken string) (domain.TokenPair, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(domain.TokenPair), args.Error(1)
+}
+
+func (m *MockUserUseCase) Logout(_ context.Context, tokenID, sessionID string, expiresAt time.Time) error {
+	args := m.Called(tokenID, sessionID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) GetUser(_ context.Context, username string) (domain.User, error) {
+	args := m.Called(username)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) GetAllUsers(_ context.Context) ([]domain.User, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) PromoteUser(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+var _ domain.TaskUseCase = (*MockTaskUseCase)(nil)
+var _ domain.UserUseCase = (*MockUserUseCase)(nil)
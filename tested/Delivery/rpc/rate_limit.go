@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	infrastructure "task-manager/Infrastructure"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimits maps full method names to the policy limiting them. Methods
+// without one are not limited.
+type RateLimits map[string]infrastructure.RateLimitPolicy
+
+// RateLimitInterceptor charges each limited call to the peer address's
+// bucket, which is the one the HTTP API uses for anonymous requests from the
+// same address. Rejected calls get ResourceExhausted with a retry-after
+// header in seconds.
+func RateLimitInterceptor(limiter *infrastructure.RateLimiter, limits RateLimits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		policy, limited := limits[info.FullMethod]
+		if !limited {
+			return handler(ctx, req)
+		}
+
+		result, err := limiter.TakeIP(ctx, policy, peerIP(ctx))
+		if err != nil {
+			// Fail open, as the HTTP limiter does.
+			infrastructure.LoggerFrom(ctx).Error("rate limit store failed", "error", err, "policy", policy.Name)
+			return handler(ctx, req)
+		}
+		if !result.Allowed {
+			retryAfter := strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
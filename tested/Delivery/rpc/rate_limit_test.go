@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-manager/Delivery/rpc/pb"
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitInterceptor_LimitsListedMethodsPerPeer(t *testing.T) {
+	users := new(MockUserUseCase)
+	users.On("LoginUser", "bob", "wrong", mock.Anything).Return(domain.TokenPair{}, nil)
+	users.On("RegisterUser", "bob", "secret123", "").Return(nil)
+	limiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), nil)
+	login := infrastructure.RateLimitPolicy{Name: "login", Requests: 1, Per: time.Hour, Burst: 2}
+	client := pb.NewUserServiceClient(startServer(t, new(MockTaskUseCase), users,
+		RateLimitInterceptor(limiter, RateLimits{"/taskmanager.v1.UserService/Login": login})))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Login(ctx, &pb.LoginRequest{Username: "bob", Password: "wrong"})
+		require.NoError(t, err)
+	}
+	var header metadata.MD
+	_, err := client.Login(ctx, &pb.LoginRequest{Username: "bob", Password: "wrong"}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"3600"}, header.Get("retry-after"))
+	users.AssertNumberOfCalls(t, "LoginUser", 2)
+
+	_, err = client.RegisterUser(ctx, &pb.RegisterUserRequest{Username: "bob", Password: "secret123"})
+	assert.NoError(t, err, "methods without a policy are not limited")
+}
@@ -0,0 +1,232 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"task-manager/Delivery/rpc/pb"
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewServer returns a gRPC server exposing TaskService and UserService.
+// interceptors run in order before authentication.
+func NewServer(
+	taskUseCase domain.TaskUseCase,
+	userUseCase domain.UserUseCase,
+	auth Authenticator,
+	interceptors ...grpc.UnaryServerInterceptor,
+) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(append(interceptors, AuthInterceptor(auth))...))
+	pb.RegisterTaskServiceServer(server, &taskServer{taskUseCase: taskUseCase})
+	pb.RegisterUserServiceServer(server, &userServer{userUseCase: userUseCase})
+	return server
+}
+
+type taskServer struct {
+	pb.UnimplementedTaskServiceServer
+	taskUseCase domain.TaskUseCase
+}
+
+func (s *taskServer) GetAllTasks(ctx context.Context, req *pb.GetAllTasksRequest) (*pb.GetAllTasksResponse, error) {
+	tasks, err := s.taskUseCase.GetAllTasks(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.GetAllTasksResponse{}
+	for _, task := range tasks {
+		resp.Tasks = append(resp.Tasks, taskToProto(task))
+	}
+	return resp, nil
+}
+
+func (s *taskServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	task, err := s.taskUseCase.GetTaskByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskToProto(task), nil
+}
+
+func (s *taskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.CreateTaskResponse, error) {
+	task, err := s.taskUseCase.CreateTask(ctx, taskFromProto(req.GetTask()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateTaskResponse{Task: taskToProto(task)}, nil
+}
+
+func (s *taskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.Task, error) {
+	task, err := s.taskUseCase.UpdateTask(ctx, int(req.GetId()), taskFromProto(req.GetTask()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	task.UserID = int(req.GetId())
+	return taskToProto(task), nil
+}
+
+func (s *taskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
+	if err := s.taskUseCase.DeleteTask(ctx, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteTaskResponse{}, nil
+}
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	userUseCase domain.UserUseCase
+}
+
+func (s *userServer) RegisterUser(ctx context.Context, req *pb.RegisterUserRequest) (*pb.RegisterUserResponse, error) {
+	if err := s.userUseCase.RegisterUser(ctx, req.GetUsername(), req.GetPassword(), req.GetEmail()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RegisterUserResponse{}, nil
+}
+
+func (s *userServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	tokens, err := s.userUseCase.LoginUser(ctx, req.GetUsername(), req.GetPassword(), peerIP(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.LoginResponse{Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+func (s *userServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.LoginResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+	tokens, err := s.userUseCase.RefreshToken(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.LoginResponse{Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+func (s *userServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	claims, ok := infrastructure.ClaimsFrom(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	if err := s.userUseCase.Logout(ctx, claims.ID, claims.SessionID, claims.ExpiresAt.Time); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	c := callerFrom(ctx)
+	if req.GetUsername() != c.username && !c.role.Can(domain.PermissionUserList) {
+		return nil, status.Error(codes.PermissionDenied, "missing permission "+string(domain.PermissionUserList))
+	}
+
+	user, err := s.userUseCase.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return userToProto(user), nil
+}
+
+func (s *userServer) GetAllUsers(ctx context.Context, req *pb.GetAllUsersRequest) (*pb.GetAllUsersResponse, error) {
+	users, err := s.userUseCase.GetAllUsers(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.GetAllUsersResponse{}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userToProto(user))
+	}
+	return resp, nil
+}
+
+func (s *userServer) PromoteUser(ctx context.Context, req *pb.PromoteUserRequest) (*pb.PromoteUserResponse, error) {
+	if err := s.userUseCase.PromoteUser(ctx, req.GetUsername()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.PromoteUserResponse{}, nil
+}
+
+// toStatus maps domain errors onto gRPC status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTaskTitle),
+		errors.Is(err, domain.ErrInvalidTaskDescription),
+		errors.Is(err, domain.ErrInvalidEmail),
+		errors.Is(err, domain.ErrPasswordTooShort):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrTaskNotFound), errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrUsernameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrTaskForbidden), errors.Is(err, domain.ErrUserDisabled), errors.Is(err, domain.ErrRoleEscalation):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrInvalidRefreshToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrTooManyLoginAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// peerIP is the caller's address without the port, matching what the HTTP
+// API keys lockouts on.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func taskToProto(task domain.Task) *pb.Task {
+	msg := &pb.Task{
+		Id:          int64(task.UserID),
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Assignee:    task.Assignee,
+		Project:     task.Project,
+	}
+	if !task.DueDate.IsZero() {
+		msg.DueDate = timestamppb.New(task.DueDate)
+	}
+	return msg
+}
+
+func taskFromProto(msg *pb.Task) domain.Task {
+	task := domain.Task{
+		Title:       msg.GetTitle(),
+		Description: msg.GetDescription(),
+		Status:      msg.GetStatus(),
+		Assignee:    msg.GetAssignee(),
+		Project:     msg.GetProject(),
+	}
+	if msg.GetDueDate() != nil {
+		task.DueDate = msg.GetDueDate().AsTime()
+	}
+	return task
+}
+
+func userToProto(user domain.User) *pb.User {
+	return &pb.User{
+		Id:       user.ID.Hex(),
+		Username: user.UserName,
+		Role:     user.Role,
+		Email:    user.Email,
+		Disabled: user.Disabled,
+	}
+}
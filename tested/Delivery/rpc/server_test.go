@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"task-manager/Delivery/rpc/pb"
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// disabledUsers resolves every username, as a disabled user if listed.
+type disabledUsers map[string]bool
+
+func (d disabledUsers) GetUserByUsername(_ context.Context, username string) (domain.User, error) {
+	return domain.User{UserName: username, Disabled: d[username]}, nil
+}
+
+func startServer(t *testing.T, tasks *MockTaskUseCase, users *MockUserUseCase, interceptors ...grpc.UnaryServerInterceptor) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	auth := infrastructure.NewAuthMiddleware(infrastructure.NewJWTService([]byte("test-secret"), time.Hour), infrastructure.NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, disabledUsers{"mallory": true})
+	server := NewServer(tasks, users, auth, interceptors...)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withToken(t *testing.T, username, role string) context.Context {
+	token, err := infrastructure.NewJWTService([]byte("test-secret"), time.Hour).GenerateToken(domain.User{UserName: username, Role: role}, "session-1")
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "token "+token)
+}
+
+func TestTaskService_RequiresToken(t *testing.T) {
+	client := pb.NewTaskServiceClient(startServer(t, new(MockTaskUseCase), new(MockUserUseCase)))
+
+	_, err := client.GetAllTasks(context.Background(), &pb.GetAllTasksRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestTaskService_GetTask(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	tasks.On("GetTaskByID", 3).Return(domain.Task{UserID: 3, Title: "Write docs", Project: "api"}, nil)
+	tasks.On("GetTaskByID", 9).Return(domain.Task{}, domain.ErrTaskNotFound)
+	client := pb.NewTaskServiceClient(startServer(t, tasks, new(MockUserUseCase)))
+	ctx := withToken(t, "bob", "User")
+
+	task, err := client.GetTask(ctx, &pb.GetTaskRequest{Id: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), task.GetId())
+	assert.Equal(t, "Write docs", task.GetTitle())
+	assert.Equal(t, "api", task.GetProject())
+	assert.Nil(t, task.GetDueDate())
+
+	_, err = client.GetTask(ctx, &pb.GetTaskRequest{Id: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestTaskService_CreateTaskIsAdminOnly(t *testing.T) {
+	tasks := new(MockTaskUseCase)
+	tasks.On("CreateTask", domain.Task{Title: ""}).Return(domain.Task{}, domain.ErrInvalidTaskTitle)
+	tasks.On("CreateTask", mock.Anything).Return(domain.Task{UserID: 1, Title: "Ship", Description: "it"}, nil)
+	client := pb.NewTaskServiceClient(startServer(t, tasks, new(MockUserUseCase)))
+
+	_, err := client.CreateTask(withToken(t, "bob", "User"), &pb.CreateTaskRequest{Task: &pb.Task{Title: "x"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	tasks.AssertNotCalled(t, "CreateTask", mock.Anything)
+
+	admin := withToken(t, "root", "Admin")
+	_, err = client.CreateTask(admin, &pb.CreateTaskRequest{Task: &pb.Task{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	created, err := client.CreateTask(admin, &pb.CreateTaskRequest{Task: &pb.Task{Title: "Ship", Description: "it"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created.GetTask().GetId())
+	assert.Equal(t, "Ship", created.GetTask().GetTitle())
+}
+
+func TestUserService_RegisterAndLogin(t *testing.T) {
+	users := new(MockUserUseCase)
+	users.On("RegisterUser", "bob", "secret123", "bob@example.com").Return(nil)
+	users.On("RegisterUser", "alice", "secret123", "").Return(domain.ErrUsernameTaken)
+	users.On("LoginUser", "bob", "secret123", mock.Anything).Return(domain.TokenPair{AccessToken: "jwt", RefreshToken: "refresh"}, nil)
+	users.On("LoginUser", "bob", "wrong", mock.Anything).Return(domain.TokenPair{}, domain.ErrInvalidCredentials)
+	users.On("LoginUser", "eve", "guess", mock.Anything).Return(domain.TokenPair{}, domain.ErrTooManyLoginAttempts)
+	users.On("LoginUser", "mallory", "secret123", mock.Anything).Return(domain.TokenPair{}, domain.ErrUserDisabled)
+	client := pb.NewUserServiceClient(startServer(t, new(MockTaskUseCase), users))
+	ctx := context.Background()
+
+	_, err := client.RegisterUser(ctx, &pb.RegisterUserRequest{Username: "bob", Password: "secret123", Email: "bob@example.com"})
+	assert.NoError(t, err)
+
+	_, err = client.RegisterUser(ctx, &pb.RegisterUserRequest{Username: "alice", Password: "secret123"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	resp, err := client.Login(ctx, &pb.LoginRequest{Username: "bob", Password: "secret123"})
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", resp.GetToken())
+	assert.Equal(t, "refresh", resp.GetRefreshToken())
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Username: "bob", Password: "wrong"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Username: "eve", Password: "guess"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Username: "mallory", Password: "secret123"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestUserService_RefreshTokenAndLogout(t *testing.T) {
+	users := new(MockUserUseCase)
+	users.On("RefreshToken", "refresh").Return(domain.TokenPair{AccessToken: "jwt-2", RefreshToken: "refresh-2"}, nil)
+	users.On("RefreshToken", "reused").Return(domain.TokenPair{}, domain.ErrInvalidRefreshToken)
+	users.On("Logout", mock.Anything, "session-1", mock.Anything).Return(nil).Once()
+	client := pb.NewUserServiceClient(startServer(t, new(MockTaskUseCase), users))
+	ctx := context.Background()
+
+	resp, err := client.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: "refresh"})
+	require.NoError(t, err)
+	assert.Equal(t, "jwt-2", resp.GetToken())
+	assert.Equal(t, "refresh-2", resp.GetRefreshToken())
+
+	_, err = client.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: "reused"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.RefreshToken(ctx, &pb.RefreshTokenRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Logout(ctx, &pb.LogoutRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.Logout(withToken(t, "bob", "User"), &pb.LogoutRequest{})
+	assert.NoError(t, err)
+	users.AssertExpectations(t)
+}
+
+func TestAuthInterceptor_RejectsDisabledUsers(t *testing.T) {
+	client := pb.NewTaskServiceClient(startServer(t, new(MockTaskUseCase), new(MockUserUseCase)))
+
+	_, err := client.GetAllTasks(withToken(t, "mallory", domain.RoleAdmin), &pb.GetAllTasksRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "user is disabled", status.Convert(err).Message())
+}
+
+func TestUserService_GetUserIsSelfOrAdmin(t *testing.T) {
+	users := new(MockUserUseCase)
+	users.On("GetUser", "bob").Return(domain.User{UserName: "bob", Role: "User", Disabled: true}, nil)
+	client := pb.NewUserServiceClient(startServer(t, new(MockTaskUseCase), users))
+
+	user, err := client.GetUser(withToken(t, "bob", "User"), &pb.GetUserRequest{Username: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.GetUsername())
+	assert.True(t, user.GetDisabled())
+
+	_, err = client.GetUser(withToken(t, "alice", "User"), &pb.GetUserRequest{Username: "bob"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetUser(withToken(t, "root", "Admin"), &pb.GetUserRequest{Username: "bob"})
+	assert.NoError(t, err)
+}
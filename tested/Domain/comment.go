@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidCommentBody = errors.New("comment body cannot be empty")
+
+type Comment struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID    int                `bson:"task_id" json:"task_id"`
+	Author    string             `bson:"author" json:"author"`
+	Body      string             `bson:"body" json:"body"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func (c Comment) Validate() error {
+	if strings.TrimSpace(c.Body) == "" {
+		return ErrInvalidCommentBody
+	}
+	return nil
+}
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment Comment) (Comment, error)
+	GetCommentsByTaskID(ctx context.Context, taskID int) ([]Comment, error)
+}
+
+type CommentUseCase interface {
+	AddComment(ctx context.Context, taskID int, author, body string) (Comment, error)
+	GetComments(ctx context.Context, taskID int) ([]Comment, error)
+}
@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"context"
 	"errors"
+	"net/mail"
 	"strings"
 	"time"
 
@@ -13,6 +15,14 @@ var (
 	ErrInvalidTaskDescription = errors.New("task description cannot be empty")
 	ErrInvalidCredentials     = errors.New("invalid credentials")
 	ErrUserNotFound           = errors.New("user not found")
+	ErrInvalidEmail           = errors.New("invalid email address")
+	ErrTaskNotFound           = errors.New("task not found")
+	ErrUsernameTaken          = errors.New("username is taken")
+	ErrPasswordTooShort       = errors.New("password must be at least 8 characters")
+	ErrTaskForbidden          = errors.New("not allowed by task policy")
+	ErrUserDisabled           = errors.New("user is disabled")
+	ErrLastAdmin              = errors.New("cannot remove the last enabled admin")
+	ErrBootstrapUserExists    = errors.New("bootstrap admin username is taken by an account with another password")
 )
 
 type Task struct {
@@ -22,6 +32,9 @@ type Task struct {
 	Description string             `bson:"description" json:"description"`
 	DueDate     time.Time          `bson:"due_date" json:"due_date"`
 	Status      string             `bson:"status" json:"status"`
+	Assignee    string             `bson:"assignee,omitempty" json:"assignee,omitempty"`
+	Project     string             `bson:"project,omitempty" json:"project,omitempty"`
+	CreatedBy   string             `bson:"created_by,omitempty" json:"created_by,omitempty"`
 }
 
 func (t Task) Validate() error {
@@ -34,41 +47,141 @@ func (t Task) Validate() error {
 	return nil
 }
 
+// User.TokenVersion is copied into every access token and goes up whenever
+// the role or disabled flag changes, which rejects the tokens issued before.
+// Passwords cannot be changed yet; the update that changes one must raise it
+// too, with the repository's bumpTokenVersion.
 type User struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserName string             `bson:"user_name" json:"user_name"`
-	Password string             `bson:"password,omitempty" json:"_"`
-	Role     string             `bson:"role" json:"role"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserName     string             `bson:"user_name" json:"user_name"`
+	Password     string             `bson:"password,omitempty" json:"-"`
+	Role         string             `bson:"role" json:"role"`
+	Email        string             `bson:"email,omitempty" json:"email,omitempty"`
+	Disabled     bool               `bson:"disabled,omitempty" json:"disabled"`
+	TokenVersion int64              `bson:"token_version,omitempty" json:"-"`
 }
 
 func (u User) IsAdmin() bool {
-	return u.Role == "Admin"
+	return u.Role == RoleAdmin
+}
+
+// ValidateEmail accepts an empty address since email is optional, but
+// rejects anything that is not a bare address.
+func ValidateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+// Page sizes for ListUsers. A UserQuery without a limit gets the default.
+const (
+	DefaultUserPageSize = 20
+	MaxUserPageSize     = 100
+)
+
+// UserQuery selects a page of users, sorted by username. Search matches
+// usernames containing it, ignoring case.
+type UserQuery struct {
+	Search string
+	Offset int
+	Limit  int
+}
+
+type UserPage struct {
+	Users  []User `json:"users"`
+	Total  int64  `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
 }
 
 type TaskRepository interface {
-	GetAllTasks() ([]Task, error)
-	GetTaskByID(userID int) (Task, error)
-	CreateTask(task Task) error
-	UpdateTask(userID int, task Task) (Task, error)
-	DeleteTask(userID int) error
+	GetAllTasks(ctx context.Context) ([]Task, error)
+	GetTaskByID(ctx context.Context, userID int) (Task, error)
+	CreateTask(ctx context.Context, task Task) (Task, error)
+	UpdateTask(ctx context.Context, userID int, task Task) (Task, error)
+	DeleteTask(ctx context.Context, userID int) error
 }
 
 type UserRepository interface {
-	RegisterUser(username, password string) error
-	AuthenticateUser(username, password string) (User, error)
-	PromoteUser(username string) error
+	RegisterUser(ctx context.Context, username, password, email string) error
+	AuthenticateUser(ctx context.Context, username, password string) (User, error)
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetAllUsers(ctx context.Context) ([]User, error)
+	PromoteUser(ctx context.Context, username string) error
+	SetUserRole(ctx context.Context, username, role string) error
+	CountUsersWithRole(ctx context.Context, role string) (int64, error)
+	// CountEnabledUsersWithRole is CountUsersWithRole without disabled users.
+	CountEnabledUsersWithRole(ctx context.Context, role string) (int64, error)
+	// ListUsers returns the page selected by query, without passwords, and
+	// how many users match it in total.
+	ListUsers(ctx context.Context, query UserQuery) ([]User, int64, error)
+	SetUserDisabled(ctx context.Context, username string, disabled bool) error
+	DeleteUser(ctx context.Context, username string) error
+}
+
+// UserResolver looks up a user by name. The auth middleware uses it to turn
+// away tokens of disabled and deleted users.
+type UserResolver interface {
+	GetUserByUsername(ctx context.Context, username string) (User, error)
 }
 
 type TaskUseCase interface {
-	GetAllTasks() ([]Task, error)
-	GetTaskByID(userID int) (Task, error)
-	CreateTask(task Task) error
-	UpdateTask(userID int, task Task) (Task, error)
-	DeleteTask(userID int) error
+	GetAllTasks(ctx context.Context) ([]Task, error)
+	GetTaskByID(ctx context.Context, userID int) (Task, error)
+	CreateTask(ctx context.Context, task Task) (Task, error)
+	UpdateTask(ctx context.Context, userID int, task Task) (Task, error)
+	DeleteTask(ctx context.Context, userID int) error
+}
+
+// TaskAuthorizer decides whether the caller in ctx may apply action to task,
+// returning ErrTaskForbidden if not. For PermissionTaskUpdate, update is the
+// task as the caller wants it; it is nil for other actions.
+type TaskAuthorizer interface {
+	AuthorizeTask(ctx context.Context, action Permission, task Task, update *Task) error
 }
 
 type UserUseCase interface {
-	RegisterUser(username, password string) error
-	LoginUser(username, password string) (string, error)
-	PromoteUser(username string) error
+	RegisterUser(ctx context.Context, username, password, email string) error
+	// LoginUser starts a session, or returns ErrInvalidCredentials for
+	// unknown users and wrong passwords alike. ip is the client address used
+	// for lockouts.
+	LoginUser(ctx context.Context, username, password, ip string) (TokenPair, error)
+	// RefreshToken rotates a refresh token. Presenting one that was already
+	// rotated revokes its whole session.
+	RefreshToken(ctx context.Context, refreshToken string) (TokenPair, error)
+	// Logout revokes the access token tokenID until it expires and ends its
+	// session.
+	Logout(ctx context.Context, tokenID, sessionID string, expiresAt time.Time) error
+	GetUser(ctx context.Context, username string) (User, error)
+	GetAllUsers(ctx context.Context) ([]User, error)
+	PromoteUser(ctx context.Context, username string) error
+	// UnlockUser lifts a login lockout on an existing user.
+	UnlockUser(ctx context.Context, username string) error
+	ListUsers(ctx context.Context, query UserQuery) (UserPage, error)
+	// DemoteUser, DisableUser and DeleteUser return ErrLastAdmin when the user
+	// is the only enabled Admin. The check is best-effort: two of these calls
+	// racing on the last two Admins can both pass it, leaving the admin CLI as
+	// the way back.
+	DemoteUser(ctx context.Context, username string) error
+	DisableUser(ctx context.Context, username string) error
+	EnableUser(ctx context.Context, username string) error
+	DeleteUser(ctx context.Context, username string) error
+}
+
+// AdminUseCase grants Admin directly against the store, for setting up a
+// deployment before anyone can call the admin-only HTTP endpoints.
+type AdminUseCase interface {
+	// CreateOrPromoteAdmin promotes username, registering it first when it
+	// does not exist. It reports whether the user was created.
+	CreateOrPromoteAdmin(ctx context.Context, username, password, email string) (bool, error)
+	// BootstrapAdmin creates username as an Admin while no enabled Admin
+	// exists. An existing account is only promoted if password is its own,
+	// and refused with ErrBootstrapUserExists otherwise. It reports whether
+	// it did anything.
+	BootstrapAdmin(ctx context.Context, username, password, email string) (bool, error)
 }
@@ -27,3 +27,58 @@ func TestUser_IsAdmin(t *testing.T) {
 	assert.True(t, u1.IsAdmin())
 	assert.False(t, u2.IsAdmin())
 }
+
+func TestWebhook_Validate(t *testing.T) {
+	assert.ErrorIs(t, Webhook{URL: "ftp://example.com", Secret: "s"}.Validate(), ErrInvalidWebhookURL)
+	assert.ErrorIs(t, Webhook{URL: "/relative", Secret: "s"}.Validate(), ErrInvalidWebhookURL)
+	assert.ErrorIs(t, Webhook{URL: "https://example.com", Secret: ""}.Validate(), ErrInvalidWebhookSecret)
+	assert.ErrorIs(t, Webhook{URL: "https://example.com", Secret: "s", Events: []string{"nope"}}.Validate(), ErrInvalidWebhookEvent)
+	assert.NoError(t, Webhook{URL: "https://example.com", Secret: "s", Events: []string{EventTaskCreated, WebhookAllEvents}}.Validate())
+}
+
+func TestWebhook_Subscribes(t *testing.T) {
+	all := Webhook{}
+	filtered := Webhook{Events: []string{EventTaskCreated}}
+	assert.True(t, all.Subscribes(EventUserPromoted))
+	assert.True(t, filtered.Subscribes(EventTaskCreated))
+	assert.False(t, filtered.Subscribes(EventTaskDeleted))
+}
+
+func TestNewUserEvent_StripsPassword(t *testing.T) {
+	event := NewUserEvent(EventUserRegistered, User{UserName: "bob", Password: "hash", Role: "user"})
+	assert.Equal(t, "bob", event.User.UserName)
+	assert.Empty(t, event.User.Password)
+	assert.NotEmpty(t, event.ID)
+}
+
+func TestValidateEmail(t *testing.T) {
+	assert.NoError(t, ValidateEmail(""))
+	assert.NoError(t, ValidateEmail("bob@example.com"))
+	assert.ErrorIs(t, ValidateEmail("bob"), ErrInvalidEmail)
+	assert.ErrorIs(t, ValidateEmail("Bob <bob@example.com>"), ErrInvalidEmail)
+}
+
+func TestComment_Validate(t *testing.T) {
+	assert.ErrorIs(t, Comment{Body: " \n"}.Validate(), ErrInvalidCommentBody)
+	assert.NoError(t, Comment{Body: "hi"}.Validate())
+}
+
+func TestRole_Validate(t *testing.T) {
+	role := Role{Name: "triager", Permissions: []Permission{PermissionTaskUpdate, PermissionTaskCreate, PermissionTaskUpdate}}
+	assert.NoError(t, role.Validate())
+	assert.Equal(t, []Permission{PermissionTaskCreate, PermissionTaskUpdate}, role.Permissions, "catalogue order, no duplicates")
+	assert.True(t, role.Can(PermissionTaskCreate))
+	assert.False(t, role.Can(PermissionTaskDelete))
+
+	assert.ErrorIs(t, (&Role{Name: "9lives"}).Validate(), ErrInvalidRoleName)
+	assert.ErrorIs(t, (&Role{Name: "Admin"}).Validate(), ErrBuiltInRole)
+	assert.ErrorIs(t, (&Role{Name: "ops", Permissions: []Permission{"task:*"}}).Validate(), ErrUnknownPermission)
+
+	admin, ok := BuiltInRole(RoleAdmin)
+	assert.True(t, ok)
+	for _, permission := range Permissions {
+		assert.True(t, admin.Can(permission), permission)
+	}
+	user, _ := BuiltInRole(RoleUser)
+	assert.Empty(t, user.Permissions)
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	EventTaskCreated    = "task.created"
+	EventTaskUpdated    = "task.updated"
+	EventTaskDeleted    = "task.deleted"
+	EventTaskCommented  = "task.commented"
+	EventUserRegistered = "user.registered"
+	EventUserPromoted   = "user.promoted"
+)
+
+var EventTypes = []string{
+	EventTaskCreated,
+	EventTaskUpdated,
+	EventTaskDeleted,
+	EventTaskCommented,
+	EventUserRegistered,
+	EventUserPromoted,
+}
+
+type Event struct {
+	ID         string    `bson:"id" json:"id"`
+	Type       string    `bson:"type" json:"type"`
+	OccurredAt time.Time `bson:"occurred_at" json:"occurred_at"`
+	Task       *Task     `bson:"task,omitempty" json:"task,omitempty"`
+	Previous   *Task     `bson:"previous,omitempty" json:"previous,omitempty"`
+	Comment    *Comment  `bson:"comment,omitempty" json:"comment,omitempty"`
+	User       *User     `bson:"user,omitempty" json:"user,omitempty"`
+}
+
+func NewEvent(eventType string) Event {
+	return Event{
+		ID:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+func NewTaskEvent(eventType string, task Task) Event {
+	event := NewEvent(eventType)
+	event.Task = &task
+	return event
+}
+
+func NewTaskUpdatedEvent(previous, task Task) Event {
+	event := NewTaskEvent(EventTaskUpdated, task)
+	event.Previous = &previous
+	return event
+}
+
+func NewCommentEvent(task Task, comment Comment) Event {
+	event := NewTaskEvent(EventTaskCommented, task)
+	event.Comment = &comment
+	return event
+}
+
+// NewUserEvent only carries the public part of the user so that password
+// hashes never leave the service.
+func NewUserEvent(eventType string, user User) Event {
+	event := NewEvent(eventType)
+	event.User = &User{ID: user.ID, UserName: user.UserName, Role: user.Role, Email: user.Email}
+	return event
+}
+
+func IsKnownEventType(eventType string) bool {
+	for _, known := range EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type EventPublisher interface {
+	Publish(event Event)
+}
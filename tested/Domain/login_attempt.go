@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTooManyLoginAttempts is returned alike for existing and unknown
+// usernames, so a lockout does not reveal which accounts exist.
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+// LoginAttempt counts recent failed logins for a key such as "user:alice" or
+// "ip:192.0.2.1".
+type LoginAttempt struct {
+	Key         string    `bson:"key" json:"key"`
+	Failures    int       `bson:"failures" json:"failures"`
+	LastFailure time.Time `bson:"last_failure" json:"last_failure"`
+	LockedUntil time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+}
+
+func (a LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil.After(now)
+}
+
+type LoginAttemptRepository interface {
+	// GetLoginAttempt returns the zero LoginAttempt for keys without failures.
+	GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error)
+	// RecordFailedLogin adds a failure, starting the count over when the last
+	// one is older than window, and returns the updated attempt.
+	RecordFailedLogin(ctx context.Context, key string, now time.Time, window time.Duration) (LoginAttempt, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ClearLoginAttempts(ctx context.Context, key string) error
+}
+
+// LoginPolicy configures a LoginGuard.
+type LoginPolicy struct {
+	// FreeAttempts failures are answered immediately; after that every
+	// failure is delayed, doubling from 250ms up to MaxDelay.
+	FreeAttempts int
+	MaxDelay     time.Duration
+	// UserThreshold failures for one username, or IPThreshold from one
+	// address, within Window lock logins for LockoutDuration.
+	UserThreshold   int
+	IPThreshold     int
+	Window          time.Duration
+	LockoutDuration time.Duration
+}
+
+// LoginGuard slows down and then locks out repeated failed logins.
+type LoginGuard interface {
+	// Check returns ErrTooManyLoginAttempts while username or ip is locked.
+	Check(ctx context.Context, username, ip string) error
+	// Failed records a failure and blocks for the progressive delay.
+	Failed(ctx context.Context, username, ip string)
+	// Succeeded forgets the username's failures.
+	Succeeded(ctx context.Context, username string)
+	// Unlock lifts a username's lockout.
+	Unlock(ctx context.Context, username string) error
+}
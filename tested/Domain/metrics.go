@@ -0,0 +1,15 @@
+package domain
+
+// Login outcomes reported to a LoginRecorder.
+const (
+	LoginSucceeded     = "success"
+	LoginUnknownUser   = "unknown_user"
+	LoginWrongPassword = "wrong_password"
+	LoginLocked        = "locked"
+	LoginDisabled      = "disabled"
+)
+
+// LoginRecorder counts login attempts by outcome so failures can be alerted on.
+type LoginRecorder interface {
+	RecordLogin(outcome string)
+}
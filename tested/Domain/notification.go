@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotificationQueueFull = errors.New("notification queue is full")
+
+// Notifier delivers user-facing notifications. Implementations must not block
+// the caller on the underlying transport.
+type Notifier interface {
+	NotifyAssignment(user User, task Task) error
+	NotifyDueSoon(user User, task Task) error
+	NotifyComment(user User, task Task, comment Comment) error
+}
+
+type NotificationUseCase interface {
+	EventPublisher
+	SendDueDateReminders(ctx context.Context, window time.Duration) error
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+)
+
+// Permission is one action a role may allow.
+type Permission string
+
+const (
+	PermissionTaskCreate    Permission = "task:create"
+	PermissionTaskUpdate    Permission = "task:update"
+	PermissionTaskDelete    Permission = "task:delete"
+	PermissionUserList      Permission = "user:list"
+	PermissionUserPromote   Permission = "user:promote"
+	PermissionUserUnlock    Permission = "user:unlock"
+	PermissionUserManage    Permission = "user:manage"
+	PermissionRoleManage    Permission = "role:manage"
+	PermissionWebhookManage Permission = "webhook:manage"
+	PermissionPolicyExplain Permission = "policy:explain"
+)
+
+// Permissions is the catalogue of every permission, in display order.
+var Permissions = []Permission{
+	PermissionTaskCreate,
+	PermissionTaskUpdate,
+	PermissionTaskDelete,
+	PermissionUserList,
+	PermissionUserPromote,
+	PermissionUserUnlock,
+	PermissionUserManage,
+	PermissionRoleManage,
+	PermissionWebhookManage,
+	PermissionPolicyExplain,
+}
+
+// Built-in roles. New users get RoleUser, which allows nothing beyond
+// reading tasks and commenting; RoleAdmin allows everything.
+const (
+	RoleAdmin = "Admin"
+	RoleUser  = "user"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleInUse         = errors.New("role is still assigned to users")
+	ErrBuiltInRole       = errors.New("built-in roles cannot be changed")
+	ErrInvalidRoleName   = errors.New("role name must be 1-32 letters, digits, '-' or '_', starting with a letter")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrRoleEscalation    = errors.New("cannot grant permissions you do not hold")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,31}$`)
+
+// Role is a named set of permissions. Custom roles are stored in the
+// database; the built-in ones are not and cannot be changed.
+type Role struct {
+	Name        string       `bson:"_id" json:"name"`
+	Permissions []Permission `bson:"permissions" json:"permissions"`
+	BuiltIn     bool         `bson:"-" json:"built_in"`
+}
+
+func (r Role) Can(permission Permission) bool {
+	return slices.Contains(r.Permissions, permission)
+}
+
+// Validate checks the name and permissions of a custom role, and sorts its
+// permissions into catalogue order without duplicates.
+func (r *Role) Validate() error {
+	if !roleNamePattern.MatchString(r.Name) {
+		return ErrInvalidRoleName
+	}
+	if _, ok := BuiltInRole(r.Name); ok {
+		return ErrBuiltInRole
+	}
+	for _, permission := range r.Permissions {
+		if !slices.Contains(Permissions, permission) {
+			return ErrUnknownPermission
+		}
+	}
+	sorted := []Permission{}
+	for _, permission := range Permissions {
+		if slices.Contains(r.Permissions, permission) {
+			sorted = append(sorted, permission)
+		}
+	}
+	r.Permissions = sorted
+	return nil
+}
+
+func BuiltInRoles() []Role {
+	return []Role{
+		{Name: RoleAdmin, Permissions: slices.Clone(Permissions), BuiltIn: true},
+		{Name: RoleUser, Permissions: []Permission{}, BuiltIn: true},
+	}
+}
+
+func BuiltInRole(name string) (Role, bool) {
+	for _, role := range BuiltInRoles() {
+		if role.Name == name {
+			return role, true
+		}
+	}
+	return Role{}, false
+}
+
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role Role) error
+	GetRole(ctx context.Context, name string) (Role, error)
+	GetAllRoles(ctx context.Context) ([]Role, error)
+	UpdateRole(ctx context.Context, role Role) error
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// RoleResolver looks up a built-in or custom role by name.
+type RoleResolver interface {
+	GetRole(ctx context.Context, name string) (Role, error)
+}
+
+type RoleUseCase interface {
+	RoleResolver
+	// GetAllRoles lists the built-in roles first, then the custom ones.
+	GetAllRoles(ctx context.Context) ([]Role, error)
+	CreateRole(ctx context.Context, role Role) (Role, error)
+	UpdateRole(ctx context.Context, name string, permissions []Permission) (Role, error)
+	// DeleteRole refuses roles that are still assigned, with ErrRoleInUse.
+	DeleteRole(ctx context.Context, name string) error
+	AssignRole(ctx context.Context, username, role string) error
+}
+
+type builtInRoleResolver struct{}
+
+func (builtInRoleResolver) GetRole(_ context.Context, name string) (Role, error) {
+	if role, ok := BuiltInRole(name); ok {
+		return role, nil
+	}
+	return Role{}, ErrRoleNotFound
+}
+
+// BuiltInRoleResolver resolves only the built-in roles, for callers without
+// a database such as tests.
+var BuiltInRoleResolver RoleResolver = builtInRoleResolver{}
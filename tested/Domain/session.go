@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidRefreshToken covers unknown, expired, revoked and reused refresh
+// tokens alike.
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// TokenPair is what a login or refresh hands out: a short-lived access token
+// and the single-use refresh token that replaces it.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// RefreshToken is the stored side of a refresh token. Only a hash of the
+// token is kept. Every token rotated from the same login shares a Family,
+// which is also the "sid" claim of the access tokens issued with it.
+type RefreshToken struct {
+	ID        string    `bson:"_id" json:"-"`
+	Family    string    `bson:"family" json:"family"`
+	Username  string    `bson:"username" json:"username"`
+	IssuedAt  time.Time `bson:"issued_at" json:"issued_at"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	UsedAt    time.Time `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	Revoked   bool      `bson:"revoked,omitempty" json:"revoked,omitempty"`
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token RefreshToken) error
+	// UseRefreshToken marks the token used and returns it as it was before,
+	// so a non-zero UsedAt means it had already been used. Unknown ids
+	// return ErrInvalidRefreshToken.
+	UseRefreshToken(ctx context.Context, id string, now time.Time) (RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, family string) error
+	// RevokeUserRefreshTokens revokes every family of username, so none of
+	// them outlives a disabled or deleted user.
+	RevokeUserRefreshTokens(ctx context.Context, username string) error
+}
+
+// TokenDenylist holds access token ("jti") and session ("sid") ids that must
+// be rejected before they expire.
+type TokenDenylist interface {
+	RevokeToken(ctx context.Context, id string, until time.Time) error
+	IsTokenRevoked(ctx context.Context, ids ...string) (bool, error)
+}
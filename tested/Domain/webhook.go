@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const WebhookAllEvents = "*"
+
+var (
+	ErrInvalidWebhookURL    = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookSecret = errors.New("webhook secret cannot be empty")
+	ErrInvalidWebhookEvent  = errors.New("unknown webhook event type")
+	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrDeadLetterNotFound   = errors.New("dead letter not found")
+)
+
+type Webhook struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	URL       string             `bson:"url" json:"url"`
+	Secret    string             `bson:"secret" json:"secret,omitempty"`
+	Events    []string           `bson:"events" json:"events"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func (w Webhook) Validate() error {
+	parsed, err := url.Parse(w.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ErrInvalidWebhookURL
+	}
+	if strings.TrimSpace(w.Secret) == "" {
+		return ErrInvalidWebhookSecret
+	}
+	for _, eventType := range w.Events {
+		if eventType != WebhookAllEvents && !IsKnownEventType(eventType) {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook wants the given event. An empty
+// event filter subscribes to everything.
+func (w Webhook) Subscribes(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == WebhookAllEvents || subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a delivery that exhausted its retries and was moved to
+// the dead-letter list.
+type WebhookDelivery struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookID primitive.ObjectID `bson:"webhook_id" json:"webhook_id"`
+	Event     Event              `bson:"event" json:"event"`
+	Attempts  int                `bson:"attempts" json:"attempts"`
+	LastError string             `bson:"last_error" json:"last_error"`
+	FailedAt  time.Time          `bson:"failed_at" json:"failed_at"`
+}
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]Webhook, error)
+	GetWebhookByID(ctx context.Context, id string) (Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	AddDeadLetter(ctx context.Context, delivery WebhookDelivery) error
+	GetDeadLetters(ctx context.Context) ([]WebhookDelivery, error)
+	GetDeadLetterByID(ctx context.Context, id string) (WebhookDelivery, error)
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
+
+type WebhookUseCase interface {
+	CreateWebhook(ctx context.Context, webhook Webhook) (Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	GetDeadLetters(ctx context.Context) ([]WebhookDelivery, error)
+	RedeliverDeadLetter(ctx context.Context, id string) error
+}
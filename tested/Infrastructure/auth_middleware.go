@@ -1,19 +1,30 @@
 package infrastructure
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
+
+	domain "task-manager/Domain"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthMiddleware struct {
 	jwtService JWTService
+	denylist   domain.TokenDenylist
+	roles      domain.RoleResolver
+	users      domain.UserResolver
 }
 
-func NewAuthMiddleware(jwtService JWTService) *AuthMiddleware {
+func NewAuthMiddleware(jwtService JWTService, denylist domain.TokenDenylist, roles domain.RoleResolver, users domain.UserResolver) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtService: jwtService,
+		denylist:   denylist,
+		roles:      roles,
+		users:      users,
 	}
 }
 
@@ -28,39 +39,175 @@ func (a *AuthMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "token ")
+		a.authenticate(c, tokenStr)
+	}
+}
+
+// WebSocketAuthMiddleware accepts the same JWT as JWTAuthMiddleware, either in
+// the Authorization header or, because browsers cannot set headers on a
+// WebSocket handshake, in the access_token query parameter.
+func (a *AuthMiddleware) WebSocketAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr := c.Query("access_token")
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "token ") {
+			tokenStr = strings.TrimPrefix(authHeader, "token ")
+		}
 
-		claims, err := a.jwtService.ValidateToken(tokenStr)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		if tokenStr == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid token"})
 			c.Abort()
 			return
 		}
 
-		username := claims["username"]
-		role := claims["role"]
+		a.authenticate(c, tokenStr)
+	}
+}
 
-		c.Set("username", username)
-		c.Set("role", role)
+// Caller names the user whose token the request carries, checking only its
+// signature and expiry. It lets the rate limiter run before authentication
+// without a database round trip, and must not be used to authorize.
+func (a *AuthMiddleware) Caller(c *gin.Context) (string, bool) {
+	tokenStr := c.Query("access_token")
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "token ") {
+		tokenStr = strings.TrimPrefix(authHeader, "token ")
+	}
+	if tokenStr == "" {
+		return "", false
+	}
+	claims, err := a.jwtService.ValidateToken(tokenStr)
+	if err != nil {
+		return "", false
+	}
+	return claims.Username, true
+}
 
-		c.Next()
+func (a *AuthMiddleware) authenticate(c *gin.Context, tokenStr string) {
+	claims, role, err := a.Authenticate(c.Request.Context(), tokenStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	SetCurrentUser(c, claims, role)
+
+	// Everything logged for the rest of the request names the caller.
+	ctx := WithClaims(c.Request.Context(), claims)
+	c.Request = c.Request.WithContext(WithLogger(ctx, LoggerFrom(ctx).With("user", claims.Username)))
+
+	c.Next()
+}
+
+// Authenticate accepts tokenStr only if it is validly signed, unexpired, not
+// revoked and its user has not changed since, and returns its claims with
+// the caller's current role. Every transport authenticates through it.
+func (a *AuthMiddleware) Authenticate(ctx context.Context, tokenStr string) (*Claims, domain.Role, error) {
+	claims, err := a.jwtService.ValidateToken(tokenStr)
+	if err != nil {
+		return nil, domain.Role{}, ErrInvalidToken
+	}
+	if err := a.checkToken(ctx, claims); err != nil {
+		return nil, domain.Role{}, err
 	}
+	return claims, ResolveRole(ctx, a.roles, claims.Role), nil
 }
 
-func (a *AuthMiddleware) AdminOnly() gin.HandlerFunc {
+// Recheck repeats the checks made when claims were accepted, for
+// connections that outlive the request that opened them, and returns the
+// caller's current role.
+func (a *AuthMiddleware) Recheck(ctx context.Context, claims *Claims) (domain.Role, error) {
+	if claims.ExpiresAt != nil && !time.Now().Before(claims.ExpiresAt.Time) {
+		return domain.Role{}, ErrTokenExpired
+	}
+	if err := a.checkToken(ctx, claims); err != nil {
+		return domain.Role{}, err
+	}
+	return ResolveRole(ctx, a.roles, claims.Role), nil
+}
+
+// checkToken rejects validated claims that were revoked or whose user has
+// changed since they were issued.
+func (a *AuthMiddleware) checkToken(ctx context.Context, claims *Claims) error {
+	revoked, err := a.denylist.IsTokenRevoked(ctx, claims.ID, claims.SessionID)
+	if err != nil {
+		// Like the rate limiter, fail open: revoked tokens still expire soon,
+		// while failing closed would log everyone out.
+		LoggerFrom(ctx).Error("failed to check token revocation", "error", err)
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return CheckTokenUser(ctx, a.users, claims)
+}
+
+// RequirePermission lets the request through only if the caller's role
+// grants every one of permissions. It runs after JWTAuthMiddleware.
+func (a *AuthMiddleware) RequirePermission(permissions ...domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
+		if _, ok := CurrentUser(c); !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
 			c.Abort()
 			return
 		}
 
-		if role != "Admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "admin access only"})
-			c.Abort()
-			return
+		role := CurrentRole(c)
+		for _, permission := range permissions {
+			if !role.Can(permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "missing permission " + string(permission)})
+				c.Abort()
+				return
+			}
 		}
 
 		c.Next()
 	}
 }
+
+// ResolveRole looks up a role for authorization. A role that no longer
+// exists, or can't be loaded, grants nothing: callers keep read access but
+// no mutation goes through.
+func ResolveRole(ctx context.Context, roles domain.RoleResolver, name string) domain.Role {
+	role, err := roles.GetRole(ctx, name)
+	if err != nil {
+		if !errors.Is(err, domain.ErrRoleNotFound) {
+			LoggerFrom(ctx).Error("failed to resolve role", "error", err, "role", name)
+		}
+		return domain.Role{Name: name}
+	}
+	return role
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenRevoked = errors.New("token has been revoked")
+	ErrTokenExpired = errors.New("token has expired")
+)
+
+// ErrStaleToken rejects tokens issued before the user's role or disabled
+// flag last changed. Refreshing gets a token that reflects the change.
+var ErrStaleToken = errors.New("token predates a change to the user, refresh it")
+
+// CheckTokenUser returns ErrUserDisabled, ErrUserNotFound or ErrStaleToken
+// for tokens whose user was disabled, deleted or changed after they were
+// issued. A token whose subject is not the user's id belonged to an earlier
+// user of the same name and counts as deleted. If the user can't be loaded
+// it lets the token through, like the denylist check.
+func CheckTokenUser(ctx context.Context, users domain.UserResolver, claims *Claims) error {
+	user, err := users.GetUserByUsername(ctx, claims.Username)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return err
+	case err != nil:
+		LoggerFrom(ctx).Error("failed to check user status", "error", err, "username", claims.Username)
+		return nil
+	case !user.ID.IsZero() && claims.Subject != user.ID.Hex():
+		return domain.ErrUserNotFound
+	case user.Disabled:
+		return domain.ErrUserDisabled
+	case claims.TokenVersion < user.TokenVersion:
+		// Newer tokens than the user are fine: the user came from a cache
+		// that has not seen the change yet.
+		return ErrStaleToken
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// stubUsers resolves the given users, and fails for "broken".
+type stubUsers map[string]domain.User
+
+func (s stubUsers) GetUserByUsername(_ context.Context, username string) (domain.User, error) {
+	if username == "broken" {
+		return domain.User{}, errors.New("mongo is down")
+	}
+	if user, ok := s[username]; ok {
+		return user, nil
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+var testUsers = stubUsers{"bob": {UserName: "bob"}}
+
+func newAuthTestRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", handler, func(c *gin.Context) {
+		claims, _ := CurrentUser(c)
+		c.String(http.StatusOK, claims.Username)
+	})
+	return r
+}
+
+func TestWebSocketAuthMiddleware_AcceptsHeaderOrQuery(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	token, _ := jwtService.GenerateToken(domain.User{UserName: "bob", Role: "user"}, "session-1")
+	r := newAuthTestRouter(NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, testUsers).WebSocketAuthMiddleware())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?access_token="+token, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "bob", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "token "+token)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWebSocketAuthMiddleware_Rejects(t *testing.T) {
+	r := newAuthTestRouter(NewAuthMiddleware(NewJWTService([]byte("test-secret"), time.Hour), NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, testUsers).WebSocketAuthMiddleware())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?access_token=garbage", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestJWTAuthMiddleware_RejectsRevokedTokensAndSessions(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	denylist := NewMemoryTokenDenylist()
+	r := newAuthTestRouter(NewAuthMiddleware(jwtService, denylist, domain.BuiltInRoleResolver, testUsers).JWTAuthMiddleware())
+	get := func(token string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "token "+token)
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	first, _ := jwtService.GenerateToken(domain.User{UserName: "bob"}, "session-1")
+	second, _ := jwtService.GenerateToken(domain.User{UserName: "bob"}, "session-1")
+	other, _ := jwtService.GenerateToken(domain.User{UserName: "bob"}, "session-2")
+	claims, _ := jwtService.ValidateToken(first)
+	assert.Equal(t, http.StatusOK, get(first))
+
+	denylist.RevokeToken(context.Background(), claims.ID, time.Now().Add(time.Hour))
+	assert.Equal(t, http.StatusUnauthorized, get(first))
+	assert.Equal(t, http.StatusOK, get(second), "only the revoked jti is rejected")
+
+	denylist.RevokeToken(context.Background(), "session-1", time.Now().Add(time.Hour))
+	assert.Equal(t, http.StatusUnauthorized, get(second))
+	assert.Equal(t, http.StatusOK, get(other))
+}
+
+// stubRoles resolves the built-in roles plus the given custom ones, and
+// fails for "broken".
+type stubRoles map[string]domain.Role
+
+func (s stubRoles) GetRole(ctx context.Context, name string) (domain.Role, error) {
+	if role, ok := s[name]; ok {
+		return role, nil
+	}
+	if name == "broken" {
+		return domain.Role{}, errors.New("mongo is down")
+	}
+	return domain.BuiltInRoleResolver.GetRole(ctx, name)
+}
+
+func TestRequirePermission(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	roles := stubRoles{"triager": {Name: "triager", Permissions: []domain.Permission{domain.PermissionTaskUpdate}}}
+	auth := NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), roles, testUsers)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/tasks/1", auth.JWTAuthMiddleware(), auth.RequirePermission(domain.PermissionTaskUpdate), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.DELETE("/tasks/1", auth.JWTAuthMiddleware(), auth.RequirePermission(domain.PermissionTaskDelete), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/unauthenticated", auth.RequirePermission(domain.PermissionTaskUpdate), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(method, path, role string) *httptest.ResponseRecorder {
+		token, err := jwtService.GenerateToken(domain.User{UserName: "bob", Role: role}, "session-1")
+		assert.NoError(t, err)
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "token "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/tasks/1", domain.RoleAdmin).Code)
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, "/tasks/1", "triager").Code)
+	rec := call(http.MethodDelete, "/tasks/1", "triager")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"error":"missing permission task:delete"}`, rec.Body.String())
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPut, "/tasks/1", domain.RoleUser).Code)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPut, "/tasks/1", "deleted-role").Code)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPut, "/tasks/1", "broken").Code, "an unresolvable role grants nothing")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unauthenticated", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestJWTAuthMiddleware_RejectsDisabledAndDeletedUsers(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	users := stubUsers{"bob": {UserName: "bob"}, "eve": {UserName: "eve", Disabled: true}}
+	r := newAuthTestRouter(NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, users).JWTAuthMiddleware())
+	get := func(username string) *httptest.ResponseRecorder {
+		token, _ := jwtService.GenerateToken(domain.User{UserName: username}, "session-1")
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "token "+token)
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get("bob").Code)
+	rec := get("eve")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":"user is disabled"}`, rec.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, get("ghost").Code)
+	assert.Equal(t, http.StatusOK, get("broken").Code, "a failed lookup lets the token through")
+}
+
+func TestJWTAuthMiddleware_RejectsTokensOlderThanTheUser(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	users := stubUsers{"bob": {UserName: "bob", TokenVersion: 2}}
+	r := newAuthTestRouter(NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, users).JWTAuthMiddleware())
+	get := func(version int64) *httptest.ResponseRecorder {
+		token, _ := jwtService.GenerateToken(domain.User{UserName: "bob", TokenVersion: version}, "session-1")
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "token "+token)
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(1)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":"token predates a change to the user, refresh it"}`, rec.Body.String())
+	assert.Equal(t, http.StatusOK, get(2).Code)
+	assert.Equal(t, http.StatusOK, get(3).Code, "a cached user may lag behind a fresh token")
+}
+
+func TestJWTAuthMiddleware_RejectsTokensOfAnEarlierUserWithTheSameName(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	first := domain.User{ID: primitive.NewObjectID(), UserName: "bob"}
+	users := stubUsers{"bob": first}
+	r := newAuthTestRouter(NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, users).JWTAuthMiddleware())
+	token, _ := jwtService.GenerateToken(first, "session-1")
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "token "+token)
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get().Code)
+
+	// bob is deleted and someone registers the name again.
+	users["bob"] = domain.User{ID: primitive.NewObjectID(), UserName: "bob"}
+	rec := get()
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":"user not found"}`, rec.Body.String())
+}
+
+func TestAuthMiddleware_Recheck(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	denylist := NewMemoryTokenDenylist()
+	users := stubUsers{"bob": {UserName: "bob", Role: domain.RoleAdmin}}
+	auth := NewAuthMiddleware(jwtService, denylist, domain.BuiltInRoleResolver, users)
+	token, _ := jwtService.GenerateToken(users["bob"], "session-1")
+	claims, err := jwtService.ValidateToken(token)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	role, err := auth.Recheck(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, role.Can(domain.PermissionUserManage))
+
+	users["bob"] = domain.User{UserName: "bob", Disabled: true}
+	_, err = auth.Recheck(ctx, claims)
+	assert.ErrorIs(t, err, domain.ErrUserDisabled)
+
+	denylist.RevokeToken(ctx, "session-1", time.Now().Add(time.Hour))
+	_, err = auth.Recheck(ctx, claims)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	claims.ExpiresAt.Time = time.Now().Add(-time.Second)
+	_, err = auth.Recheck(ctx, claims)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestAuthMiddleware_CallerChecksOnlyTheSignature(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	auth := NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, stubUsers{})
+	caller := func(header, query string) (string, bool) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		if header != "" {
+			c.Request.Header.Set("Authorization", header)
+		}
+		return auth.Caller(c)
+	}
+	token, _ := jwtService.GenerateToken(domain.User{UserName: "ghost"}, "session-1")
+
+	username, ok := caller("token "+token, "")
+	assert.True(t, ok)
+	assert.Equal(t, "ghost", username, "unknown users are still named")
+	username, _ = caller("", "?access_token="+token)
+	assert.Equal(t, "ghost", username)
+
+	_, ok = caller("token forged", "")
+	assert.False(t, ok)
+	_, ok = caller("", "")
+	assert.False(t, ok)
+}
@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims are the claims of an access token. Subject is the user's id;
+// Username, Role and TokenVersion are copied from the user when the token is
+// issued.
+type Claims struct {
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	SessionID    string `json:"sid,omitempty"`
+	TokenVersion int64  `json:"ver,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) IsAdmin() bool {
+	return c.Role == domain.RoleAdmin
+}
+
+// Validate runs after the registered claims are checked, so a token that
+// verifies but names nobody, or can't be revoked, is still rejected.
+func (c *Claims) Validate() error {
+	switch {
+	case c.Username == "":
+		return errors.New("token has no username")
+	case c.ID == "":
+		return errors.New("token has no jti")
+	}
+	return nil
+}
+
+const (
+	currentUserKey = "auth.claims"
+	currentRoleKey = "auth.role"
+)
+
+// SetCurrentUser records the caller the auth middleware authenticated and
+// the role it resolved for them.
+func SetCurrentUser(c *gin.Context, claims *Claims, role domain.Role) {
+	c.Set(currentUserKey, claims)
+	c.Set(currentRoleKey, role)
+}
+
+// CurrentUser returns the claims of the request's access token; ok is false
+// on routes without the auth middleware.
+func CurrentUser(c *gin.Context) (claims *Claims, ok bool) {
+	value, _ := c.Get(currentUserKey)
+	claims, ok = value.(*Claims)
+	return claims, ok && claims != nil
+}
+
+// CurrentRole is the role of the authenticated caller; it grants nothing on
+// routes without the auth middleware.
+func CurrentRole(c *gin.Context) domain.Role {
+	value, _ := c.Get(currentRoleKey)
+	role, _ := value.(domain.Role)
+	return role
+}
+
+type claimsKey struct{}
+
+// WithClaims carries the caller's claims past the transport, so use cases
+// can authorize against them.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFrom returns the claims stored by WithClaims; ok is false for
+// unauthenticated or internal calls.
+func ClaimsFrom(ctx context.Context) (claims *Claims, ok bool) {
+	claims, ok = ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+func newEmailTemplate(name, subject, text, html string) emailTemplate {
+	return emailTemplate{
+		subject: texttemplate.Must(texttemplate.New(name + "_subject").Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New(name + "_text").Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New(name + "_html").Parse(html)),
+	}
+}
+
+var (
+	assignmentTemplate = newEmailTemplate("assignment",
+		`You have been assigned task #{{.Task.UserID}}: {{.Task.Title}}`,
+		`Hi {{.User.UserName}},
+
+You have been assigned task #{{.Task.UserID}} "{{.Task.Title}}".
+
+{{.Task.Description}}
+{{if not .Task.DueDate.IsZero}}
+Due: {{.Task.DueDate.Format "2006-01-02 15:04 MST"}}
+{{end}}`,
+		`<p>Hi {{.User.UserName}},</p>
+<p>You have been assigned task #{{.Task.UserID}} <strong>{{.Task.Title}}</strong>.</p>
+<p>{{.Task.Description}}</p>
+{{if not .Task.DueDate.IsZero}}<p>Due: {{.Task.DueDate.Format "2006-01-02 15:04 MST"}}</p>{{end}}`,
+	)
+
+	dueSoonTemplate = newEmailTemplate("due_soon",
+		`Reminder: task #{{.Task.UserID}} is due {{.Task.DueDate.Format "2006-01-02 15:04 MST"}}`,
+		`Hi {{.User.UserName}},
+
+Task #{{.Task.UserID}} "{{.Task.Title}}" is due {{.Task.DueDate.Format "2006-01-02 15:04 MST"}} and is currently "{{.Task.Status}}".
+`,
+		`<p>Hi {{.User.UserName}},</p>
+<p>Task #{{.Task.UserID}} <strong>{{.Task.Title}}</strong> is due {{.Task.DueDate.Format "2006-01-02 15:04 MST"}} and is currently &quot;{{.Task.Status}}&quot;.</p>`,
+	)
+
+	commentTemplate = newEmailTemplate("comment",
+		`New comment on task #{{.Task.UserID}}: {{.Task.Title}}`,
+		`Hi {{.User.UserName}},
+
+{{.Comment.Author}} commented on task #{{.Task.UserID}} "{{.Task.Title}}":
+
+{{.Comment.Body}}
+`,
+		`<p>Hi {{.User.UserName}},</p>
+<p>{{.Comment.Author}} commented on task #{{.Task.UserID}} <strong>{{.Task.Title}}</strong>:</p>
+<blockquote>{{.Comment.Body}}</blockquote>`,
+	)
+)
@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"sync"
+
+	domain "task-manager/Domain"
+)
+
+// EventBus fans every published event out to its subscribers in
+// registration order. Subscribers must return quickly.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []domain.EventPublisher
+}
+
+func NewEventBus(subscribers ...domain.EventPublisher) *EventBus {
+	return &EventBus{
+		subscribers: subscribers,
+	}
+}
+
+func (b *EventBus) Subscribe(subscriber domain.EventPublisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+func (b *EventBus) Publish(event domain.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, subscriber := range b.subscribers {
+		subscriber.Publish(event)
+	}
+}
@@ -0,0 +1,30 @@
+package infrastructure
+
+import (
+	"testing"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingPublisher struct {
+	events []domain.Event
+}
+
+func (r *recordingPublisher) Publish(event domain.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestEventBus_FansOutToSubscribers(t *testing.T) {
+	first := &recordingPublisher{}
+	second := &recordingPublisher{}
+	bus := NewEventBus(first)
+	bus.Subscribe(second)
+
+	event := domain.NewEvent(domain.EventTaskCreated)
+	bus.Publish(event)
+
+	assert.Equal(t, []domain.Event{event}, first.events)
+	assert.Equal(t, []domain.Event{event}, second.events)
+}
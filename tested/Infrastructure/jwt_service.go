@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"time"
 
 	domain "task-manager/Domain"
@@ -8,42 +10,134 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("BlackBox")
+// Default issuer and audience of access tokens, for NewJWTService.
+const (
+	DefaultIssuer   = ServiceName
+	DefaultAudience = "task-manager-api"
+)
 
 type JWTService interface {
-	GenerateToken(user domain.User) (string, error)
-	ValidateToken(tokenString string) (jwt.MapClaims, error)
+	// GenerateToken issues an access token for user in session sessionID,
+	// with a fresh "jti" so it can be revoked on its own.
+	GenerateToken(user domain.User, sessionID string) (string, error)
+	// ValidateToken accepts only tokens signed with a known key in that
+	// key's algorithm, for this issuer and audience, and currently valid.
+	ValidateToken(tokenString string) (*Claims, error)
 }
 
-type jwtServiceImpl struct{}
+// JWTOptions describe the tokens a JWTService issues and accepts; an empty
+// Issuer or Audience means the default. Leeway tolerates clock skew between
+// servers when checking exp, nbf and iat.
+type JWTOptions struct {
+	Expiry   time.Duration
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
 
-func NewJWTService() JWTService {
-	return &jwtServiceImpl{}
+type jwtServiceImpl struct {
+	keys    *KeySet
+	options JWTOptions
+	parser  *jwt.Parser
 }
 
-func (j *jwtServiceImpl) GenerateToken(user domain.User) (string, error) {
-	claims := jwt.MapClaims{
-		"username": user.UserName,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+// NewJWTService signs with a single HS256 secret, for the default issuer
+// and audience.
+func NewJWTService(secret []byte, expiry time.Duration) JWTService {
+	keys := &KeySet{
+		keys: []SigningKey{{Algorithm: AlgorithmHS256, Key: secret}},
+		now:  time.Now,
 	}
+	return NewKeySetJWTService(keys, JWTOptions{Expiry: expiry, Leeway: 30 * time.Second})
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+// NewKeySetJWTService signs with the active key of keys and names it in the
+// "kid" header, so tokens signed before a rotation keep validating.
+func NewKeySetJWTService(keys *KeySet, options JWTOptions) JWTService {
+	if options.Issuer == "" {
+		options.Issuer = DefaultIssuer
+	}
+	if options.Audience == "" {
+		options.Audience = DefaultAudience
+	}
+	return &jwtServiceImpl{
+		keys:    keys,
+		options: options,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(keys.algorithms()),
+			jwt.WithIssuer(options.Issuer),
+			jwt.WithAudience(options.Audience),
+			jwt.WithLeeway(options.Leeway),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithTimeFunc(func() time.Time { return keys.now() }),
+		),
+	}
 }
 
-func (j *jwtServiceImpl) ValidateToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+func (j *jwtServiceImpl) GenerateToken(user domain.User, sessionID string) (string, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := j.keys.now()
+	claims := &Claims{
+		Username:     user.UserName,
+		Role:         user.Role,
+		SessionID:    sessionID,
+		TokenVersion: user.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.options.Issuer,
+			Audience:  jwt.ClaimStrings{j.options.Audience},
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.options.Expiry)),
+		},
+	}
+	if !user.ID.IsZero() {
+		claims.Subject = user.ID.Hex()
+	}
 
+	key, err := j.keys.signing()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.Key)
+}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+func (j *jwtServiceImpl) ValidateToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := j.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		id, _ := token.Header["kid"].(string)
+		key, ok := j.keys.verifying(id)
+		if !ok {
+			return nil, ErrUnknownSigningKey
+		}
+		// The algorithm comes from the key, never from the token, so an
+		// RS256 public key can't be replayed as an HS256 secret.
+		if token.Method.Alg() != key.Algorithm {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key.verificationKey(), nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return claims, nil
+}
 
-	return nil, jwt.ErrSignatureInvalid
+// NewTokenID returns 256 random bits, URL-safe encoded, for token ids and
+// refresh tokens.
+func NewTokenID() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
 }
@@ -1,6 +1,12 @@
 package infrastructure
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"testing"
 	"time"
 
@@ -8,42 +14,232 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestJWTService_GenerateAndValidate(t *testing.T) {
-	service := NewJWTService()
-	user := domain.User{UserName: "bob", Role: "Admin"}
+	service := NewJWTService([]byte("test-secret"), time.Hour)
+	user := domain.User{ID: primitive.NewObjectID(), UserName: "bob", Role: "Admin", TokenVersion: 3}
 
-	token, err := service.GenerateToken(user)
+	token, err := service.GenerateToken(user, "session-1")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
 	claims, err := service.ValidateToken(token)
 	assert.NoError(t, err)
-	assert.Equal(t, "bob", claims["username"])
-	assert.Equal(t, "Admin", claims["role"])
+	assert.Equal(t, "bob", claims.Username)
+	assert.Equal(t, "Admin", claims.Role)
+	assert.True(t, claims.IsAdmin())
+	assert.Equal(t, "session-1", claims.SessionID)
+	assert.Equal(t, int64(3), claims.TokenVersion)
+	assert.Equal(t, user.ID.Hex(), claims.Subject)
+	assert.Equal(t, DefaultIssuer, claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{DefaultAudience}, claims.Audience)
+	assert.NotEmpty(t, claims.ID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt.Time, time.Minute)
+
+	again, err := service.GenerateToken(user, "session-1")
+	assert.NoError(t, err)
+	againClaims, err := service.ValidateToken(again)
+	assert.NoError(t, err)
+	assert.NotEqual(t, claims.ID, againClaims.ID, "every token gets its own jti")
 }
 
 func TestJWTService_ValidateToken_Invalid(t *testing.T) {
-	service := NewJWTService()
+	service := NewJWTService([]byte("test-secret"), time.Hour)
 	_, err := service.ValidateToken("invalid.token.value")
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, jwt.ErrTokenMalformed)
 }
 
 func TestJWTService_ValidateToken_Expired(t *testing.T) {
-	service := NewJWTService()
-	// Create a token already expired
-	claims := jwt.MapClaims{
+	service := NewJWTService([]byte("test-secret"), time.Hour)
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	_, err := service.ValidateToken(signHS256(t, claims))
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestJWTService_ValidateToken_RejectsClaims(t *testing.T) {
+	service := NewJWTService([]byte("test-secret"), time.Hour)
+	now := time.Now()
+
+	for name, change := range map[string]func(jwt.MapClaims){
+		"other issuer":      func(c jwt.MapClaims) { c["iss"] = "someone-else" },
+		"no issuer":         func(c jwt.MapClaims) { delete(c, "iss") },
+		"other audience":    func(c jwt.MapClaims) { c["aud"] = "another-api" },
+		"no expiry":         func(c jwt.MapClaims) { delete(c, "exp") },
+		"not yet valid":     func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() },
+		"issued in future":  func(c jwt.MapClaims) { c["iat"] = now.Add(time.Minute).Unix() },
+		"expired past skew": func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() },
+		"no username":       func(c jwt.MapClaims) { delete(c, "username") },
+		"no jti":            func(c jwt.MapClaims) { delete(c, "jti") },
+	} {
+		claims := validClaims()
+		change(claims)
+		_, err := service.ValidateToken(signHS256(t, claims))
+		assert.Error(t, err, name)
+	}
+
+	// Clocks a few seconds apart still agree.
+	claims := validClaims()
+	claims["iat"] = now.Add(10 * time.Second).Unix()
+	claims["nbf"] = now.Add(10 * time.Second).Unix()
+	_, err := service.ValidateToken(signHS256(t, claims))
+	assert.NoError(t, err)
+	claims["exp"] = now.Add(-10 * time.Second).Unix()
+	_, err = service.ValidateToken(signHS256(t, claims))
+	assert.NoError(t, err)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+	_, err = service.ValidateToken(unsigned)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+// validClaims are what NewJWTService accepts, for tests to break one at a
+// time.
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
 		"username": "bob",
-		"role":     "user",
-		"exp":      time.Now().Add(-time.Hour).Unix(),
+		"role":     "User",
+		"jti":      "jti-1",
+		"iss":      DefaultIssuer,
+		"aud":      DefaultAudience,
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
+		"exp":      now.Add(time.Hour).Unix(),
 	}
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := jwtToken.SignedString([]byte("BlackBox"))
+}
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestJWTService_AsymmetricKeys(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	for _, key := range []SigningKey{
+		{ID: "ed", Algorithm: AlgorithmEdDSA, Key: edKey},
+		{ID: "rsa", Algorithm: AlgorithmRS256, Key: rsaKey},
+	} {
+		keys, err := NewKeySet(time.Hour, key)
+		assert.NoError(t, err)
+		service := NewKeySetJWTService(keys, JWTOptions{Expiry: time.Hour})
+
+		token, err := service.GenerateToken(domain.User{UserName: "bob", Role: "User"}, "session-1")
+		assert.NoError(t, err)
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+		assert.NoError(t, err)
+		assert.Equal(t, key.ID, parsed.Header["kid"])
+		assert.Equal(t, key.Algorithm, parsed.Method.Alg())
+
+		claims, err := service.ValidateToken(token)
+		assert.NoError(t, err, key.Algorithm)
+		assert.Equal(t, "bob", claims.Username)
+	}
+}
+
+func TestJWTService_KeyRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	rotation := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	keys, err := NewKeySet(time.Hour,
+		SigningKey{ID: "new", Algorithm: AlgorithmEdDSA, Key: newKey, ActivatesAt: rotation},
+		SigningKey{ID: "old", Algorithm: AlgorithmEdDSA, Key: oldKey},
+	)
+	assert.NoError(t, err)
+	now := rotation.Add(-time.Minute)
+	keys.now = func() time.Time { return now }
+	service := NewKeySetJWTService(keys, JWTOptions{Expiry: 24 * time.Hour})
+	user := domain.User{UserName: "bob", Role: "User"}
+
+	before, err := service.GenerateToken(user, "session-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old", "new"}, keyIDs(keys.PublicKeys()), "the next key is published ahead of the rotation")
+
+	now = rotation.Add(30 * time.Minute)
+	after, err := service.GenerateToken(user, "session-1")
+	assert.NoError(t, err)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(after, jwt.MapClaims{})
+	assert.Equal(t, "new", parsed.Header["kid"])
+	_, err = service.ValidateToken(before)
+	assert.NoError(t, err, "the old key verifies during the grace period")
+
+	now = rotation.Add(time.Hour)
+	_, err = service.ValidateToken(before)
+	assert.ErrorIs(t, err, ErrUnknownSigningKey)
+	_, err = service.ValidateToken(after)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new"}, keyIDs(keys.PublicKeys()))
+}
+
+func TestJWTService_RejectsAlgorithmOtherThanTheKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, err := NewKeySet(time.Hour, SigningKey{ID: "rsa", Algorithm: AlgorithmRS256, Key: rsaKey})
+	assert.NoError(t, err)
+	service := NewKeySetJWTService(keys, JWTOptions{Expiry: time.Hour})
+
+	// Signed with the public modulus as an HMAC secret, which a verifier
+	// trusting the token's "alg" header would accept.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"username": "mallory", "role": "Admin"})
+	forged.Header["kid"] = "rsa"
+	signed, err := forged.SignedString(rsaKey.PublicKey.N.Bytes())
 	assert.NoError(t, err)
 
 	_, err = service.ValidateToken(signed)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestKeySet_PublicKeysOmitSecrets(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	keys, err := NewKeySet(time.Hour,
+		SigningKey{ID: "hmac", Algorithm: AlgorithmHS256, Key: []byte("secret")},
+		SigningKey{ID: "ed", Algorithm: AlgorithmEdDSA, Key: edKey},
+	)
+	assert.NoError(t, err)
+
+	jwks := keys.PublicKeys()
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Curve)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey)), jwks.Keys[0].X)
+
+	_, err = NewKeySet(time.Hour, SigningKey{ID: "ed", Algorithm: AlgorithmRS256, Key: edKey})
+	assert.Error(t, err, "key type must match the algorithm")
+	_, err = NewKeySet(time.Hour, SigningKey{ID: "ed", Algorithm: AlgorithmEdDSA, Key: edKey}, SigningKey{ID: "ed", Algorithm: AlgorithmEdDSA, Key: edKey})
+	assert.Error(t, err, "key ids must be unique")
+}
+
+func keyIDs(set JSONWebKeySet) []string {
+	var ids []string
+	for _, key := range set.Keys {
+		ids = append(ids, key.ID)
+	}
+	return ids
+}
+
+func TestParseSigningKey(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	assert.NoError(t, err)
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	key, err := ParseSigningKey("ed", AlgorithmEdDSA, pemBytes, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, edKey, key.Key)
+
+	_, err = ParseSigningKey("ed", AlgorithmRS256, pemBytes, time.Time{})
 	assert.Error(t, err)
-	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	_, err = ParseSigningKey("ed", AlgorithmHS256, pemBytes, time.Time{})
+	assert.Error(t, err, "HS256 secrets are not PEM keys")
 }
@@ -0,0 +1,63 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// redactedKeys are attribute names whose values never reach the log output,
+// matched case-insensitively at any group depth.
+var redactedKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"secret":        true,
+	"authorization": true,
+	"cookie":        true,
+}
+
+const redacted = "[REDACTED]"
+
+// NewLogger returns a JSON logger that redacts credentials.
+func NewLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}))
+}
+
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	if redactedKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithLogger stores a request-scoped logger for use cases and repositories.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFrom returns the logger stored by WithLogger, or slog.Default().
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
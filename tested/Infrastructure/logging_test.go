@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var line map[string]any
+		require.NoError(t, dec.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestNewLogger_RedactsCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, slog.LevelInfo)
+
+	logger.Info("login", "username", "alice", "Password", "hunter22",
+		slog.Group("headers", "Authorization", "token abc", "accept", "json"))
+	logger.Debug("dropped below the level")
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "alice", lines[0]["username"])
+	assert.Equal(t, "[REDACTED]", lines[0]["Password"])
+	headers := lines[0]["headers"].(map[string]any)
+	assert.Equal(t, "[REDACTED]", headers["Authorization"])
+	assert.Equal(t, "json", headers["accept"])
+	assert.NotContains(t, buf.String(), "hunter22")
+}
+
+func TestLoggerFrom_FallsBackToDefault(t *testing.T) {
+	assert.Same(t, slog.Default(), LoggerFrom(context.Background()))
+
+	logger := NewLogger(&bytes.Buffer{}, slog.LevelInfo)
+	ctx := WithRequestID(WithLogger(context.Background(), logger), "abc")
+	assert.Same(t, logger, LoggerFrom(ctx))
+	assert.Equal(t, "abc", RequestIDFrom(ctx))
+	assert.Empty(t, RequestIDFrom(context.Background()))
+}
@@ -0,0 +1,140 @@
+package infrastructure
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns the Prometheus registry served on /metrics. Each instance has
+// its own registry, so tests can create as many as they like.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	mongoDuration   *prometheus.HistogramVec
+	logins          *prometheus.CounterVec
+	tasks           *prometheus.GaugeVec
+	overdueTasks    prometheus.Gauge
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route template and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mongo_operation_duration_seconds",
+			Help:    "MongoDB latency by repository and method.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_logins_total",
+			Help: "Login attempts by outcome.",
+		}, []string{"outcome"}),
+		tasks: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tasks",
+			Help: "Tasks by status, as of the last collection.",
+		}, []string{"status"}),
+		overdueTasks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tasks_overdue",
+			Help: "Tasks past their due date and not done, as of the last collection.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.mongoDuration,
+		m.logins,
+		m.tasks,
+		m.overdueTasks,
+	)
+	// Start the outcomes at zero so alerts on their rate work from the
+	// first scrape.
+	for _, outcome := range []string{domain.LoginSucceeded, domain.LoginUnknownUser, domain.LoginWrongPassword, domain.LoginLocked, domain.LoginDisabled} {
+		m.logins.WithLabelValues(outcome)
+	}
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records every request under its route template rather than its
+// path, so task ids do not each become a series.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": c.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(c.Writer.Status()),
+		}
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveMongo matches repositories.Observer.
+func (m *Metrics) ObserveMongo(repository, method string, elapsed time.Duration) {
+	m.mongoDuration.WithLabelValues(repository, method).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) RecordLogin(outcome string) {
+	m.logins.WithLabelValues(outcome).Inc()
+}
+
+const maxStatusLabels = 20
+
+// RecordTasks replaces the task gauges with counts over tasks.
+func (m *Metrics) RecordTasks(tasks []domain.Task, now time.Time) {
+	byStatus := map[string]int{}
+	overdue := 0
+	for _, task := range tasks {
+		status := task.Status
+		switch {
+		case status == "":
+			status = "none"
+		case byStatus[status] == 0 && len(byStatus) >= maxStatusLabels:
+			// Statuses are free text; keep a typo-ridden store from
+			// creating unbounded series.
+			status = "other"
+		}
+		byStatus[status]++
+		if !task.DueDate.IsZero() && task.DueDate.Before(now) && task.Status != "done" {
+			overdue++
+		}
+	}
+
+	m.tasks.Reset()
+	for status, count := range byStatus {
+		m.tasks.WithLabelValues(status).Set(float64(count))
+	}
+	m.overdueTasks.Set(float64(overdue))
+}
+
+var _ domain.LoginRecorder = (*Metrics)(nil)
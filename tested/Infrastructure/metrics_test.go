@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_MiddlewareLabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := NewMetrics()
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/tasks/1", "/tasks/2", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/tasks/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "unmatched", "404")))
+	body := scrape(t, m)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/tasks/:id",status="200"} 2`)
+	assert.NotContains(t, body, "/tasks/1")
+}
+
+func TestMetrics_MongoAndLogins(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveMongo("tasks", "GetTaskByID", 3*time.Millisecond)
+	m.RecordLogin(domain.LoginWrongPassword)
+	m.RecordLogin(domain.LoginWrongPassword)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `mongo_operation_duration_seconds_count{method="GetTaskByID",repository="tasks"} 1`)
+	assert.Contains(t, body, `auth_logins_total{outcome="wrong_password"} 2`)
+	assert.Contains(t, body, `auth_logins_total{outcome="success"} 0`)
+}
+
+func TestMetrics_RecordTasks(t *testing.T) {
+	m := NewMetrics()
+	now := time.Now()
+	m.RecordTasks([]domain.Task{
+		{Status: "todo", DueDate: now.Add(-time.Hour)},
+		{Status: "todo", DueDate: now.Add(time.Hour)},
+		{Status: "done", DueDate: now.Add(-time.Hour)},
+		{Status: ""},
+	}, now)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.tasks.WithLabelValues("todo")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.tasks.WithLabelValues("none")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.overdueTasks))
+
+	// Statuses that disappear are dropped rather than left at their last
+	// value, and free-text statuses cannot grow the series without bound.
+	var tasks []domain.Task
+	for i := 0; i < maxStatusLabels+5; i++ {
+		tasks = append(tasks, domain.Task{Status: fmt.Sprintf("s%d", i)})
+	}
+	m.RecordTasks(tasks, now)
+
+	assert.Equal(t, maxStatusLabels+1, testutil.CollectAndCount(m.tasks))
+	assert.Equal(t, 5.0, testutil.ToFloat64(m.tasks.WithLabelValues("other")))
+	assert.False(t, strings.Contains(scrape(t, m), `status="todo"`))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.overdueTasks))
+}
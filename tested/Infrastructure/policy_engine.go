@@ -0,0 +1,394 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyDeny is the only policy effect: the role permissions decide what a
+// caller may do, and policies can only take some of it away again.
+const PolicyDeny = "deny"
+
+// taskActions are the actions a policy can name.
+var taskActions = []domain.Permission{domain.PermissionTaskCreate, domain.PermissionTaskUpdate, domain.PermissionTaskDelete}
+
+// taskFields are the task fields an update can change.
+var taskFields = []string{"title", "description", "due_date", "status", "assignee", "project"}
+
+// Policy is one rule of a policy file. It applies to a request when the
+// action is listed, every condition in When holds and, if Fields is set, the
+// update changes at least one of those fields, and then denies it.
+//
+// A condition compares two operands with == or !=. An operand is a quoted
+// literal, subject.id, subject.username, subject.role, or resource.<field>
+// and update.<field> for the task before and after an update, where field is
+// id, created_by or one of taskFields.
+type Policy struct {
+	Name        string              `yaml:"name" json:"name"`
+	Description string              `yaml:"description" json:"description,omitempty"`
+	Effect      string              `yaml:"effect" json:"effect"`
+	Actions     []domain.Permission `yaml:"actions" json:"actions"`
+	When        []string            `yaml:"when" json:"when,omitempty"`
+	Fields      []string            `yaml:"fields" json:"fields,omitempty"`
+
+	conditions []condition
+}
+
+// PolicySet is a parsed policy file. The first policy that applies denies
+// the request; when none does, the request is allowed.
+type PolicySet struct {
+	Policies []Policy `yaml:"policies"`
+
+	// version identifies the file the set was loaded from, if any.
+	version fileVersion
+}
+
+type fileVersion struct {
+	modTime int64
+	size    int64
+}
+
+func versionOf(info os.FileInfo) fileVersion {
+	return fileVersion{modTime: info.ModTime().UnixNano(), size: info.Size()}
+}
+
+// PolicyRequest is one attempt to touch a task. Subject is nil for calls
+// without an authenticated caller.
+type PolicyRequest struct {
+	Subject  *Claims
+	Action   domain.Permission
+	Resource domain.Task
+	// Update is the task as the caller wants it after a task:update.
+	Update *domain.Task
+}
+
+// PolicyDecision explains itself: Policy names the policy that denied, or is
+// empty when none applied, and Trace has the outcome of every policy.
+type PolicyDecision struct {
+	Allowed bool               `json:"allowed"`
+	Policy  string             `json:"policy,omitempty"`
+	Trace   []PolicyEvaluation `json:"trace"`
+}
+
+type PolicyEvaluation struct {
+	Policy  string `json:"policy"`
+	Effect  string `json:"effect"`
+	Applies bool   `json:"applies"`
+	Reason  string `json:"reason"`
+}
+
+// ParsePolicies reads a YAML policy file and checks every policy, so a typo
+// fails the load instead of silently never matching.
+func ParsePolicies(data []byte) (*PolicySet, error) {
+	set := &PolicySet{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(set); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(set.Policies))
+	for i := range set.Policies {
+		policy := &set.Policies[i]
+		if err := policy.compile(); err != nil {
+			return nil, fmt.Errorf("policy %q: %w", policy.Name, err)
+		}
+		if seen[policy.Name] {
+			return nil, fmt.Errorf("policy %q is listed twice", policy.Name)
+		}
+		seen[policy.Name] = true
+	}
+	return set, nil
+}
+
+func LoadPolicyFile(path string) (*PolicySet, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set, err := ParsePolicies(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	set.version = versionOf(info)
+	return set, nil
+}
+
+func (p *Policy) compile() error {
+	switch {
+	case p.Name == "":
+		return errors.New("name is required")
+	case p.Effect == "":
+		p.Effect = PolicyDeny
+	case p.Effect != PolicyDeny:
+		return fmt.Errorf("effect must be %q; policies can only narrow the role permissions", PolicyDeny)
+	}
+	if len(p.Actions) == 0 {
+		return errors.New("actions are required")
+	}
+	for _, action := range p.Actions {
+		if !slices.Contains(taskActions, action) {
+			return fmt.Errorf("unknown action %q", action)
+		}
+	}
+	for _, field := range p.Fields {
+		if !slices.Contains(taskFields, field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+	}
+	if len(p.Fields) > 0 && !slices.Equal(p.Actions, []domain.Permission{domain.PermissionTaskUpdate}) {
+		return errors.New("fields only apply to task:update")
+	}
+
+	p.conditions = make([]condition, 0, len(p.When))
+	for _, source := range p.When {
+		c, err := parseCondition(source)
+		if err != nil {
+			return err
+		}
+		p.conditions = append(p.conditions, c)
+	}
+	return nil
+}
+
+func (p Policy) evaluate(req PolicyRequest) (applies bool, reason string) {
+	if !slices.Contains(p.Actions, req.Action) {
+		return false, "action " + string(req.Action) + " is not listed"
+	}
+	for _, c := range p.conditions {
+		if !c.holds(req) {
+			return false, "condition not met: " + c.source
+		}
+	}
+	if len(p.Fields) > 0 {
+		changed := changedFields(req)
+		if !slices.ContainsFunc(p.Fields, func(field string) bool { return slices.Contains(changed, field) }) {
+			return false, "update does not change " + strings.Join(p.Fields, ", ")
+		}
+	}
+	return true, "applies"
+}
+
+// Evaluate decides req and records why.
+func (s *PolicySet) Evaluate(req PolicyRequest) PolicyDecision {
+	decision := PolicyDecision{Trace: make([]PolicyEvaluation, 0, len(s.Policies))}
+	for _, policy := range s.Policies {
+		applies, reason := policy.evaluate(req)
+		decision.Trace = append(decision.Trace, PolicyEvaluation{Policy: policy.Name, Effect: policy.Effect, Applies: applies, Reason: reason})
+		if applies && decision.Policy == "" {
+			decision.Policy = policy.Name
+		}
+	}
+	decision.Allowed = decision.Policy == ""
+	return decision
+}
+
+// changedFields lists the fields a task:update changes.
+func changedFields(req PolicyRequest) []string {
+	if req.Update == nil {
+		return nil
+	}
+	var changed []string
+	for _, field := range taskFields {
+		before, _ := taskAttribute(req.Resource, field)
+		after, _ := taskAttribute(*req.Update, field)
+		if before != after {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+func taskAttribute(task domain.Task, name string) (string, bool) {
+	switch name {
+	case "id":
+		return strconv.Itoa(task.UserID), true
+	case "created_by":
+		return task.CreatedBy, true
+	case "title":
+		return task.Title, true
+	case "description":
+		return task.Description, true
+	case "due_date":
+		if task.DueDate.IsZero() {
+			return "", true
+		}
+		return task.DueDate.UTC().Format(time.RFC3339), true
+	case "status":
+		return task.Status, true
+	case "assignee":
+		return task.Assignee, true
+	case "project":
+		return task.Project, true
+	}
+	return "", false
+}
+
+var conditionPattern = regexp.MustCompile(`^\s*('[^']*'|[\w.]+)\s*(==|!=)\s*('[^']*'|[\w.]+)\s*$`)
+
+type condition struct {
+	source      string
+	left, right operand
+	negate      bool
+}
+
+func parseCondition(source string) (condition, error) {
+	match := conditionPattern.FindStringSubmatch(source)
+	if match == nil {
+		return condition{}, fmt.Errorf("condition %q must compare two operands with == or !=", source)
+	}
+	left, err := parseOperand(match[1])
+	if err != nil {
+		return condition{}, err
+	}
+	right, err := parseOperand(match[3])
+	if err != nil {
+		return condition{}, err
+	}
+	return condition{source: source, left: left, right: right, negate: match[2] == "!="}, nil
+}
+
+func (c condition) holds(req PolicyRequest) bool {
+	return (c.left.value(req) == c.right.value(req)) != c.negate
+}
+
+// operand is a literal, or an attribute of the subject, resource or update.
+type operand struct {
+	literal   string
+	scope     string
+	attribute string
+}
+
+func parseOperand(source string) (operand, error) {
+	if strings.HasPrefix(source, "'") {
+		return operand{literal: strings.Trim(source, "'")}, nil
+	}
+	scope, attribute, _ := strings.Cut(source, ".")
+	switch scope {
+	case "subject":
+		if attribute == "id" || attribute == "username" || attribute == "role" {
+			return operand{scope: scope, attribute: attribute}, nil
+		}
+	case "resource", "update":
+		if _, ok := taskAttribute(domain.Task{}, attribute); ok {
+			return operand{scope: scope, attribute: attribute}, nil
+		}
+	}
+	return operand{}, fmt.Errorf("unknown attribute %q", source)
+}
+
+func (o operand) value(req PolicyRequest) string {
+	switch o.scope {
+	case "subject":
+		if req.Subject == nil {
+			return ""
+		}
+		switch o.attribute {
+		case "id":
+			return req.Subject.Subject
+		case "username":
+			return req.Subject.Username
+		default:
+			return req.Subject.Role
+		}
+	case "resource":
+		value, _ := taskAttribute(req.Resource, o.attribute)
+		return value
+	case "update":
+		if req.Update == nil {
+			return ""
+		}
+		value, _ := taskAttribute(*req.Update, o.attribute)
+		return value
+	}
+	return o.literal
+}
+
+// PolicyEngine evaluates the current PolicySet for the task use cases. Watch
+// swaps in a new set whenever the policy file changes.
+type PolicyEngine struct {
+	policies atomic.Pointer[PolicySet]
+}
+
+// NewPolicyEngine starts with policies; nil denies nothing, leaving tasks
+// governed by roles alone.
+func NewPolicyEngine(policies *PolicySet) *PolicyEngine {
+	if policies == nil {
+		policies = &PolicySet{}
+	}
+	e := &PolicyEngine{}
+	e.policies.Store(policies)
+	return e
+}
+
+func (e *PolicyEngine) Evaluate(req PolicyRequest) PolicyDecision {
+	return e.policies.Load().Evaluate(req)
+}
+
+// AuthorizeTask implements domain.TaskAuthorizer for the caller whose claims
+// are in ctx.
+func (e *PolicyEngine) AuthorizeTask(ctx context.Context, action domain.Permission, task domain.Task, update *domain.Task) error {
+	claims, _ := ClaimsFrom(ctx)
+	decision := e.Evaluate(PolicyRequest{Subject: claims, Action: action, Resource: task, Update: update})
+	if decision.Allowed {
+		return nil
+	}
+	LoggerFrom(ctx).Info("audit", "action", "task.policy_denied", "permission", action, "task_id", task.UserID, "policy", decision.Policy)
+	return domain.ErrTaskForbidden
+}
+
+// Watch checks path every interval and loads it again when its modification
+// time or size differs from the policies in force. A file that fails to load
+// is logged once and the previous policies stay in force. It returns when ctx
+// is done.
+func (e *PolicyEngine) Watch(ctx context.Context, path string, interval time.Duration) error {
+	logger := LoggerFrom(ctx).With("path", path)
+	var failed fileVersion
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			logger.Error("failed to check the policy file", "error", err)
+			continue
+		}
+		version := versionOf(info)
+		if version == e.policies.Load().version || version == failed {
+			continue
+		}
+
+		policies, err := LoadPolicyFile(path)
+		if err != nil {
+			failed = version
+			logger.Error("failed to reload the policy file; keeping the previous policies", "error", err)
+			continue
+		}
+		e.policies.Store(policies)
+		logger.Info("reloaded the policy file", "policies", len(policies.Policies))
+	}
+}
@@ -0,0 +1,162 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicies = `
+policies:
+  - name: only-creator-deletes
+    description: Only the creator of a task may delete it
+    effect: deny
+    actions: [task:delete]
+    when: ["subject.username != resource.created_by"]
+  - name: assignee-keeps-due-date
+    description: Assignees may change the status but not the due date
+    effect: deny
+    actions: [task:update]
+    when: ["subject.username == resource.assignee"]
+    fields: [due_date]
+`
+
+func TestParsePolicies_RejectsMistakes(t *testing.T) {
+	for name, source := range map[string]string{
+		"unknown key":       "policies:\n  - name: p\n    effect: deny\n    actions: [task:delete]\n    wehn: []\n",
+		"unknown effect":    "policies:\n  - name: p\n    effect: maybe\n    actions: [task:delete]\n",
+		"unknown action":    "policies:\n  - name: p\n    effect: deny\n    actions: [user:list]\n",
+		"no actions":        "policies:\n  - name: p\n    effect: deny\n",
+		"unknown attribute": "policies:\n  - name: p\n    effect: deny\n    actions: [task:delete]\n    when: [\"subject.email == 'x'\"]\n",
+		"bad operator":      "policies:\n  - name: p\n    effect: deny\n    actions: [task:delete]\n    when: [\"subject.role ~= 'x'\"]\n",
+		"unknown field":     "policies:\n  - name: p\n    effect: deny\n    actions: [task:update]\n    fields: [colour]\n",
+		"fields on delete":  "policies:\n  - name: p\n    effect: deny\n    actions: [task:delete]\n    fields: [status]\n",
+		"duplicate name":    "policies:\n  - {name: p, effect: deny, actions: [task:delete]}\n  - {name: p, effect: deny, actions: [task:delete]}\n",
+		"allow effect":      "policies:\n  - name: p\n    effect: allow\n    actions: [task:delete]\n",
+		"default":           "default: deny\n",
+	} {
+		_, err := ParsePolicies([]byte(source))
+		assert.Error(t, err, name)
+	}
+
+	set, err := ParsePolicies([]byte("policies:\n  - {name: p, actions: [task:delete]}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyDeny, set.Policies[0].Effect, "deny when no effect is given")
+}
+
+func TestPolicySet_Evaluate(t *testing.T) {
+	set, err := ParsePolicies([]byte(testPolicies))
+	require.NoError(t, err)
+
+	alice := &Claims{Username: "alice", Role: domain.RoleUser}
+	bob := &Claims{Username: "bob", Role: domain.RoleUser}
+	admin := &Claims{Username: "root", Role: domain.RoleAdmin}
+	task := domain.Task{UserID: 1, Title: "t", Description: "d", Status: "open", Assignee: "bob", CreatedBy: "alice"}
+
+	decision := set.Evaluate(PolicyRequest{Subject: bob, Action: domain.PermissionTaskDelete, Resource: task})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "only-creator-deletes", decision.Policy)
+	require.Len(t, decision.Trace, 2)
+	assert.Equal(t, PolicyEvaluation{Policy: "only-creator-deletes", Effect: PolicyDeny, Applies: true, Reason: "applies"}, decision.Trace[0])
+	assert.Equal(t, "action task:delete is not listed", decision.Trace[1].Reason)
+
+	// policies only narrow the role permissions, so nothing exempts an admin
+	// unless a condition says so
+	decision = set.Evaluate(PolicyRequest{Subject: admin, Action: domain.PermissionTaskDelete, Resource: task})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "only-creator-deletes", decision.Policy)
+
+	decision = set.Evaluate(PolicyRequest{Subject: alice, Action: domain.PermissionTaskDelete, Resource: task})
+	assert.True(t, decision.Allowed)
+	assert.Empty(t, decision.Policy, "no policy applies")
+
+	status := task
+	status.Status = "done"
+	decision = set.Evaluate(PolicyRequest{Subject: bob, Action: domain.PermissionTaskUpdate, Resource: task, Update: &status})
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "update does not change due_date", decision.Trace[1].Reason)
+
+	due := task
+	due.DueDate = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	decision = set.Evaluate(PolicyRequest{Subject: bob, Action: domain.PermissionTaskUpdate, Resource: task, Update: &due})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "assignee-keeps-due-date", decision.Policy)
+
+	decision = set.Evaluate(PolicyRequest{Subject: admin, Action: domain.PermissionTaskUpdate, Resource: task, Update: &due})
+	assert.True(t, decision.Allowed)
+}
+
+func TestPolicySet_LegacyTasksWithoutCreator(t *testing.T) {
+	set, err := ParsePolicies([]byte(`
+policies:
+  - name: only-creator-deletes
+    actions: [task:delete]
+    when: ["subject.username != resource.created_by", "resource.created_by != ''"]
+`))
+	require.NoError(t, err)
+
+	legacy := domain.Task{UserID: 1}
+	assert.True(t, set.Evaluate(PolicyRequest{Subject: &Claims{Username: "bob"}, Action: domain.PermissionTaskDelete, Resource: legacy}).Allowed)
+	owned := domain.Task{UserID: 2, CreatedBy: "alice"}
+	assert.False(t, set.Evaluate(PolicyRequest{Subject: &Claims{Username: "bob"}, Action: domain.PermissionTaskDelete, Resource: owned}).Allowed)
+}
+
+func TestPolicyEngine_AuthorizeTaskUsesTheCallerInContext(t *testing.T) {
+	set, err := ParsePolicies([]byte(testPolicies))
+	require.NoError(t, err)
+	engine := NewPolicyEngine(set)
+	task := domain.Task{CreatedBy: "alice"}
+
+	ctx := WithClaims(context.Background(), &Claims{Username: "bob"})
+	assert.ErrorIs(t, engine.AuthorizeTask(ctx, domain.PermissionTaskDelete, task, nil), domain.ErrTaskForbidden)
+
+	ctx = WithClaims(context.Background(), &Claims{Username: "alice"})
+	assert.NoError(t, engine.AuthorizeTask(ctx, domain.PermissionTaskDelete, task, nil))
+
+	assert.NoError(t, NewPolicyEngine(nil).AuthorizeTask(context.Background(), domain.PermissionTaskDelete, task, nil))
+}
+
+func TestPolicyEngine_WatchReloadsTheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("policies: []\n"), 0o600))
+	set, err := LoadPolicyFile(path)
+	require.NoError(t, err)
+	engine := NewPolicyEngine(set)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		engine.Watch(ctx, path, 5*time.Millisecond)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	write := func(content string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	allowed := func() bool {
+		return engine.Evaluate(PolicyRequest{Action: domain.PermissionTaskCreate}).Allowed
+	}
+
+	write("policies:\n  - {name: no-create, actions: [task:create]}\n", time.Now().Add(time.Minute))
+	assert.Eventually(t, func() bool { return !allowed() }, time.Second, 5*time.Millisecond)
+
+	// a broken file keeps the previous policies
+	write("policies: [\n", time.Now().Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, allowed())
+
+	write("policies: []\n", time.Now().Add(3*time.Minute))
+	assert.Eventually(t, allowed, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,178 @@
+package infrastructure
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy is a token bucket: it holds up to Burst requests and
+// refills at Requests per Per. Name keeps the buckets of different policies
+// apart.
+type RateLimitPolicy struct {
+	Name     string
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (p RateLimitPolicy) perSecond() float64 {
+	return float64(p.Requests) / p.Per.Seconds()
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request would be allowed; zero
+	// when this one was.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// RateLimitStore keeps the buckets. The in-memory store is enough for one
+// instance; several instances behind a load balancer need a shared store
+// that applies the same arithmetic atomically per key.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	takes   int
+}
+
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: map[string]*tokenBucket{}}
+}
+
+// evictEvery bounds memory: full buckets are dropped, since a missing bucket
+// starts out full anyway.
+const evictEvery = 1024
+
+func (s *memoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%evictEvery == 0 {
+		for k, b := range s.buckets {
+			if !b.fullAt.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+	}
+
+	burst := float64(policy.Burst)
+	rate := policy.perSecond()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, updated: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+		b.updated = now
+	}
+
+	result := RateLimitResult{Limit: policy.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((burst - b.tokens) / rate)
+	b.fullAt = now.Add(result.Reset)
+	return result, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// CallerFunc names the user a request is made as; ok is false for
+// anonymous requests.
+type CallerFunc func(c *gin.Context) (username string, ok bool)
+
+type RateLimiter struct {
+	store  RateLimitStore
+	caller CallerFunc
+	now    func() time.Time
+}
+
+// NewRateLimiter takes the caller from caller, or from the auth middleware
+// that already ran if caller is nil.
+func NewRateLimiter(store RateLimitStore, caller CallerFunc) *RateLimiter {
+	if caller == nil {
+		caller = authenticatedCaller
+	}
+	return &RateLimiter{store: store, caller: caller, now: time.Now}
+}
+
+func authenticatedCaller(c *gin.Context) (string, bool) {
+	claims, ok := CurrentUser(c)
+	if !ok {
+		return "", false
+	}
+	return claims.Username, true
+}
+
+// Limit applies policy per caller, and per client IP for anonymous
+// requests. Rejected requests get 429 with Retry-After; every response
+// carries the RateLimit-* headers.
+func (l *RateLimiter) Limit(policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := ipKey(policy, c.ClientIP())
+		if username, ok := l.caller(c); ok {
+			key = policy.Name + ":user:" + username
+		}
+
+		result, err := l.store.Take(c.Request.Context(), key, policy, l.now())
+		if err != nil {
+			// An unreachable shared store must not take the API down with it.
+			LoggerFrom(c.Request.Context()).Error("rate limit store failed", "error", err, "policy", policy.Name)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// TakeIP charges a request to ip's bucket for policy, the same bucket Limit
+// charges anonymous HTTP requests from ip to.
+func (l *RateLimiter) TakeIP(ctx context.Context, policy RateLimitPolicy, ip string) (RateLimitResult, error) {
+	return l.store.Take(ctx, ipKey(policy, ip), policy, l.now())
+}
+
+func ipKey(policy RateLimitPolicy, ip string) string {
+	return policy.Name + ":ip:" + ip
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimitStore_TokenBucket(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	policy := RateLimitPolicy{Name: "login", Requests: 1, Per: time.Second, Burst: 2}
+	now := time.Unix(1000, 0)
+	ctx := context.Background()
+
+	first, _ := store.Take(ctx, "k", policy, now)
+	second, _ := store.Take(ctx, "k", policy, now)
+	third, _ := store.Take(ctx, "k", policy, now)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, time.Second, third.RetryAfter)
+	assert.Equal(t, 2*time.Second, third.Reset)
+
+	other, _ := store.Take(ctx, "other", policy, now)
+	assert.True(t, other.Allowed, "buckets are per key")
+
+	refilled, _ := store.Take(ctx, "k", policy, now.Add(1500*time.Millisecond))
+	assert.True(t, refilled.Allowed)
+	assert.Equal(t, 0, refilled.Remaining)
+
+	full, _ := store.Take(ctx, "k", policy, now.Add(time.Hour))
+	assert.Equal(t, 1, full.Remaining, "tokens never exceed the burst")
+}
+
+func TestMemoryRateLimitStore_EvictsFullBuckets(t *testing.T) {
+	store := NewMemoryRateLimitStore().(*memoryRateLimitStore)
+	policy := RateLimitPolicy{Name: "api", Requests: 1, Per: time.Second, Burst: 1}
+	now := time.Unix(1000, 0)
+
+	_, _ = store.Take(context.Background(), "idle", policy, now)
+	for i := 1; i < evictEvery; i++ {
+		_, _ = store.Take(context.Background(), "busy", policy, now.Add(time.Minute))
+	}
+
+	assert.NotContains(t, store.buckets, "idle")
+	assert.Contains(t, store.buckets, "busy")
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, RateLimitPolicy, time.Time) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store unavailable")
+}
+
+func newRateLimitedRouter(store RateLimitStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	limiter := NewRateLimiter(store, nil)
+	limiter.now = func() time.Time { return time.Unix(1000, 0) }
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			SetCurrentUser(c, &Claims{Username: user}, domain.Role{})
+		}
+	}, limiter.Limit(RateLimitPolicy{Name: "api", Requests: 1, Per: time.Minute, Burst: 1}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func get(r http.Handler, ip, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiter_KeysByUserOrIP(t *testing.T) {
+	r := newRateLimitedRouter(NewMemoryRateLimitStore())
+
+	rec := get(r, "10.0.0.1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+
+	rec = get(r, "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"rate limit exceeded"}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusOK, get(r, "10.0.0.2", "").Code, "other IPs have their own bucket")
+
+	// Authenticated callers are limited as themselves, wherever they connect
+	// from.
+	assert.Equal(t, http.StatusOK, get(r, "10.0.0.1", "alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(r, "10.0.0.3", "alice").Code)
+	assert.Equal(t, http.StatusOK, get(r, "10.0.0.3", "bob").Code)
+}
+
+func TestRateLimiter_FailsOpenWhenTheStoreFails(t *testing.T) {
+	r := newRateLimitedRouter(failingRateLimitStore{})
+
+	for i := 0; i < 3; i++ {
+		rec := get(r, "10.0.0.1", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimiter_TakeIPSharesTheAnonymousBucket(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limiter := NewRateLimiter(store, nil)
+	limiter.now = func() time.Time { return time.Unix(1000, 0) }
+	policy := RateLimitPolicy{Name: "api", Requests: 1, Per: time.Minute, Burst: 1}
+
+	result, err := limiter.TakeIP(context.Background(), policy, "10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	r := newRateLimitedRouter(store)
+	assert.Equal(t, http.StatusTooManyRequests, get(r, "10.0.0.1", "").Code)
+	assert.Equal(t, http.StatusOK, get(r, "10.0.0.1", "alice").Code)
+}
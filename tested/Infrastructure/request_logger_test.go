@@ -0,0 +1,95 @@
+package infrastructure
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoggedRouter(buf *bytes.Buffer, handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger(NewLogger(buf, slog.LevelInfo)), Recovery())
+	r.GET("/tasks/:id", handlers...)
+	return r
+}
+
+func TestRequestLogger_GeneratesAndPropagatesIDs(t *testing.T) {
+	var buf bytes.Buffer
+	var seen string
+	r := newLoggedRouter(&buf, func(c *gin.Context) {
+		seen = RequestIDFrom(c.Request.Context())
+		LoggerFrom(c.Request.Context()).Info("handled")
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/1", nil))
+	generated := rec.Header().Get(RequestIDHeader)
+	assert.Len(t, generated, 32)
+	assert.Equal(t, generated, seen)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "handled", lines[0]["msg"])
+	assert.Equal(t, generated, lines[0]["request_id"])
+	assert.Equal(t, "request", lines[1]["msg"])
+	assert.Equal(t, "/tasks/:id", lines[1]["route"])
+	assert.EqualValues(t, http.StatusNoContent, lines[1]["status"])
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+	req.Header.Set(RequestIDHeader, "upstream-42")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "upstream-42", rec.Header().Get(RequestIDHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nforged=line")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Len(t, rec.Header().Get(RequestIDHeader), 32)
+}
+
+func TestRequestLogger_LogsUserAndRedactsQuery(t *testing.T) {
+	jwtService := NewJWTService([]byte("test-secret"), time.Hour)
+	token, _ := jwtService.GenerateToken(domain.User{UserName: "bob", Role: "user"}, "session-1")
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf, NewAuthMiddleware(jwtService, NewMemoryTokenDenylist(), domain.BuiltInRoleResolver, testUsers).WebSocketAuthMiddleware(), func(c *gin.Context) {
+		LoggerFrom(c.Request.Context()).Info("handled")
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/1?access_token="+token+"&page=2", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "bob", lines[0]["user"])
+	assert.Equal(t, "bob", lines[1]["user"])
+	assert.Equal(t, "access_token=%5BREDACTED%5D&page=2", lines[1]["query"])
+	assert.NotContains(t, buf.String(), token)
+}
+
+func TestRecovery_LogsPanicsAs500(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf, func(c *gin.Context) { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks/1", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "panic recovered", lines[0]["msg"])
+	assert.Equal(t, "ERROR", lines[1]["level"])
+}
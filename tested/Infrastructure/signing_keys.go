@@ -0,0 +1,209 @@
+package infrastructure
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms accepted for access tokens.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrUnknownSigningKey = errors.New("unknown or retired signing key")
+
+// SigningKey is one key of a KeySet. Key is a []byte secret for HS256, an
+// *rsa.PrivateKey for RS256 and an ed25519.PrivateKey for EdDSA.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Key       any
+	// ActivatesAt is when the key starts signing tokens; zero means
+	// straight away. Until then it is already published in the JWKS so
+	// verifiers can pick it up ahead of the rotation.
+	ActivatesAt time.Time
+}
+
+func (k SigningKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// verificationKey is what jwt.Parse needs to check a signature.
+func (k SigningKey) verificationKey() any {
+	switch key := k.Key.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case ed25519.PrivateKey:
+		return key.Public()
+	default:
+		return k.Key
+	}
+}
+
+func (k SigningKey) validate() error {
+	var ok bool
+	switch k.Algorithm {
+	case AlgorithmHS256:
+		_, ok = k.Key.([]byte)
+	case AlgorithmRS256:
+		_, ok = k.Key.(*rsa.PrivateKey)
+	case AlgorithmEdDSA:
+		_, ok = k.Key.(ed25519.PrivateKey)
+	default:
+		return fmt.Errorf("signing key %q: unsupported algorithm %q", k.ID, k.Algorithm)
+	}
+	if !ok {
+		return fmt.Errorf("signing key %q: key does not match algorithm %s", k.ID, k.Algorithm)
+	}
+	return nil
+}
+
+// ParseSigningKey reads a PEM encoded private key for RS256 or EdDSA.
+func ParseSigningKey(id, algorithm string, pem []byte, activatesAt time.Time) (SigningKey, error) {
+	key := SigningKey{ID: id, Algorithm: algorithm, ActivatesAt: activatesAt}
+	var err error
+	switch algorithm {
+	case AlgorithmRS256:
+		key.Key, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+	case AlgorithmEdDSA:
+		key.Key, err = jwt.ParseEdPrivateKeyFromPEM(pem)
+	default:
+		return SigningKey{}, fmt.Errorf("signing key %q: unsupported algorithm %q", id, algorithm)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("signing key %q: %w", id, err)
+	}
+	return key, key.validate()
+}
+
+// KeySet rotates between signing keys on their ActivatesAt schedule. The
+// newest active key signs; a key it replaced keeps verifying tokens for
+// the grace period, which should be at least the access token lifetime.
+type KeySet struct {
+	keys  []SigningKey
+	grace time.Duration
+	now   func() time.Time
+}
+
+func NewKeySet(grace time.Duration, keys ...SigningKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("a key set needs at least one key")
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if err := key.validate(); err != nil {
+			return nil, err
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("signing key %q is listed twice", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	sorted := append([]SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivatesAt.Before(sorted[j].ActivatesAt)
+	})
+	return &KeySet{keys: sorted, grace: grace, now: time.Now}, nil
+}
+
+// algorithms lists the algorithms of the keys, the only ones a token may
+// claim.
+func (s *KeySet) algorithms() []string {
+	var algorithms []string
+	for _, key := range s.keys {
+		if !slices.Contains(algorithms, key.Algorithm) {
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+	return algorithms
+}
+
+// signing returns the key that signs tokens now.
+func (s *KeySet) signing() (SigningKey, error) {
+	now := s.now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActivatesAt.After(now) {
+			return s.keys[i], nil
+		}
+	}
+	return SigningKey{}, errors.New("no signing key is active yet")
+}
+
+// retired reports whether the key at index i was replaced more than the
+// grace period ago.
+func (s *KeySet) retired(i int, now time.Time) bool {
+	for _, next := range s.keys[i+1:] {
+		if !next.ActivatesAt.Add(s.grace).After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifying looks up a key by the token's "kid" header.
+func (s *KeySet) verifying(id string) (SigningKey, bool) {
+	now := s.now()
+	for i, key := range s.keys {
+		if key.ID == id && !s.retired(i, now) {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// JSONWebKey is the public half of an RS256 or EdDSA key, as published at
+// /.well-known/jwks.json.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeys lists the asymmetric keys that are scheduled or still verify
+// tokens. HS256 secrets are never published.
+func (s *KeySet) PublicKeys() JSONWebKeySet {
+	now := s.now()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for i, key := range s.keys {
+		if s.retired(i, now) {
+			continue
+		}
+		jwk := JSONWebKey{ID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch private := key.Key.(type) {
+		case *rsa.PrivateKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(private.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(private.E)).Bytes())
+		case ed25519.PrivateKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(private.Public().(ed25519.PublicKey))
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,201 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	domain "task-manager/Domain"
+)
+
+type SMTPConfig struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// EmailNotifier is a domain.Notifier that sends emails in the background.
+// Close stops accepting notifications and waits for the queue to drain.
+type EmailNotifier interface {
+	domain.Notifier
+	Close()
+}
+
+type emailMessage struct {
+	to      string
+	subject string
+	text    string
+	html    string
+}
+
+type smtpNotifierImpl struct {
+	config SMTPConfig
+	queue  chan emailMessage
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewSMTPNotifier renders notifications on the caller's goroutine and hands
+// them to a single worker over a queue of queueSize messages. When the queue
+// is full, notifications are rejected with domain.ErrNotificationQueueFull
+// rather than blocking the caller.
+func NewSMTPNotifier(config SMTPConfig, queueSize int) EmailNotifier {
+	n := &smtpNotifierImpl{
+		config: config,
+		queue:  make(chan emailMessage, queueSize),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+type emailData struct {
+	User    domain.User
+	Task    domain.Task
+	Comment domain.Comment
+}
+
+func (n *smtpNotifierImpl) NotifyAssignment(user domain.User, task domain.Task) error {
+	return n.enqueue(assignmentTemplate, emailData{User: user, Task: task})
+}
+
+func (n *smtpNotifierImpl) NotifyDueSoon(user domain.User, task domain.Task) error {
+	return n.enqueue(dueSoonTemplate, emailData{User: user, Task: task})
+}
+
+func (n *smtpNotifierImpl) NotifyComment(user domain.User, task domain.Task, comment domain.Comment) error {
+	return n.enqueue(commentTemplate, emailData{User: user, Task: task, Comment: comment})
+}
+
+func (n *smtpNotifierImpl) Close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	<-n.done
+}
+
+func (n *smtpNotifierImpl) enqueue(tmpl emailTemplate, data emailData) error {
+	if data.User.Email == "" {
+		return nil
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return err
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return err
+	}
+
+	message := emailMessage{
+		to:      data.User.Email,
+		subject: subject.String(),
+		text:    text.String(),
+		html:    html.String(),
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return domain.ErrNotificationQueueFull
+	}
+
+	select {
+	case n.queue <- message:
+		return nil
+	default:
+		return domain.ErrNotificationQueueFull
+	}
+}
+
+func (n *smtpNotifierImpl) run() {
+	defer close(n.done)
+	for message := range n.queue {
+		if err := n.send(message); err != nil {
+			slog.Error("failed to send email", "to", message.to, "error", err)
+		}
+	}
+}
+
+func (n *smtpNotifierImpl) send(message emailMessage) error {
+	body, err := buildEmail(n.config.From, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		host := n.config.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, host)
+	}
+
+	return smtp.SendMail(n.config.Addr, auth, n.config.From, []string{message.to}, body)
+}
+
+// buildEmail renders a multipart/alternative message with a plain-text and
+// an HTML part.
+func buildEmail(from string, message emailMessage) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", message.text},
+		{"text/html; charset=utf-8", message.html},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	// Strip line breaks so user-controlled titles cannot inject headers.
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(message.subject)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", message.to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	fmt.Fprintf(&msg, "\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
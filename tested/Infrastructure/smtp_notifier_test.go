@@ -0,0 +1,198 @@
+package infrastructure
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTPServer is an in-process SMTP stand-in that understands just enough
+// of the protocol for net/smtp.SendMail and records every message.
+type fakeSMTPServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSMTPServer) Messages() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail{}, s.messages...)
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP test")
+	var current receivedMail
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = receivedMail{from: strings.Trim(strings.TrimSpace(line)[10:], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			current.to = append(current.to, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier_SendsMultipartAssignmentEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewSMTPNotifier(SMTPConfig{Addr: server.Addr(), From: "tasks@example.com"}, 10)
+
+	user := domain.User{UserName: "bob", Email: "bob@example.com"}
+	task := domain.Task{UserID: 7, Title: "Ship <it>", Description: "before friday"}
+	assert.NoError(t, notifier.NotifyAssignment(user, task))
+	notifier.Close()
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "tasks@example.com", messages[0].from)
+	assert.Equal(t, []string{"bob@example.com"}, messages[0].to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "You have been assigned task #7: Ship <it>", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, _ := io.ReadAll(part)
+		parts[part.Header.Get("Content-Type")] = string(body)
+	}
+	assert.Contains(t, parts["text/plain; charset=utf-8"], `"Ship <it>"`)
+	assert.Contains(t, parts["text/html; charset=utf-8"], "Ship &lt;it&gt;")
+}
+
+func TestSMTPNotifier_CommentAndDueSoonTemplates(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewSMTPNotifier(SMTPConfig{Addr: server.Addr(), From: "tasks@example.com"}, 10)
+
+	user := domain.User{UserName: "bob", Email: "bob@example.com"}
+	task := domain.Task{UserID: 2, Title: "Review", Status: "open", DueDate: time.Date(2030, 1, 2, 15, 4, 0, 0, time.UTC)}
+	assert.NoError(t, notifier.NotifyDueSoon(user, task))
+	assert.NoError(t, notifier.NotifyComment(user, task, domain.Comment{Author: "alice", Body: "ping"}))
+	notifier.Close()
+
+	messages := server.Messages()
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0].data, "2030-01-02 15:04 UTC")
+	assert.Contains(t, messages[1].data, "alice commented on task #2")
+}
+
+func TestSMTPNotifier_DoesNotBlockWhenQueueIsFull(t *testing.T) {
+	// Nothing listens on this address, and the queue holds a single message,
+	// so at least one of the notifications below has to be rejected.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	notifier := NewSMTPNotifier(SMTPConfig{Addr: addr, From: "tasks@example.com"}, 1)
+	defer notifier.Close()
+
+	user := domain.User{UserName: "bob", Email: "bob@example.com"}
+	var rejected bool
+	for i := 0; i < 20; i++ {
+		if err := notifier.NotifyAssignment(user, domain.Task{UserID: i}); err == domain.ErrNotificationQueueFull {
+			rejected = true
+		}
+	}
+	assert.True(t, rejected)
+}
+
+func TestSMTPNotifier_SkipsUsersWithoutEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewSMTPNotifier(SMTPConfig{Addr: server.Addr(), From: "tasks@example.com"}, 10)
+
+	assert.NoError(t, notifier.NotifyAssignment(domain.User{UserName: "bob"}, domain.Task{UserID: 1}))
+	notifier.Close()
+
+	assert.Empty(t, server.Messages())
+}
@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"sync"
+
+	domain "task-manager/Domain"
+)
+
+// StreamedEvent is a task event tagged with its position in the broker's
+// stream, which clients echo back to resume after a reconnect.
+type StreamedEvent struct {
+	Seq   uint64
+	Event domain.Event
+}
+
+// TaskEventBroker keeps the last few task events in memory and fans new ones
+// out to live subscribers such as SSE streams.
+type TaskEventBroker struct {
+	mu          sync.Mutex
+	capacity    int
+	buffer      []StreamedEvent
+	lastSeq     uint64
+	subscribers map[chan StreamedEvent]struct{}
+	closed      bool
+}
+
+func NewTaskEventBroker(capacity int) *TaskEventBroker {
+	return &TaskEventBroker{
+		capacity:    capacity,
+		subscribers: map[chan StreamedEvent]struct{}{},
+	}
+}
+
+func (b *TaskEventBroker) Publish(event domain.Event) {
+	if event.Task == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastSeq++
+	streamed := StreamedEvent{Seq: b.lastSeq, Event: event}
+	b.buffer = append(b.buffer, streamed)
+	if len(b.buffer) > b.capacity {
+		b.buffer = b.buffer[len(b.buffer)-b.capacity:]
+	}
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- streamed:
+		default:
+			// A subscriber that cannot keep up is dropped; it can reconnect
+			// and resume from the buffer.
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Subscribe returns the buffered events after lastSeq followed by a channel of
+// live events. A lastSeq of zero skips the backlog, and one newer than
+// anything the broker has seen (e.g. from before a restart) replays the whole
+// buffer. The returned cancel function must be called once the subscriber is
+// done.
+func (b *TaskEventBroker) Subscribe(lastSeq uint64) ([]StreamedEvent, <-chan StreamedEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []StreamedEvent
+	if lastSeq > b.lastSeq {
+		backlog = append(backlog, b.buffer...)
+	} else if lastSeq > 0 {
+		for _, streamed := range b.buffer {
+			if streamed.Seq > lastSeq {
+				backlog = append(backlog, streamed)
+			}
+		}
+	}
+
+	subscriber := make(chan StreamedEvent, b.capacity)
+	if b.closed {
+		close(subscriber)
+		return backlog, subscriber, func() {}
+	}
+	b.subscribers[subscriber] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[subscriber]; ok {
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return backlog, subscriber, cancel
+}
+
+// Close ends every live subscription, so that long-lived streams finish
+// during a graceful shutdown. Later subscribers get an already closed
+// channel.
+func (b *TaskEventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for subscriber := range b.subscribers {
+		delete(b.subscribers, subscriber)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskEventBroker_DeliversLiveTaskEvents(t *testing.T) {
+	broker := NewTaskEventBroker(10)
+	backlog, events, cancel := broker.Subscribe(0)
+	defer cancel()
+	assert.Empty(t, backlog)
+
+	broker.Publish(domain.NewUserEvent(domain.EventUserRegistered, domain.User{UserName: "bob"}))
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 1}))
+
+	select {
+	case streamed := <-events:
+		assert.Equal(t, uint64(1), streamed.Seq)
+		assert.Equal(t, domain.EventTaskCreated, streamed.Event.Type)
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+}
+
+func TestTaskEventBroker_ResumesFromLastSeq(t *testing.T) {
+	broker := NewTaskEventBroker(3)
+	for i := 1; i <= 5; i++ {
+		broker.Publish(domain.NewTaskEvent(domain.EventTaskUpdated, domain.Task{UserID: i}))
+	}
+
+	backlog, _, cancel := broker.Subscribe(3)
+	defer cancel()
+	require.Len(t, backlog, 2)
+	assert.Equal(t, uint64(4), backlog[0].Seq)
+	assert.Equal(t, uint64(5), backlog[1].Seq)
+
+	// only the last three events are buffered
+	backlog, _, cancel = broker.Subscribe(1)
+	defer cancel()
+	require.Len(t, backlog, 3)
+	assert.Equal(t, uint64(3), backlog[0].Seq)
+}
+
+func TestTaskEventBroker_UnknownSeqReplaysBuffer(t *testing.T) {
+	broker := NewTaskEventBroker(3)
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskDeleted, domain.Task{UserID: 1}))
+
+	backlog, _, cancel := broker.Subscribe(42)
+	defer cancel()
+	assert.Len(t, backlog, 1)
+}
+
+func TestTaskEventBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := NewTaskEventBroker(1)
+	_, events, cancel := broker.Subscribe(0)
+	defer cancel()
+
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 1}))
+	broker.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 2}))
+
+	<-events
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestTaskEventBroker_CloseEndsSubscriptions(t *testing.T) {
+	broker := NewTaskEventBroker(10)
+	_, events, cancel := broker.Subscribe(0)
+
+	broker.Close()
+	cancel()
+
+	_, ok := <-events
+	assert.False(t, ok)
+
+	_, late, lateCancel := broker.Subscribe(0)
+	defer lateCancel()
+	_, ok = <-late
+	assert.False(t, ok)
+}
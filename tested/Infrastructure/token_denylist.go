@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domain "task-manager/Domain"
+)
+
+type memoryTokenDenylist struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryTokenDenylist keeps revocations in this process only, for tests
+// and single-instance deployments.
+func NewMemoryTokenDenylist() domain.TokenDenylist {
+	return &memoryTokenDenylist{revoked: map[string]time.Time{}, now: time.Now}
+}
+
+func (d *memoryTokenDenylist) RevokeToken(_ context.Context, id string, until time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if until.After(d.revoked[id]) {
+		d.revoked[id] = until
+	}
+	return nil
+}
+
+func (d *memoryTokenDenylist) IsTokenRevoked(_ context.Context, ids ...string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	for _, id := range ids {
+		until, ok := d.revoked[id]
+		if !ok {
+			continue
+		}
+		if until.After(now) {
+			return true, nil
+		}
+		delete(d.revoked, id)
+	}
+	return false, nil
+}
@@ -0,0 +1,28 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryTokenDenylist(t *testing.T) {
+	denylist := NewMemoryTokenDenylist().(*memoryTokenDenylist)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	denylist.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, denylist.RevokeToken(ctx, "jti-1", now.Add(time.Minute)))
+	assert.NoError(t, denylist.RevokeToken(ctx, "jti-1", now.Add(time.Second)), "an earlier expiry does not shorten the entry")
+
+	revoked, err := denylist.IsTokenRevoked(ctx, "sid-1", "jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	now = now.Add(2 * time.Minute)
+	revoked, _ = denylist.IsTokenRevoked(ctx, "sid-1", "jti-1")
+	assert.False(t, revoked)
+	assert.Empty(t, denylist.revoked, "expired entries are dropped")
+}
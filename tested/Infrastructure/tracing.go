@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const ServiceName = "task-manager"
+
+type TracingConfig struct {
+	// Exporter is "none", "stdout" or "otlp". OTLP is sent over HTTP to
+	// OTEL_EXPORTER_OTLP_ENDPOINT (default localhost:4318).
+	Exporter    string
+	SampleRatio float64
+	// Stdout receives the spans of the stdout exporter.
+	Stdout io.Writer
+}
+
+// SetupTracing installs the global tracer provider and the W3C trace context
+// propagator, which the Gin middleware, Mongo monitor and webhook dispatcher
+// pick up. The returned function flushes buffered spans.
+func SetupTracing(ctx context.Context, config TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(config.Stdout))
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win over the defaults.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	// Callers look up their tracer with otel.Tracer on every span instead of
+	// keeping one: a tracer obtained before this call stays bound to the
+	// first provider ever installed.
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
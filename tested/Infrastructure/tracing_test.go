@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useInMemoryTracing installs a global tracer provider that records spans
+// synchronously, restoring the previous globals when the test ends.
+func useInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func TestSetupTracing_Exporters(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, err := SetupTracing(context.Background(), TracingConfig{Exporter: "jaeger"})
+	assert.Error(t, err)
+
+	shutdown, err := SetupTracing(context.Background(), TracingConfig{Exporter: "none"})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	var out bytes.Buffer
+	shutdown, err = SetupTracing(context.Background(), TracingConfig{Exporter: "stdout", SampleRatio: 1, Stdout: &out})
+	require.NoError(t, err)
+	_, span := otel.Tracer("test").Start(context.Background(), "stdout-span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, out.String(), `"Name":"stdout-span"`)
+	assert.Contains(t, out.String(), `"Value":"task-manager"`)
+}
+
+func TestWebhookDispatcher_Deliver_PropagatesTraceContext(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	traceparents := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	dispatcher := NewWebhookDispatcher(&memoryWebhookRepository{}, receiver.Client(), 1, time.Millisecond, 1, 1)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	err := dispatcher.Deliver(ctx, domain.Webhook{URL: receiver.URL}, domain.NewEvent(domain.EventTaskDeleted))
+	parent.End()
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	deliver := spans[0]
+	assert.Equal(t, "webhook.deliver", deliver.Name)
+	assert.Equal(t, parent.SpanContext().TraceID(), deliver.SpanContext.TraceID())
+	assert.Equal(t, codes.Error, deliver.Status.Code)
+	assert.Contains(t, <-traceparents, deliver.SpanContext.SpanID().String())
+}
+
+func TestRequestLogger_IncludesTraceID(t *testing.T) {
+	useInMemoryTracing(t)
+	var buf bytes.Buffer
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		ctx, span := otel.Tracer("test").Start(c.Request.Context(), "server")
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}, RequestLogger(NewLogger(&buf, slog.LevelInfo)))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Len(t, lines[0]["trace_id"], 32)
+}
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	domain "task-manager/Domain"
+)
+
+// userCacheSweepSize is how many entries the cache holds before a miss
+// first drops the expired ones.
+const userCacheSweepSize = 1024
+
+type cachedUser struct {
+	user      domain.User
+	err       error
+	expiresAt time.Time
+}
+
+// UserCache remembers the users the auth middleware looks up for ttl, so an
+// authenticated request does not cost a database round trip. Changes made
+// through any instance are seen at most ttl late; a ttl of zero turns the
+// cache off.
+type UserCache struct {
+	next domain.UserResolver
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu    sync.Mutex
+	users map[string]cachedUser
+}
+
+func NewUserCache(next domain.UserResolver, ttl time.Duration) *UserCache {
+	return &UserCache{
+		next:  next,
+		ttl:   ttl,
+		now:   time.Now,
+		users: map[string]cachedUser{},
+	}
+}
+
+// GetUserByUsername also caches ErrUserNotFound, so tokens of deleted users
+// don't hit the database either. Other errors are not cached.
+func (c *UserCache) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
+	if c.ttl <= 0 {
+		return c.next.GetUserByUsername(ctx, username)
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.users[username]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.user, cached.err
+	}
+
+	user, err := c.next.GetUserByUsername(ctx, username)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return user, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.users) >= userCacheSweepSize {
+		for name, entry := range c.users {
+			if !now.Before(entry.expiresAt) {
+				delete(c.users, name)
+			}
+		}
+	}
+	c.users[username] = cachedUser{user: user, err: err, expiresAt: now.Add(c.ttl)}
+	return user, err
+}
@@ -0,0 +1,68 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingUsers counts lookups of stubUsers.
+type countingUsers struct {
+	stubUsers
+	calls map[string]int
+}
+
+func (c *countingUsers) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
+	c.calls[username]++
+	return c.stubUsers.GetUserByUsername(ctx, username)
+}
+
+func TestUserCache_CachesUntilTheTTL(t *testing.T) {
+	users := &countingUsers{stubUsers: stubUsers{"bob": {UserName: "bob"}}, calls: map[string]int{}}
+	cache := NewUserCache(users, 5*time.Second)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 3 {
+		user, err := cache.GetUserByUsername(ctx, "bob")
+		assert.NoError(t, err)
+		assert.Equal(t, "bob", user.UserName)
+		_, err = cache.GetUserByUsername(ctx, "ghost")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	}
+	assert.Equal(t, 1, users.calls["bob"])
+	assert.Equal(t, 1, users.calls["ghost"], "deleted users are cached too")
+
+	users.stubUsers["bob"] = domain.User{UserName: "bob", Disabled: true}
+	now = now.Add(5 * time.Second)
+	user, _ := cache.GetUserByUsername(ctx, "bob")
+	assert.True(t, user.Disabled)
+	assert.Equal(t, 2, users.calls["bob"])
+}
+
+func TestUserCache_DoesNotCacheFailures(t *testing.T) {
+	users := &countingUsers{stubUsers: stubUsers{}, calls: map[string]int{}}
+	cache := NewUserCache(users, time.Minute)
+
+	for range 2 {
+		_, err := cache.GetUserByUsername(context.Background(), "broken")
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, domain.ErrUserNotFound))
+	}
+	assert.Equal(t, 2, users.calls["broken"])
+}
+
+func TestUserCache_ZeroTTLLooksUpEveryTime(t *testing.T) {
+	users := &countingUsers{stubUsers: stubUsers{"bob": {UserName: "bob"}}, calls: map[string]int{}}
+	cache := NewUserCache(users, 0)
+
+	cache.GetUserByUsername(context.Background(), "bob")
+	cache.GetUserByUsername(context.Background(), "bob")
+	assert.Equal(t, 2, users.calls["bob"])
+}
@@ -0,0 +1,255 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookEventIDHeader   = "X-Webhook-Event-ID"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher queues deliveries on Publish; Run sends them and must be
+// running, normally as one of the server's Workers, for any to go out.
+type WebhookDispatcher interface {
+	domain.EventPublisher
+	Deliver(ctx context.Context, webhook domain.Webhook, event domain.Event) error
+	Run(ctx context.Context) error
+}
+
+var (
+	errWebhookQueueFull = errors.New("delivery queue full")
+	errWebhookShutdown  = errors.New("not delivered before shutdown")
+)
+
+// deadLetterTimeout bounds storing a dead letter, which also happens while
+// shutting down, after the worker's context is gone.
+const deadLetterTimeout = 5 * time.Second
+
+type webhookJob struct {
+	webhook domain.Webhook
+	event   domain.Event
+}
+
+type webhookDispatcherImpl struct {
+	webhookRepository domain.WebhookRepository
+	client            *http.Client
+	maxAttempts       int
+	baseDelay         time.Duration
+	concurrency       int
+	queue             chan webhookJob
+
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewWebhookDispatcher retries each delivery up to maxAttempts times, doubling
+// the wait after every failure starting from baseDelay, before the delivery
+// is moved to the dead-letter list. Run sends at most concurrency deliveries
+// at once from a queue of queueSize; deliveries that do not fit, or are still
+// queued or retrying at shutdown, are dead-lettered instead.
+func NewWebhookDispatcher(
+	webhookRepository domain.WebhookRepository,
+	client *http.Client,
+	maxAttempts int,
+	baseDelay time.Duration,
+	concurrency int,
+	queueSize int,
+) WebhookDispatcher {
+	return &webhookDispatcherImpl{
+		webhookRepository: webhookRepository,
+		client:            client,
+		maxAttempts:       maxAttempts,
+		baseDelay:         baseDelay,
+		concurrency:       concurrency,
+		queue:             make(chan webhookJob, queueSize),
+	}
+}
+
+// Publish queues a delivery per subscribed webhook without waiting for any,
+// so the request that caused the event is not held up.
+func (d *webhookDispatcherImpl) Publish(event domain.Event) {
+	ctx := context.Background()
+	webhooks, err := d.webhookRepository.GetAllWebhooks(ctx)
+	if err != nil {
+		slog.Error("failed to load webhooks", "error", err, "event_id", event.ID)
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+		job := webhookJob{webhook: webhook, event: event}
+		if d.stopped {
+			d.deadLetter(job, 0, errWebhookShutdown)
+			continue
+		}
+		select {
+		case d.queue <- job:
+		default:
+			d.deadLetter(job, 0, errWebhookQueueFull)
+		}
+	}
+}
+
+// Run sends queued deliveries until ctx is done, then dead-letters whatever
+// is still queued and returns once the deliveries in flight have given up.
+func (d *webhookDispatcherImpl) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range d.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-d.queue:
+					if ctx.Err() != nil {
+						d.deadLetter(job, 0, errWebhookShutdown)
+						return
+					}
+					d.deliverWithRetry(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+	for {
+		select {
+		case job := <-d.queue:
+			d.deadLetter(job, 0, errWebhookShutdown)
+		default:
+			return nil
+		}
+	}
+}
+
+// Deliver sends one attempt in its own client span and passes the trace on
+// to the receiver in the traceparent header.
+func (d *webhookDispatcherImpl) Deliver(ctx context.Context, webhook domain.Webhook, event domain.Event) (err error) {
+	ctx, span := otel.Tracer("task-manager/Infrastructure").Start(ctx, "webhook.deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("webhook.id", webhook.ID.Hex()),
+			attribute.String("event.type", event.Type),
+			attribute.String("event.id", event.ID),
+		))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookEventIDHeader, event.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(webhook.Secret, timestamp, body))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *webhookDispatcherImpl) deliverWithRetry(ctx context.Context, job webhookJob) {
+	var err error
+	delay := d.baseDelay
+	attempt := 1
+	for ; attempt <= d.maxAttempts; attempt++ {
+		if err = d.Deliver(ctx, job.webhook, job.event); err == nil {
+			return
+		}
+		if attempt == d.maxAttempts {
+			break
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.deadLetter(job, attempt, fmt.Errorf("%w: %w", errWebhookShutdown, err))
+			return
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+	d.deadLetter(job, attempt, err)
+}
+
+func (d *webhookDispatcherImpl) deadLetter(job webhookJob, attempts int, err error) {
+	deadLetter := domain.WebhookDelivery{
+		WebhookID: job.webhook.ID,
+		Event:     job.event,
+		Attempts:  attempts,
+		LastError: err.Error(),
+		FailedAt:  time.Now().UTC(),
+	}
+	slog.Warn("webhook delivery dead-lettered",
+		"webhook_id", job.webhook.ID.Hex(), "event_id", job.event.ID, "attempts", attempts, "error", err)
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterTimeout)
+	defer cancel()
+	if err := d.webhookRepository.AddDeadLetter(ctx, deadLetter); err != nil {
+		slog.Error("failed to store webhook dead letter", "error", err, "webhook_id", job.webhook.ID.Hex())
+	}
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 of "<timestamp>.<body>",
+// which receivers recompute to verify the X-Webhook-Signature header.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,272 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryWebhookRepository is an in-memory domain.WebhookRepository for tests.
+type memoryWebhookRepository struct {
+	mu          sync.Mutex
+	webhooks    []domain.Webhook
+	deadLetters []domain.WebhookDelivery
+}
+
+func (m *memoryWebhookRepository) CreateWebhook(_ context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook.ID = primitive.NewObjectID()
+	m.webhooks = append(m.webhooks, webhook)
+	return webhook, nil
+}
+
+func (m *memoryWebhookRepository) GetAllWebhooks(_ context.Context) ([]domain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Webhook{}, m.webhooks...), nil
+}
+
+func (m *memoryWebhookRepository) GetWebhookByID(_ context.Context, id string) (domain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, webhook := range m.webhooks {
+		if webhook.ID.Hex() == id {
+			return webhook, nil
+		}
+	}
+	return domain.Webhook{}, domain.ErrWebhookNotFound
+}
+
+func (m *memoryWebhookRepository) DeleteWebhook(_ context.Context, id string) error {
+	return nil
+}
+
+func (m *memoryWebhookRepository) AddDeadLetter(_ context.Context, delivery domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadLetters = append(m.deadLetters, delivery)
+	return nil
+}
+
+func (m *memoryWebhookRepository) GetDeadLetters(_ context.Context) ([]domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.WebhookDelivery{}, m.deadLetters...), nil
+}
+
+func (m *memoryWebhookRepository) GetDeadLetterByID(_ context.Context, id string) (domain.WebhookDelivery, error) {
+	return domain.WebhookDelivery{}, domain.ErrDeadLetterNotFound
+}
+
+func (m *memoryWebhookRepository) DeleteDeadLetter(_ context.Context, id string) error {
+	return nil
+}
+
+// runDispatcher runs d for the rest of the test and returns a function that
+// stops it and waits for Run to return.
+func runDispatcher(t *testing.T, d WebhookDispatcher) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	stop = func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{}
+	_, _ = repo.CreateWebhook(context.Background(), domain.Webhook{URL: receiver.URL, Secret: "s3cret", Events: []string{domain.EventTaskCreated}})
+	dispatcher := NewWebhookDispatcher(repo, receiver.Client(), 3, time.Millisecond, 1, 10)
+	runDispatcher(t, dispatcher)
+
+	event := domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{UserID: 4, Title: "t"})
+	dispatcher.Publish(event)
+
+	select {
+	case req := <-received:
+		body := <-bodies
+		timestamp := req.Header.Get(WebhookTimestampHeader)
+		assert.Equal(t, domain.EventTaskCreated, req.Header.Get(WebhookEventHeader))
+		assert.Equal(t, event.ID, req.Header.Get(WebhookEventIDHeader))
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, "sha256="+SignWebhookPayload("s3cret", timestamp, body), req.Header.Get(WebhookSignatureHeader))
+
+		var got domain.Event
+		assert.NoError(t, json.Unmarshal(body, &got))
+		assert.Equal(t, 4, got.Task.UserID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestWebhookDispatcher_SkipsUnsubscribedEvents(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{}
+	_, _ = repo.CreateWebhook(context.Background(), domain.Webhook{URL: receiver.URL, Secret: "s", Events: []string{domain.EventUserPromoted}})
+	dispatcher := NewWebhookDispatcher(repo, receiver.Client(), 1, time.Millisecond, 1, 10)
+	runDispatcher(t, dispatcher)
+
+	dispatcher.Publish(domain.NewEvent(domain.EventTaskDeleted))
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 0, calls)
+}
+
+func TestWebhookDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{}
+	_, _ = repo.CreateWebhook(context.Background(), domain.Webhook{URL: receiver.URL, Secret: "s"})
+	dispatcher := NewWebhookDispatcher(repo, receiver.Client(), 3, time.Millisecond, 1, 10)
+	runDispatcher(t, dispatcher)
+
+	dispatcher.Publish(domain.NewEvent(domain.EventUserRegistered))
+
+	assert.Eventually(t, func() bool {
+		deadLetters, _ := repo.GetDeadLetters(context.Background())
+		return len(deadLetters) == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	deadLetters, _ := repo.GetDeadLetters(context.Background())
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.True(t, strings.Contains(deadLetters[0].LastError, "500"))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWebhookDispatcher_RetrySucceedsAfterFailure(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{}
+	_, _ = repo.CreateWebhook(context.Background(), domain.Webhook{URL: receiver.URL, Secret: "s"})
+	dispatcher := NewWebhookDispatcher(repo, receiver.Client(), 3, time.Millisecond, 1, 10)
+	runDispatcher(t, dispatcher)
+
+	dispatcher.Publish(domain.NewEvent(domain.EventUserPromoted))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts == 2
+	}, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	deadLetters, _ := repo.GetDeadLetters(context.Background())
+	assert.Empty(t, deadLetters)
+}
+
+func TestWebhookDispatcher_DeadLettersWhenTheQueueIsFull(t *testing.T) {
+	repo := &memoryWebhookRepository{}
+	_, _ = repo.CreateWebhook(context.Background(), domain.Webhook{URL: "http://127.0.0.1:0", Secret: "s"})
+	dispatcher := NewWebhookDispatcher(repo, http.DefaultClient, 1, time.Millisecond, 1, 1)
+
+	// Not running, so the first delivery fills the queue.
+	dispatcher.Publish(domain.NewEvent(domain.EventUserRegistered))
+	dispatcher.Publish(domain.NewEvent(domain.EventUserPromoted))
+
+	deadLetters, _ := repo.GetDeadLetters(context.Background())
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, domain.EventUserPromoted, deadLetters[0].Event.Type)
+	assert.Equal(t, 0, deadLetters[0].Attempts)
+	assert.Equal(t, "delivery queue full", deadLetters[0].LastError)
+}
+
+func TestWebhookDispatcher_ShutdownDeadLettersPendingDeliveries(t *testing.T) {
+	attempted := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempted <- struct{}{}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{}
+	_, _ = repo.CreateWebhook(context.Background(), domain.Webhook{URL: receiver.URL, Secret: "s"})
+	dispatcher := NewWebhookDispatcher(repo, receiver.Client(), 3, time.Hour, 1, 10)
+	stop := runDispatcher(t, dispatcher)
+
+	dispatcher.Publish(domain.NewEvent(domain.EventUserRegistered))
+	<-attempted
+	// Queued behind the first, which now waits an hour before retrying.
+	dispatcher.Publish(domain.NewEvent(domain.EventUserPromoted))
+
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run waited out the backoff")
+	}
+
+	deadLetters, _ := repo.GetDeadLetters(context.Background())
+	if assert.Len(t, deadLetters, 2) {
+		assert.Equal(t, 1, deadLetters[0].Attempts)
+		assert.Contains(t, deadLetters[0].LastError, "not delivered before shutdown")
+		assert.Equal(t, domain.EventUserPromoted, deadLetters[1].Event.Type)
+		assert.Equal(t, 0, deadLetters[1].Attempts)
+	}
+
+	// Publishing after Run returned goes straight to the dead letters.
+	dispatcher.Publish(domain.NewEvent(domain.EventTaskDeleted))
+	deadLetters, _ = repo.GetDeadLetters(context.Background())
+	assert.Len(t, deadLetters, 3)
+}
@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Workers runs the server's long-lived background goroutines and reports
+// whether they are all still running, for readiness checks.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped map[string]error
+	order   []string
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: map[string]error{},
+	}
+}
+
+// Go starts run in the background. run must return once its context is
+// cancelled; returning earlier marks the worker as stopped.
+func (w *Workers) Go(name string, run func(ctx context.Context) error) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		err := run(w.ctx)
+		if w.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("exited")
+		}
+		slog.Error("background worker stopped", "worker", name, "error", err)
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.stopped[name] = err
+		w.order = append(w.order, name)
+	}()
+}
+
+// Check returns an error naming the first worker that stopped unexpectedly.
+func (w *Workers) Check(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.order) == 0 {
+		return nil
+	}
+	name := w.order[0]
+	return fmt.Errorf("worker %s stopped: %w", name, w.stopped[name])
+}
+
+// Stop cancels every worker and waits for them to return.
+func (w *Workers) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkers_CheckReportsStoppedWorker(t *testing.T) {
+	workers := NewWorkers()
+	failed := make(chan struct{})
+
+	workers.Go("steady", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	assert.NoError(t, workers.Check(context.Background()))
+
+	workers.Go("grpc", func(ctx context.Context) error {
+		defer close(failed)
+		return errors.New("address in use")
+	})
+	<-failed
+	assert.Eventually(t, func() bool { return workers.Check(context.Background()) != nil }, time.Second, 5*time.Millisecond)
+	assert.EqualError(t, workers.Check(context.Background()), "worker grpc stopped: address in use")
+
+	workers.Stop()
+}
+
+func TestWorkers_StopWaitsAndIsNotAFailure(t *testing.T) {
+	workers := NewWorkers()
+	finished := false
+
+	workers.Go("reminders", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+		return ctx.Err()
+	})
+	workers.Stop()
+
+	assert.True(t, finished)
+	assert.NoError(t, workers.Check(context.Background()))
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommentRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewCommentRepository(collection *mongo.Collection) domain.CommentRepository {
+	return &CommentRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *CommentRepositoryImpl) CreateComment(ctx context.Context, comment domain.Comment) (domain.Comment, error) {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, comment); err != nil {
+		return domain.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (r *CommentRepositoryImpl) GetCommentsByTaskID(ctx context.Context, taskID int) ([]domain.Comment, error) {
+	comments := []domain.Comment{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID}, opts)
+	if err != nil {
+		return comments, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &comments); err != nil {
+		return comments, err
+	}
+	return comments, nil
+}
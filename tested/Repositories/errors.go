@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	infrastructure "task-manager/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// notFound maps a failed lookup to notFoundErr. Failures other than a
+// missing document are logged, since callers cannot tell them apart.
+func notFound(ctx context.Context, err, notFoundErr error) error {
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		infrastructure.LoggerFrom(ctx).Error("mongo lookup failed", "error", err)
+	}
+	return notFoundErr
+}
@@ -0,0 +1,394 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Observer receives the latency of every repository call, labelled by
+// repository and method name.
+type Observer func(repository, method string, elapsed time.Duration)
+
+// instrument is shared by the wrappers below: each call gets a span named
+// after the repository interface and its latency is passed to observe.
+type instrument struct {
+	repository string
+	span       string
+	observe    Observer
+}
+
+// tracerName marks the spans as coming from the Mongo repositories.
+const tracerName = "task-manager/Repositories"
+
+func (i instrument) start(ctx context.Context, method string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := otel.Tracer(tracerName).Start(ctx, i.span+"."+method, trace.WithAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("repository", i.repository),
+	))
+	return ctx, func(err error) {
+		i.observe(i.repository, method, time.Since(start))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+type instrumentedTaskRepository struct {
+	instrument
+	next domain.TaskRepository
+}
+
+// InstrumentTaskRepository times and traces every call to next.
+func InstrumentTaskRepository(next domain.TaskRepository, observe Observer) domain.TaskRepository {
+	return &instrumentedTaskRepository{
+		instrument: instrument{repository: "tasks", span: "TaskRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedTaskRepository) GetAllTasks(ctx context.Context) (result []domain.Task, err error) {
+	ctx, done := r.start(ctx, "GetAllTasks")
+	defer func() { done(err) }()
+	return r.next.GetAllTasks(ctx)
+}
+
+func (r *instrumentedTaskRepository) GetTaskByID(ctx context.Context, userID int) (result domain.Task, err error) {
+	ctx, done := r.start(ctx, "GetTaskByID")
+	defer func() { done(err) }()
+	return r.next.GetTaskByID(ctx, userID)
+}
+
+func (r *instrumentedTaskRepository) CreateTask(ctx context.Context, task domain.Task) (result domain.Task, err error) {
+	ctx, done := r.start(ctx, "CreateTask")
+	defer func() { done(err) }()
+	return r.next.CreateTask(ctx, task)
+}
+
+func (r *instrumentedTaskRepository) UpdateTask(ctx context.Context, userID int, task domain.Task) (result domain.Task, err error) {
+	ctx, done := r.start(ctx, "UpdateTask")
+	defer func() { done(err) }()
+	return r.next.UpdateTask(ctx, userID, task)
+}
+
+func (r *instrumentedTaskRepository) DeleteTask(ctx context.Context, userID int) (err error) {
+	ctx, done := r.start(ctx, "DeleteTask")
+	defer func() { done(err) }()
+	return r.next.DeleteTask(ctx, userID)
+}
+
+type instrumentedUserRepository struct {
+	instrument
+	next domain.UserRepository
+}
+
+// InstrumentUserRepository times and traces every call to next.
+func InstrumentUserRepository(next domain.UserRepository, observe Observer) domain.UserRepository {
+	return &instrumentedUserRepository{
+		instrument: instrument{repository: "users", span: "UserRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedUserRepository) RegisterUser(ctx context.Context, username, password, email string) (err error) {
+	ctx, done := r.start(ctx, "RegisterUser")
+	defer func() { done(err) }()
+	return r.next.RegisterUser(ctx, username, password, email)
+}
+
+func (r *instrumentedUserRepository) AuthenticateUser(ctx context.Context, username, password string) (result domain.User, err error) {
+	ctx, done := r.start(ctx, "AuthenticateUser")
+	defer func() { done(err) }()
+	return r.next.AuthenticateUser(ctx, username, password)
+}
+
+func (r *instrumentedUserRepository) GetUserByUsername(ctx context.Context, username string) (result domain.User, err error) {
+	ctx, done := r.start(ctx, "GetUserByUsername")
+	defer func() { done(err) }()
+	return r.next.GetUserByUsername(ctx, username)
+}
+
+func (r *instrumentedUserRepository) GetAllUsers(ctx context.Context) (result []domain.User, err error) {
+	ctx, done := r.start(ctx, "GetAllUsers")
+	defer func() { done(err) }()
+	return r.next.GetAllUsers(ctx)
+}
+
+func (r *instrumentedUserRepository) PromoteUser(ctx context.Context, username string) (err error) {
+	ctx, done := r.start(ctx, "PromoteUser")
+	defer func() { done(err) }()
+	return r.next.PromoteUser(ctx, username)
+}
+
+func (r *instrumentedUserRepository) SetUserRole(ctx context.Context, username, role string) (err error) {
+	ctx, done := r.start(ctx, "SetUserRole")
+	defer func() { done(err) }()
+	return r.next.SetUserRole(ctx, username, role)
+}
+
+func (r *instrumentedUserRepository) CountUsersWithRole(ctx context.Context, role string) (result int64, err error) {
+	ctx, done := r.start(ctx, "CountUsersWithRole")
+	defer func() { done(err) }()
+	return r.next.CountUsersWithRole(ctx, role)
+}
+
+func (r *instrumentedUserRepository) CountEnabledUsersWithRole(ctx context.Context, role string) (result int64, err error) {
+	ctx, done := r.start(ctx, "CountEnabledUsersWithRole")
+	defer func() { done(err) }()
+	return r.next.CountEnabledUsersWithRole(ctx, role)
+}
+
+func (r *instrumentedUserRepository) ListUsers(ctx context.Context, query domain.UserQuery) (result []domain.User, total int64, err error) {
+	ctx, done := r.start(ctx, "ListUsers")
+	defer func() { done(err) }()
+	return r.next.ListUsers(ctx, query)
+}
+
+func (r *instrumentedUserRepository) SetUserDisabled(ctx context.Context, username string, disabled bool) (err error) {
+	ctx, done := r.start(ctx, "SetUserDisabled")
+	defer func() { done(err) }()
+	return r.next.SetUserDisabled(ctx, username, disabled)
+}
+
+func (r *instrumentedUserRepository) DeleteUser(ctx context.Context, username string) (err error) {
+	ctx, done := r.start(ctx, "DeleteUser")
+	defer func() { done(err) }()
+	return r.next.DeleteUser(ctx, username)
+}
+
+type instrumentedCommentRepository struct {
+	instrument
+	next domain.CommentRepository
+}
+
+// InstrumentCommentRepository times and traces every call to next.
+func InstrumentCommentRepository(next domain.CommentRepository, observe Observer) domain.CommentRepository {
+	return &instrumentedCommentRepository{
+		instrument: instrument{repository: "comments", span: "CommentRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedCommentRepository) CreateComment(ctx context.Context, comment domain.Comment) (result domain.Comment, err error) {
+	ctx, done := r.start(ctx, "CreateComment")
+	defer func() { done(err) }()
+	return r.next.CreateComment(ctx, comment)
+}
+
+func (r *instrumentedCommentRepository) GetCommentsByTaskID(ctx context.Context, taskID int) (result []domain.Comment, err error) {
+	ctx, done := r.start(ctx, "GetCommentsByTaskID")
+	defer func() { done(err) }()
+	return r.next.GetCommentsByTaskID(ctx, taskID)
+}
+
+type instrumentedWebhookRepository struct {
+	instrument
+	next domain.WebhookRepository
+}
+
+// InstrumentWebhookRepository times and traces every call to next.
+func InstrumentWebhookRepository(next domain.WebhookRepository, observe Observer) domain.WebhookRepository {
+	return &instrumentedWebhookRepository{
+		instrument: instrument{repository: "webhooks", span: "WebhookRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedWebhookRepository) CreateWebhook(ctx context.Context, webhook domain.Webhook) (result domain.Webhook, err error) {
+	ctx, done := r.start(ctx, "CreateWebhook")
+	defer func() { done(err) }()
+	return r.next.CreateWebhook(ctx, webhook)
+}
+
+func (r *instrumentedWebhookRepository) GetAllWebhooks(ctx context.Context) (result []domain.Webhook, err error) {
+	ctx, done := r.start(ctx, "GetAllWebhooks")
+	defer func() { done(err) }()
+	return r.next.GetAllWebhooks(ctx)
+}
+
+func (r *instrumentedWebhookRepository) GetWebhookByID(ctx context.Context, id string) (result domain.Webhook, err error) {
+	ctx, done := r.start(ctx, "GetWebhookByID")
+	defer func() { done(err) }()
+	return r.next.GetWebhookByID(ctx, id)
+}
+
+func (r *instrumentedWebhookRepository) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, done := r.start(ctx, "DeleteWebhook")
+	defer func() { done(err) }()
+	return r.next.DeleteWebhook(ctx, id)
+}
+
+func (r *instrumentedWebhookRepository) AddDeadLetter(ctx context.Context, delivery domain.WebhookDelivery) (err error) {
+	ctx, done := r.start(ctx, "AddDeadLetter")
+	defer func() { done(err) }()
+	return r.next.AddDeadLetter(ctx, delivery)
+}
+
+func (r *instrumentedWebhookRepository) GetDeadLetters(ctx context.Context) (result []domain.WebhookDelivery, err error) {
+	ctx, done := r.start(ctx, "GetDeadLetters")
+	defer func() { done(err) }()
+	return r.next.GetDeadLetters(ctx)
+}
+
+func (r *instrumentedWebhookRepository) GetDeadLetterByID(ctx context.Context, id string) (result domain.WebhookDelivery, err error) {
+	ctx, done := r.start(ctx, "GetDeadLetterByID")
+	defer func() { done(err) }()
+	return r.next.GetDeadLetterByID(ctx, id)
+}
+
+func (r *instrumentedWebhookRepository) DeleteDeadLetter(ctx context.Context, id string) (err error) {
+	ctx, done := r.start(ctx, "DeleteDeadLetter")
+	defer func() { done(err) }()
+	return r.next.DeleteDeadLetter(ctx, id)
+}
+
+type instrumentedLoginAttemptRepository struct {
+	instrument
+	next domain.LoginAttemptRepository
+}
+
+// InstrumentLoginAttemptRepository times and traces every call to next.
+func InstrumentLoginAttemptRepository(next domain.LoginAttemptRepository, observe Observer) domain.LoginAttemptRepository {
+	return &instrumentedLoginAttemptRepository{
+		instrument: instrument{repository: "login_attempts", span: "LoginAttemptRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedLoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (result domain.LoginAttempt, err error) {
+	ctx, done := r.start(ctx, "GetLoginAttempt")
+	defer func() { done(err) }()
+	return r.next.GetLoginAttempt(ctx, key)
+}
+
+func (r *instrumentedLoginAttemptRepository) RecordFailedLogin(ctx context.Context, key string, now time.Time, window time.Duration) (result domain.LoginAttempt, err error) {
+	ctx, done := r.start(ctx, "RecordFailedLogin")
+	defer func() { done(err) }()
+	return r.next.RecordFailedLogin(ctx, key, now, window)
+}
+
+func (r *instrumentedLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) (err error) {
+	ctx, done := r.start(ctx, "LockLogin")
+	defer func() { done(err) }()
+	return r.next.LockLogin(ctx, key, until)
+}
+
+func (r *instrumentedLoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) (err error) {
+	ctx, done := r.start(ctx, "ClearLoginAttempts")
+	defer func() { done(err) }()
+	return r.next.ClearLoginAttempts(ctx, key)
+}
+
+type instrumentedRefreshTokenRepository struct {
+	instrument
+	next domain.RefreshTokenRepository
+}
+
+// InstrumentRefreshTokenRepository times and traces every call to next.
+func InstrumentRefreshTokenRepository(next domain.RefreshTokenRepository, observe Observer) domain.RefreshTokenRepository {
+	return &instrumentedRefreshTokenRepository{
+		instrument: instrument{repository: "refresh_tokens", span: "RefreshTokenRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) (err error) {
+	ctx, done := r.start(ctx, "CreateRefreshToken")
+	defer func() { done(err) }()
+	return r.next.CreateRefreshToken(ctx, token)
+}
+
+func (r *instrumentedRefreshTokenRepository) UseRefreshToken(ctx context.Context, id string, now time.Time) (result domain.RefreshToken, err error) {
+	ctx, done := r.start(ctx, "UseRefreshToken")
+	defer func() { done(err) }()
+	return r.next.UseRefreshToken(ctx, id, now)
+}
+
+func (r *instrumentedRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, family string) (err error) {
+	ctx, done := r.start(ctx, "RevokeRefreshTokenFamily")
+	defer func() { done(err) }()
+	return r.next.RevokeRefreshTokenFamily(ctx, family)
+}
+
+func (r *instrumentedRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, username string) (err error) {
+	ctx, done := r.start(ctx, "RevokeUserRefreshTokens")
+	defer func() { done(err) }()
+	return r.next.RevokeUserRefreshTokens(ctx, username)
+}
+
+type instrumentedTokenDenylist struct {
+	instrument
+	next domain.TokenDenylist
+}
+
+// InstrumentTokenDenylist times and traces every call to next.
+func InstrumentTokenDenylist(next domain.TokenDenylist, observe Observer) domain.TokenDenylist {
+	return &instrumentedTokenDenylist{
+		instrument: instrument{repository: "revoked_tokens", span: "TokenDenylist", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedTokenDenylist) RevokeToken(ctx context.Context, id string, until time.Time) (err error) {
+	ctx, done := r.start(ctx, "RevokeToken")
+	defer func() { done(err) }()
+	return r.next.RevokeToken(ctx, id, until)
+}
+
+func (r *instrumentedTokenDenylist) IsTokenRevoked(ctx context.Context, ids ...string) (result bool, err error) {
+	ctx, done := r.start(ctx, "IsTokenRevoked")
+	defer func() { done(err) }()
+	return r.next.IsTokenRevoked(ctx, ids...)
+}
+
+type instrumentedRoleRepository struct {
+	instrument
+	next domain.RoleRepository
+}
+
+// InstrumentRoleRepository times and traces every call to next.
+func InstrumentRoleRepository(next domain.RoleRepository, observe Observer) domain.RoleRepository {
+	return &instrumentedRoleRepository{
+		instrument: instrument{repository: "roles", span: "RoleRepository", observe: observe},
+		next:       next,
+	}
+}
+
+func (r *instrumentedRoleRepository) CreateRole(ctx context.Context, role domain.Role) (err error) {
+	ctx, done := r.start(ctx, "CreateRole")
+	defer func() { done(err) }()
+	return r.next.CreateRole(ctx, role)
+}
+
+func (r *instrumentedRoleRepository) GetRole(ctx context.Context, name string) (result domain.Role, err error) {
+	ctx, done := r.start(ctx, "GetRole")
+	defer func() { done(err) }()
+	return r.next.GetRole(ctx, name)
+}
+
+func (r *instrumentedRoleRepository) GetAllRoles(ctx context.Context) (result []domain.Role, err error) {
+	ctx, done := r.start(ctx, "GetAllRoles")
+	defer func() { done(err) }()
+	return r.next.GetAllRoles(ctx)
+}
+
+func (r *instrumentedRoleRepository) UpdateRole(ctx context.Context, role domain.Role) (err error) {
+	ctx, done := r.start(ctx, "UpdateRole")
+	defer func() { done(err) }()
+	return r.next.UpdateRole(ctx, role)
+}
+
+func (r *instrumentedRoleRepository) DeleteRole(ctx context.Context, name string) (err error) {
+	ctx, done := r.start(ctx, "DeleteRole")
+	defer func() { done(err) }()
+	return r.next.DeleteRole(ctx, name)
+}
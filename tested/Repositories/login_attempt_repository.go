@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptRepositoryImpl keeps an expires_at on every document so the
+// TTL index drops counters once neither the window nor a lockout applies.
+type LoginAttemptRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewLoginAttemptRepository(collection *mongo.Collection) domain.LoginAttemptRepository {
+	return &LoginAttemptRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *LoginAttemptRepositoryImpl) GetLoginAttempt(ctx context.Context, key string) (domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	err := r.collection.FindOne(ctx, bson.M{"key": key}).Decode(&attempt)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.LoginAttempt{Key: key}, nil
+	}
+	return attempt, err
+}
+
+func (r *LoginAttemptRepositoryImpl) RecordFailedLogin(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttempt, error) {
+	// A pipeline update lets the window check and the increment happen in
+	// one atomic step.
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"key": key,
+			"failures": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$last_failure", now.Add(-window)}},
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+				1,
+			}},
+			"last_failure": now,
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"expires_at": bson.M{"$max": bson.A{now.Add(window), "$locked_until"}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt domain.LoginAttempt
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"key": key}, update, opts).Decode(&attempt)
+	return attempt, err
+}
+
+func (r *LoginAttemptRepositoryImpl) LockLogin(ctx context.Context, key string, until time.Time) error {
+	update := bson.M{
+		"$set": bson.M{"locked_until": until},
+		"$max": bson.M{"expires_at": until},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"key": key}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *LoginAttemptRepositoryImpl) ClearLoginAttempts(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"key": key})
+	return err
+}
@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is one schema change. Migrations are applied in Version order
+// and recorded in the schema_migrations collection, so each runs once.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrations must only ever be appended to.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "unique index on users.user_name",
+		Up: createIndex("users", mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}),
+	},
+	{
+		Version:     2,
+		Description: "index on tasks.user_id",
+		Up:          createIndex("tasks", mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}}),
+	},
+	{
+		Version:     3,
+		Description: "index on comments.task_id and created_at",
+		Up: createIndex("comments", mongo.IndexModel{
+			Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}},
+		}),
+	},
+	{
+		Version:     4,
+		Description: "unique index on login_attempts.key",
+		Up: createIndex("login_attempts", mongo.IndexModel{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}),
+	},
+	{
+		Version:     5,
+		Description: "TTL index on login_attempts.expires_at",
+		Up: createIndex("login_attempts", mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		}),
+	},
+	{
+		Version:     6,
+		Description: "index on refresh_tokens.family",
+		Up:          createIndex("refresh_tokens", mongo.IndexModel{Keys: bson.D{{Key: "family", Value: 1}}}),
+	},
+	{
+		Version:     7,
+		Description: "TTL index on refresh_tokens.expires_at",
+		Up: createIndex("refresh_tokens", mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		}),
+	},
+	{
+		Version:     8,
+		Description: "TTL index on revoked_tokens.expires_at",
+		Up: createIndex("revoked_tokens", mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		}),
+	},
+	{
+		Version:     9,
+		Description: "index users.role for role assignment checks",
+		Up: createIndex("users", mongo.IndexModel{
+			Keys: bson.D{{Key: "role", Value: 1}},
+		}),
+	},
+	{
+		Version:     10,
+		Description: "index on refresh_tokens.username",
+		Up:          createIndex("refresh_tokens", mongo.IndexModel{Keys: bson.D{{Key: "username", Value: 1}}}),
+	},
+}
+
+func createIndex(collection string, index mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateOne(ctx, index)
+		return err
+	}
+}
+
+type Migrator struct {
+	db         *mongo.Database
+	applied    *mongo.Collection
+	migrations []Migration
+}
+
+func NewMigrator(db *mongo.Database, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		applied:    db.Collection("schema_migrations"),
+		migrations: migrations,
+	}
+}
+
+// Migrate applies every pending migration in order and stops at the first
+// failure.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		if err := migration.Up(ctx, m.db); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		_, err := m.applied.InsertOne(ctx, appliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now().UTC(),
+		})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	cursor, err := m.applied.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var applied []appliedMigration
+	if err := cursor.All(ctx, &applied); err != nil {
+		return nil, err
+	}
+
+	done := map[int]bool{}
+	for _, migration := range applied {
+		done[migration.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Check fails while any migration is pending, for readiness checks.
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migration(s) pending", len(pending))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RefreshTokenRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewRefreshTokenRepository(collection *mongo.Collection) domain.RefreshTokenRepository {
+	return &RefreshTokenRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *RefreshTokenRepositoryImpl) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *RefreshTokenRepositoryImpl) UseRefreshToken(ctx context.Context, id string, now time.Time) (domain.RefreshToken, error) {
+	// Only the first use sets used_at, and the document comes back as it
+	// was, so two concurrent refreshes cannot both see it unused.
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"used_at": bson.M{"$ifNull": bson.A{"$used_at", now}}}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var token domain.RefreshToken
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&token)
+	if err != nil {
+		return domain.RefreshToken{}, notFound(ctx, err, domain.ErrInvalidRefreshToken)
+	}
+	return token, nil
+}
+
+func (r *RefreshTokenRepositoryImpl) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"family": family}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (r *RefreshTokenRepositoryImpl) RevokeUserRefreshTokens(ctx context.Context, username string) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"username": username}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RoleRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewRoleRepository(collection *mongo.Collection) domain.RoleRepository {
+	return &RoleRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *RoleRepositoryImpl) CreateRole(ctx context.Context, role domain.Role) error {
+	_, err := r.collection.InsertOne(ctx, role)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrRoleExists
+	}
+	return err
+}
+
+func (r *RoleRepositoryImpl) GetRole(ctx context.Context, name string) (domain.Role, error) {
+	var role domain.Role
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&role)
+	if err != nil {
+		return domain.Role{}, notFound(ctx, err, domain.ErrRoleNotFound)
+	}
+	return role, nil
+}
+
+func (r *RoleRepositoryImpl) GetAllRoles(ctx context.Context) ([]domain.Role, error) {
+	roles := []domain.Role{}
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return roles, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &roles); err != nil {
+		return roles, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepositoryImpl) UpdateRole(ctx context.Context, role domain.Role) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": role.Name},
+		bson.M{"$set": bson.M{"permissions": role.Permissions}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRoleNotFound
+	}
+	return nil
+}
+
+func (r *RoleRepositoryImpl) DeleteRole(ctx context.Context, name string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrRoleNotFound
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	domain "task-manager/Domain"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,25 +12,23 @@ import (
 
 type TaskRepositoryImpl struct {
 	collection *mongo.Collection
-	ctx        context.Context
 }
 
 func NewTaskRepository(collection *mongo.Collection) domain.TaskRepository {
 	return &TaskRepositoryImpl{
 		collection: collection,
-		ctx:        context.TODO(),
 	}
 }
 
-func (t *TaskRepositoryImpl) GetAllTasks() ([]domain.Task, error) {
+func (t *TaskRepositoryImpl) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
 	var tasks []domain.Task
-	cursor, err := t.collection.Find(t.ctx, bson.M{})
+	cursor, err := t.collection.Find(ctx, bson.M{})
 	if err != nil {
 		return tasks, err
 	}
-	defer cursor.Close(t.ctx)
+	defer cursor.Close(ctx)
 
-	for cursor.Next(t.ctx) {
+	for cursor.Next(ctx) {
 		var task domain.Task
 		if err := cursor.Decode(&task); err == nil {
 			tasks = append(tasks, task)
@@ -41,34 +38,36 @@ func (t *TaskRepositoryImpl) GetAllTasks() ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (t *TaskRepositoryImpl) GetTaskByID(userID int) (domain.Task, error) {
+func (t *TaskRepositoryImpl) GetTaskByID(ctx context.Context, userID int) (domain.Task, error) {
 	var task domain.Task
-	err := t.collection.FindOne(t.ctx, bson.M{"user_id": userID}).Decode(&task)
+	err := t.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&task)
 	if err != nil {
-		return task, errors.New("task not found")
+		return task, notFound(ctx, err, domain.ErrTaskNotFound)
 	}
 	return task, nil
 }
 
-func (t *TaskRepositoryImpl) CreateTask(task domain.Task) error {
+func (t *TaskRepositoryImpl) CreateTask(ctx context.Context, task domain.Task) (domain.Task, error) {
 	if task.ID.IsZero() {
 		task.ID = primitive.NewObjectID()
 	}
 
 	var lastTask domain.Task
-	opts := options.FindOne().SetSort(bson.D{{"user_id", -1}})
-	err := t.collection.FindOne(t.ctx, bson.D{}, opts).Decode(&lastTask)
+	opts := options.FindOne().SetSort(bson.D{{Key: "user_id", Value: -1}})
+	err := t.collection.FindOne(ctx, bson.D{}, opts).Decode(&lastTask)
 	if err == nil {
 		task.UserID = lastTask.UserID + 1
 	} else {
 		task.UserID = 1
 	}
 
-	_, err = t.collection.InsertOne(t.ctx, task)
-	return err
+	if _, err = t.collection.InsertOne(ctx, task); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
 }
 
-func (t *TaskRepositoryImpl) UpdateTask(userID int, newTask domain.Task) (domain.Task, error) {
+func (t *TaskRepositoryImpl) UpdateTask(ctx context.Context, userID int, newTask domain.Task) (domain.Task, error) {
 	filter := bson.M{"user_id": userID}
 	update := bson.M{
 		"$set": bson.M{
@@ -76,29 +75,31 @@ func (t *TaskRepositoryImpl) UpdateTask(userID int, newTask domain.Task) (domain
 			"description": newTask.Description,
 			"due_date":    newTask.DueDate,
 			"status":      newTask.Status,
+			"assignee":    newTask.Assignee,
+			"project":     newTask.Project,
 		},
 	}
 
-	result, err := t.collection.UpdateOne(t.ctx, filter, update)
+	result, err := t.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return domain.Task{}, err
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.Task{}, errors.New("task not found")
+		return domain.Task{}, domain.ErrTaskNotFound
 	}
 
 	return newTask, nil
 }
 
-func (t *TaskRepositoryImpl) DeleteTask(userID int) error {
-	result, err := t.collection.DeleteOne(t.ctx, bson.M{"user_id": userID})
+func (t *TaskRepositoryImpl) DeleteTask(ctx context.Context, userID int) error {
+	result, err := t.collection.DeleteOne(ctx, bson.M{"user_id": userID})
 	if err != nil {
 		return err
 	}
 
 	if result.DeletedCount == 0 {
-		return errors.New("task not found")
+		return domain.ErrTaskNotFound
 	}
 
 	return nil
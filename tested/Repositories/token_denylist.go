@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TokenDenylistImpl shares revocations between instances; the TTL index on
+// expires_at drops entries once the tokens would have expired anyway.
+type TokenDenylistImpl struct {
+	collection *mongo.Collection
+}
+
+func NewTokenDenylist(collection *mongo.Collection) domain.TokenDenylist {
+	return &TokenDenylistImpl{
+		collection: collection,
+	}
+}
+
+func (d *TokenDenylistImpl) RevokeToken(ctx context.Context, id string, until time.Time) error {
+	_, err := d.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$max": bson.M{"expires_at": until}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (d *TokenDenylistImpl) IsTokenRevoked(ctx context.Context, ids ...string) (bool, error) {
+	// The TTL monitor runs about once a minute, so expired entries may linger.
+	count, err := d.collection.CountDocuments(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, options.Count().SetLimit(1))
+	return count > 0, err
+}
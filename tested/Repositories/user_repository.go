@@ -2,72 +2,178 @@ package repositories
 
 import (
 	"context"
-	"errors"
+	"regexp"
 	domain "task-manager/Domain"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// bumpTokenVersion is added to every update of a user's role or disabled
+// flag, so the auth middleware rejects the tokens issued before it.
+var bumpTokenVersion = bson.M{"token_version": 1}
+
 type UserRepositoryImpl struct {
 	collection *mongo.Collection
-	ctx        context.Context
 }
 
 func NewUserRepository(collection *mongo.Collection) domain.UserRepository {
 	return &UserRepositoryImpl{
 		collection: collection,
-		ctx:        context.TODO(),
 	}
 }
 
-func (u *UserRepositoryImpl) RegisterUser(username, password string) error {
+func (u *UserRepositoryImpl) RegisterUser(ctx context.Context, username, password, email string) error {
 
-	count, err := u.collection.CountDocuments(u.ctx, bson.M{"user_name": username})
+	count, err := u.collection.CountDocuments(ctx, bson.M{"user_name": username})
 	if err != nil {
 		return err
 	}
 	if count > 0 {
-		return errors.New("username is taken")
+		return domain.ErrUsernameTaken
 	}
 
 	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters")
+		return domain.ErrPasswordTooShort
 	}
 
 	user := domain.User{
 		UserName: username,
 		Password: password,
-		Role:     "user",
+		Role:     domain.RoleUser,
+		Email:    email,
 	}
 
-	_, err = u.collection.InsertOne(u.ctx, user)
+	_, err = u.collection.InsertOne(ctx, user)
 	return err
 }
 
-func (u *UserRepositoryImpl) AuthenticateUser(username, password string) (domain.User, error) {
+func (u *UserRepositoryImpl) AuthenticateUser(ctx context.Context, username, password string) (domain.User, error) {
 	var user domain.User
-	err := u.collection.FindOne(u.ctx, bson.M{"user_name": username}).Decode(&user)
+	err := u.collection.FindOne(ctx, bson.M{"user_name": username}).Decode(&user)
 	if err != nil {
-		return domain.User{}, errors.New("user not found")
+		return domain.User{}, notFound(ctx, err, domain.ErrUserNotFound)
 	}
 
 	return user, nil
 }
 
-func (u *UserRepositoryImpl) PromoteUser(username string) error {
+func (u *UserRepositoryImpl) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
+	var user domain.User
+	err := u.collection.FindOne(ctx, bson.M{"user_name": username}).Decode(&user)
+	if err != nil {
+		return domain.User{}, notFound(ctx, err, domain.ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+func (u *UserRepositoryImpl) GetAllUsers(ctx context.Context) ([]domain.User, error) {
+	users := []domain.User{}
+	opts := options.Find().SetProjection(bson.M{"password": 0}).SetSort(bson.D{{Key: "user_name", Value: 1}})
+	cursor, err := u.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return users, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return users, err
+	}
+	return users, nil
+}
+
+func (u *UserRepositoryImpl) PromoteUser(ctx context.Context, username string) error {
 
 	var user domain.User
-	err := u.collection.FindOne(u.ctx, bson.M{"user_name": username}).Decode(&user)
+	err := u.collection.FindOne(ctx, bson.M{"user_name": username}).Decode(&user)
 	if err != nil {
-		return errors.New("user not found")
+		return notFound(ctx, err, domain.ErrUserNotFound)
 	}
 
 	_, err = u.collection.UpdateOne(
-		u.ctx,
+		ctx,
 		bson.M{"user_name": username},
-		bson.M{"$set": bson.M{"role": "Admin"}},
+		bson.M{"$set": bson.M{"role": domain.RoleAdmin}, "$inc": bumpTokenVersion},
 	)
 
 	return err
 }
+
+func (u *UserRepositoryImpl) SetUserRole(ctx context.Context, username, role string) error {
+	result, err := u.collection.UpdateOne(ctx,
+		bson.M{"user_name": username},
+		bson.M{"$set": bson.M{"role": role}, "$inc": bumpTokenVersion},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *UserRepositoryImpl) CountUsersWithRole(ctx context.Context, role string) (int64, error) {
+	return u.collection.CountDocuments(ctx, bson.M{"role": role})
+}
+
+func (u *UserRepositoryImpl) CountEnabledUsersWithRole(ctx context.Context, role string) (int64, error) {
+	return u.collection.CountDocuments(ctx, bson.M{"role": role, "disabled": bson.M{"$ne": true}})
+}
+
+func (u *UserRepositoryImpl) ListUsers(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
+	filter := bson.M{}
+	if query.Search != "" {
+		filter["user_name"] = bson.M{"$regex": regexp.QuoteMeta(query.Search), "$options": "i"}
+	}
+
+	total, err := u.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := []domain.User{}
+	opts := options.Find().
+		SetProjection(bson.M{"password": 0}).
+		SetSort(bson.D{{Key: "user_name", Value: 1}}).
+		SetSkip(int64(query.Offset)).
+		SetLimit(int64(query.Limit))
+	cursor, err := u.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (u *UserRepositoryImpl) SetUserDisabled(ctx context.Context, username string, disabled bool) error {
+	update := bson.M{"$set": bson.M{"disabled": true}, "$inc": bumpTokenVersion}
+	if !disabled {
+		update = bson.M{"$unset": bson.M{"disabled": ""}, "$inc": bumpTokenVersion}
+	}
+	result, err := u.collection.UpdateOne(ctx, bson.M{"user_name": username}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *UserRepositoryImpl) DeleteUser(ctx context.Context, username string) error {
+	result, err := u.collection.DeleteOne(ctx, bson.M{"user_name": username})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
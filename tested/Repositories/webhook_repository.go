@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type WebhookRepositoryImpl struct {
+	webhooks    *mongo.Collection
+	deadLetters *mongo.Collection
+}
+
+func NewWebhookRepository(webhooks, deadLetters *mongo.Collection) domain.WebhookRepository {
+	return &WebhookRepositoryImpl{
+		webhooks:    webhooks,
+		deadLetters: deadLetters,
+	}
+}
+
+func (w *WebhookRepositoryImpl) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+
+	if _, err := w.webhooks.InsertOne(ctx, webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepositoryImpl) GetAllWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	webhooks := []domain.Webhook{}
+	cursor, err := w.webhooks.Find(ctx, bson.M{})
+	if err != nil {
+		return webhooks, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return webhooks, err
+	}
+	return webhooks, nil
+}
+
+func (w *WebhookRepositoryImpl) GetWebhookByID(ctx context.Context, id string) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return webhook, domain.ErrWebhookNotFound
+	}
+
+	if err := w.webhooks.FindOne(ctx, bson.M{"_id": objectID}).Decode(&webhook); err != nil {
+		return webhook, domain.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (w *WebhookRepositoryImpl) DeleteWebhook(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrWebhookNotFound
+	}
+
+	result, err := w.webhooks.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (w *WebhookRepositoryImpl) AddDeadLetter(ctx context.Context, delivery domain.WebhookDelivery) error {
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+
+	_, err := w.deadLetters.InsertOne(ctx, delivery)
+	return err
+}
+
+func (w *WebhookRepositoryImpl) GetDeadLetters(ctx context.Context) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	cursor, err := w.deadLetters.Find(ctx, bson.M{})
+	if err != nil {
+		return deliveries, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepositoryImpl) GetDeadLetterByID(ctx context.Context, id string) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return delivery, domain.ErrDeadLetterNotFound
+	}
+
+	if err := w.deadLetters.FindOne(ctx, bson.M{"_id": objectID}).Decode(&delivery); err != nil {
+		return delivery, domain.ErrDeadLetterNotFound
+	}
+	return delivery, nil
+}
+
+func (w *WebhookRepositoryImpl) DeleteDeadLetter(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrDeadLetterNotFound
+	}
+
+	result, err := w.deadLetters.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrDeadLetterNotFound
+	}
+	return nil
+}
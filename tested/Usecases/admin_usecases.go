@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+)
+
+type AdminUseCaseImpl struct {
+	userRepository  domain.UserRepository
+	passwordService infrastructure.PasswordService
+	publisher       domain.EventPublisher
+}
+
+func NewAdminUseCase(
+	userRepository domain.UserRepository,
+	passwordService infrastructure.PasswordService,
+	publisher domain.EventPublisher,
+) domain.AdminUseCase {
+	return &AdminUseCaseImpl{
+		userRepository:  userRepository,
+		passwordService: passwordService,
+		publisher:       publisher,
+	}
+}
+
+func (a *AdminUseCaseImpl) CreateOrPromoteAdmin(ctx context.Context, username, password, email string) (bool, error) {
+	user, err := a.userRepository.GetUserByUsername(ctx, username)
+	switch {
+	case err == nil:
+		if user.IsAdmin() && !user.Disabled {
+			return false, nil
+		}
+		return false, a.restore(ctx, user)
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return false, err
+	}
+
+	if err := a.register(ctx, username, password, email); err != nil {
+		return false, err
+	}
+	return true, a.promote(ctx, username, email)
+}
+
+func (a *AdminUseCaseImpl) BootstrapAdmin(ctx context.Context, username, password, email string) (bool, error) {
+	admins, err := a.userRepository.CountEnabledUsersWithRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return false, err
+	}
+	if admins > 0 {
+		return false, nil
+	}
+
+	// Anyone may have registered the username before the first boot, so
+	// only the holder of its password gets it promoted.
+	user, err := a.userRepository.GetUserByUsername(ctx, username)
+	switch {
+	case err == nil:
+		if a.passwordService.ComparePassword(user.Password, password) != nil {
+			audit(ctx, "user.bootstrap_refused", "username", username, "reason", "password mismatch")
+			return false, domain.ErrBootstrapUserExists
+		}
+		return true, a.restore(ctx, user)
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return false, err
+	}
+
+	if err := a.register(ctx, username, password, email); err != nil {
+		return false, err
+	}
+	return true, a.promote(ctx, username, email)
+}
+
+func (a *AdminUseCaseImpl) register(ctx context.Context, username, password, email string) error {
+	if err := domain.ValidateEmail(email); err != nil {
+		return err
+	}
+	// Checked here because the repository only ever sees the hash.
+	if len(password) < 8 {
+		return domain.ErrPasswordTooShort
+	}
+	hashedPassword, err := a.passwordService.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := a.userRepository.RegisterUser(ctx, username, hashedPassword, email); err != nil {
+		return err
+	}
+	a.publisher.Publish(domain.NewUserEvent(domain.EventUserRegistered, domain.User{UserName: username, Role: "user", Email: email}))
+	return nil
+}
+
+// restore makes an existing user an enabled Admin; a disabled Admin could
+// not sign in and would not count as the Admin the command promises.
+func (a *AdminUseCaseImpl) restore(ctx context.Context, user domain.User) error {
+	if !user.IsAdmin() {
+		if err := a.promote(ctx, user.UserName, user.Email); err != nil {
+			return err
+		}
+	}
+	if user.Disabled {
+		if err := a.userRepository.SetUserDisabled(ctx, user.UserName, false); err != nil {
+			return err
+		}
+		audit(ctx, "user.enable", "username", user.UserName, "via", "admin command")
+	}
+	return nil
+}
+
+func (a *AdminUseCaseImpl) promote(ctx context.Context, username, email string) error {
+	if err := a.userRepository.PromoteUser(ctx, username); err != nil {
+		return err
+	}
+	audit(ctx, "user.promote", "username", username, "via", "admin command")
+	a.publisher.Publish(domain.NewUserEvent(domain.EventUserPromoted, domain.User{UserName: username, Role: domain.RoleAdmin, Email: email}))
+	return nil
+}
@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminUseCase_CreatesMissingUserAsAdmin(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, pass, pub)
+
+	repo.On("GetUserByUsername", "root").Return(domain.User{}, domain.ErrUserNotFound).Once()
+	pass.On("HashPassword", "longenough").Return("hashed", nil).Once()
+	repo.On("RegisterUser", "root", "hashed", "root@example.com").Return(nil).Once()
+	repo.On("PromoteUser", "root").Return(nil).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool { return e.Type == domain.EventUserRegistered })).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventUserPromoted && e.User.Role == "Admin"
+	})).Once()
+
+	created, err := uc.CreateOrPromoteAdmin(context.Background(), "root", "longenough", "root@example.com")
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestAdminUseCase_PromotesExistingUserWithoutTouchingPassword(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, pass, pub)
+
+	repo.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob", Role: "user", Email: "bob@example.com"}, nil).Once()
+	repo.On("PromoteUser", "bob").Return(nil).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventUserPromoted && e.User.Email == "bob@example.com"
+	})).Once()
+
+	created, err := uc.CreateOrPromoteAdmin(context.Background(), "bob", "", "")
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	pass.AssertNotCalled(t, "HashPassword", mock.Anything)
+	repo.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything, mock.Anything)
+	pub.AssertExpectations(t)
+}
+
+func TestAdminUseCase_ExistingAdminIsLeftAlone(t *testing.T) {
+	repo := new(MockUserRepository)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, new(MockPasswordService), pub)
+
+	repo.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Role: "Admin"}, nil).Once()
+
+	created, err := uc.CreateOrPromoteAdmin(context.Background(), "root", "", "")
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	repo.AssertNotCalled(t, "PromoteUser", mock.Anything)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestAdminUseCase_DisabledAdminIsEnabledAgain(t *testing.T) {
+	repo := new(MockUserRepository)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, new(MockPasswordService), pub)
+
+	repo.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Role: "Admin", Disabled: true}, nil).Once()
+	repo.On("SetUserDisabled", "root", false).Return(nil).Once()
+
+	created, err := uc.CreateOrPromoteAdmin(context.Background(), "root", "", "")
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "PromoteUser", mock.Anything)
+}
+
+func TestAdminUseCase_NewUserNeedsALongEnoughPassword(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	uc := NewAdminUseCase(repo, pass, new(MockEventPublisher))
+
+	repo.On("GetUserByUsername", "root").Return(domain.User{}, domain.ErrUserNotFound).Once()
+
+	_, err := uc.CreateOrPromoteAdmin(context.Background(), "root", "short", "")
+
+	assert.ErrorIs(t, err, domain.ErrPasswordTooShort)
+	pass.AssertNotCalled(t, "HashPassword", mock.Anything)
+}
+
+func TestAdminUseCase_BootstrapSkipsWhenAnAdminExists(t *testing.T) {
+	repo := new(MockUserRepository)
+	uc := NewAdminUseCase(repo, new(MockPasswordService), new(MockEventPublisher))
+
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(1), nil).Once()
+
+	done, err := uc.BootstrapAdmin(context.Background(), "root", "longenough", "")
+
+	assert.NoError(t, err)
+	assert.False(t, done)
+	repo.AssertNotCalled(t, "GetUserByUsername", mock.Anything)
+	repo.AssertNotCalled(t, "GetAllUsers")
+}
+
+func TestAdminUseCase_BootstrapCreatesTheAdmin(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, pass, pub)
+
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(0), nil).Once()
+	repo.On("GetUserByUsername", "root").Return(domain.User{}, domain.ErrUserNotFound).Once()
+	pass.On("HashPassword", "longenough").Return("hashed", nil).Once()
+	repo.On("RegisterUser", "root", "hashed", "").Return(nil).Once()
+	repo.On("PromoteUser", "root").Return(nil).Once()
+	pub.On("Publish", mock.Anything).Twice()
+
+	done, err := uc.BootstrapAdmin(context.Background(), "root", "longenough", "")
+
+	assert.NoError(t, err)
+	assert.True(t, done)
+	repo.AssertExpectations(t)
+}
+
+func TestAdminUseCase_BootstrapRefusesSomeoneElsesAccount(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, pass, pub)
+
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(0), nil).Once()
+	repo.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Password: "squatter-hash", Role: "user"}, nil).Once()
+	pass.On("ComparePassword", "squatter-hash", "longenough").Return(errors.New("mismatch")).Once()
+
+	done, err := uc.BootstrapAdmin(context.Background(), "root", "longenough", "")
+
+	assert.ErrorIs(t, err, domain.ErrBootstrapUserExists)
+	assert.False(t, done)
+	repo.AssertNotCalled(t, "PromoteUser", mock.Anything)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestAdminUseCase_BootstrapPromotesTheOwnerOfTheAccount(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, pass, pub)
+
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(0), nil).Once()
+	repo.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Password: "hashed", Role: "user"}, nil).Once()
+	pass.On("ComparePassword", "hashed", "longenough").Return(nil).Once()
+	repo.On("PromoteUser", "root").Return(nil).Once()
+	pub.On("Publish", mock.Anything).Once()
+
+	done, err := uc.BootstrapAdmin(context.Background(), "root", "longenough", "")
+
+	assert.NoError(t, err)
+	assert.True(t, done)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminUseCase_BootstrapEnablesADisabledAccount(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	pub := new(MockEventPublisher)
+	uc := NewAdminUseCase(repo, pass, pub)
+
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(0), nil).Once()
+	repo.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Password: "hashed", Role: "Admin", Disabled: true}, nil).Once()
+	pass.On("ComparePassword", "hashed", "longenough").Return(nil).Once()
+	repo.On("SetUserDisabled", "root", false).Return(nil).Once()
+
+	done, err := uc.BootstrapAdmin(context.Background(), "root", "longenough", "")
+
+	assert.NoError(t, err)
+	assert.True(t, done)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "PromoteUser", mock.Anything)
+}
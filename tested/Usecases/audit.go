@@ -0,0 +1,13 @@
+package usecases
+
+import (
+	"context"
+
+	infrastructure "task-manager/Infrastructure"
+)
+
+// audit logs a security-relevant change with the request-scoped logger, so
+// the line carries the request ID and the authenticated user.
+func audit(ctx context.Context, action string, args ...any) {
+	infrastructure.LoggerFrom(ctx).Info("audit", append([]any{"action", action}, args...)...)
+}
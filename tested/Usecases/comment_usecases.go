@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+)
+
+type CommentUseCaseImpl struct {
+	commentRepository domain.CommentRepository
+	taskRepository    domain.TaskRepository
+	publisher         domain.EventPublisher
+}
+
+func NewCommentUseCase(
+	commentRepository domain.CommentRepository,
+	taskRepository domain.TaskRepository,
+	publisher domain.EventPublisher,
+) domain.CommentUseCase {
+	return &CommentUseCaseImpl{
+		commentRepository: commentRepository,
+		taskRepository:    taskRepository,
+		publisher:         publisher,
+	}
+}
+
+func (c *CommentUseCaseImpl) AddComment(ctx context.Context, taskID int, author, body string) (domain.Comment, error) {
+	comment := domain.Comment{
+		TaskID:    taskID,
+		Author:    author,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := comment.Validate(); err != nil {
+		return domain.Comment{}, err
+	}
+
+	task, err := c.taskRepository.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	created, err := c.commentRepository.CreateComment(ctx, comment)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	c.publisher.Publish(domain.NewCommentEvent(task, created))
+	return created, nil
+}
+
+func (c *CommentUseCaseImpl) GetComments(ctx context.Context, taskID int) ([]domain.Comment, error) {
+	if _, err := c.taskRepository.GetTaskByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return c.commentRepository.GetCommentsByTaskID(ctx, taskID)
+}
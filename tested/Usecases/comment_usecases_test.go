@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCommentUseCase_AddComment_Success(t *testing.T) {
+	comments := new(MockCommentRepository)
+	tasks := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := NewCommentUseCase(comments, tasks, pub)
+
+	task := domain.Task{UserID: 3, Title: "t", Assignee: "bob"}
+	tasks.On("GetTaskByID", 3).Return(task, nil).Once()
+	comments.On("CreateComment", mock.MatchedBy(func(c domain.Comment) bool {
+		return c.TaskID == 3 && c.Author == "alice" && c.Body == "looks good" && !c.CreatedAt.IsZero()
+	})).Return(domain.Comment{TaskID: 3, Author: "alice", Body: "looks good"}, nil).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskCommented && e.Task.UserID == 3 && e.Comment.Author == "alice"
+	})).Once()
+
+	got, err := uc.AddComment(context.Background(), 3, "alice", "looks good")
+	assert.NoError(t, err)
+	assert.Equal(t, "looks good", got.Body)
+	comments.AssertExpectations(t)
+	tasks.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestCommentUseCase_AddComment_EmptyBody(t *testing.T) {
+	comments := new(MockCommentRepository)
+	tasks := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := NewCommentUseCase(comments, tasks, pub)
+
+	_, err := uc.AddComment(context.Background(), 3, "alice", "   ")
+	assert.ErrorIs(t, err, domain.ErrInvalidCommentBody)
+	tasks.AssertNotCalled(t, "GetTaskByID", mock.Anything)
+	comments.AssertNotCalled(t, "CreateComment", mock.Anything)
+}
+
+func TestCommentUseCase_AddComment_TaskNotFound(t *testing.T) {
+	comments := new(MockCommentRepository)
+	tasks := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := NewCommentUseCase(comments, tasks, pub)
+
+	tasks.On("GetTaskByID", 9).Return(domain.Task{}, errors.New("task not found")).Once()
+
+	_, err := uc.AddComment(context.Background(), 9, "alice", "hi")
+	assert.Error(t, err)
+	comments.AssertNotCalled(t, "CreateComment", mock.Anything)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestCommentUseCase_GetComments(t *testing.T) {
+	comments := new(MockCommentRepository)
+	tasks := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := NewCommentUseCase(comments, tasks, pub)
+
+	expected := []domain.Comment{{TaskID: 3, Body: "a"}}
+	tasks.On("GetTaskByID", 3).Return(domain.Task{UserID: 3}, nil).Once()
+	comments.On("GetCommentsByTaskID", 3).Return(expected, nil).Once()
+
+	got, err := uc.GetComments(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
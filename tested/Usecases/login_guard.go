@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+)
+
+const baseLoginDelay = 250 * time.Millisecond
+
+type LoginGuardImpl struct {
+	attempts domain.LoginAttemptRepository
+	policy   domain.LoginPolicy
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration)
+}
+
+func NewLoginGuard(attempts domain.LoginAttemptRepository, policy domain.LoginPolicy) domain.LoginGuard {
+	return &LoginGuardImpl{
+		attempts: attempts,
+		policy:   policy,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+func userKey(username string) string { return "user:" + username }
+
+func ipKey(ip string) string { return "ip:" + ip }
+
+func (g *LoginGuardImpl) Check(ctx context.Context, username, ip string) error {
+	now := g.now()
+	for _, key := range []string{userKey(username), ipKey(ip)} {
+		attempt, err := g.attempts.GetLoginAttempt(ctx, key)
+		if err != nil {
+			return err
+		}
+		if attempt.IsLocked(now) {
+			return domain.ErrTooManyLoginAttempts
+		}
+	}
+	return nil
+}
+
+func (g *LoginGuardImpl) Failed(ctx context.Context, username, ip string) {
+	now := g.now()
+	worst := 0
+	for _, key := range []struct {
+		name      string
+		threshold int
+	}{
+		{userKey(username), g.policy.UserThreshold},
+		{ipKey(ip), g.policy.IPThreshold},
+	} {
+		attempt, err := g.attempts.RecordFailedLogin(ctx, key.name, now, g.policy.Window)
+		if err != nil {
+			infrastructure.LoggerFrom(ctx).Error("failed to record login failure", "error", err, "key", key.name)
+			continue
+		}
+		worst = max(worst, attempt.Failures)
+		if attempt.Failures < key.threshold || attempt.IsLocked(now) {
+			continue
+		}
+
+		until := now.Add(g.policy.LockoutDuration)
+		if err := g.attempts.LockLogin(ctx, key.name, until); err != nil {
+			infrastructure.LoggerFrom(ctx).Error("failed to lock login", "error", err, "key", key.name)
+			continue
+		}
+		audit(ctx, "user.login_locked", "key", key.name, "failures", attempt.Failures, "until", until)
+	}
+
+	if delay := g.delay(worst); delay > 0 {
+		g.sleep(ctx, delay)
+	}
+}
+
+// delay doubles for every failure past the free ones.
+func (g *LoginGuardImpl) delay(failures int) time.Duration {
+	extra := failures - g.policy.FreeAttempts
+	if extra <= 0 {
+		return 0
+	}
+	delay := baseLoginDelay
+	for i := 1; i < extra && delay < g.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, g.policy.MaxDelay)
+}
+
+func (g *LoginGuardImpl) Succeeded(ctx context.Context, username string) {
+	if err := g.attempts.ClearLoginAttempts(ctx, userKey(username)); err != nil {
+		infrastructure.LoggerFrom(ctx).Error("failed to clear login failures", "error", err, "username", username)
+	}
+}
+
+func (g *LoginGuardImpl) Unlock(ctx context.Context, username string) error {
+	return g.attempts.ClearLoginAttempts(ctx, userKey(username))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testLoginPolicy = domain.LoginPolicy{
+	FreeAttempts:    3,
+	MaxDelay:        2 * time.Second,
+	UserThreshold:   10,
+	IPThreshold:     50,
+	Window:          15 * time.Minute,
+	LockoutDuration: 15 * time.Minute,
+}
+
+func newTestLoginGuard(repo domain.LoginAttemptRepository, now time.Time) (*LoginGuardImpl, *[]time.Duration) {
+	var slept []time.Duration
+	guard := NewLoginGuard(repo, testLoginPolicy).(*LoginGuardImpl)
+	guard.now = func() time.Time { return now }
+	guard.sleep = func(_ context.Context, d time.Duration) { slept = append(slept, d) }
+	return guard, &slept
+}
+
+func TestLoginGuard_Check(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockLoginAttemptRepository)
+	guard, _ := newTestLoginGuard(repo, now)
+
+	repo.On("GetLoginAttempt", "user:bob").Return(domain.LoginAttempt{Key: "user:bob", Failures: 9}, nil)
+	repo.On("GetLoginAttempt", "ip:192.0.2.1").Return(domain.LoginAttempt{}, nil)
+	repo.On("GetLoginAttempt", "user:eve").Return(domain.LoginAttempt{}, nil)
+	repo.On("GetLoginAttempt", "ip:198.51.100.7").Return(domain.LoginAttempt{LockedUntil: now.Add(time.Minute)}, nil)
+	repo.On("GetLoginAttempt", "user:carol").Return(domain.LoginAttempt{LockedUntil: now.Add(-time.Minute)}, nil)
+
+	assert.NoError(t, guard.Check(context.Background(), "bob", "192.0.2.1"))
+	assert.ErrorIs(t, guard.Check(context.Background(), "eve", "198.51.100.7"), domain.ErrTooManyLoginAttempts)
+	assert.NoError(t, guard.Check(context.Background(), "carol", "192.0.2.1"), "expired lockouts no longer apply")
+}
+
+func TestLoginGuard_Failed_DelaysProgressively(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for failures, want := range map[int]time.Duration{
+		3: 0,
+		4: 250 * time.Millisecond,
+		5: 500 * time.Millisecond,
+		7: 2 * time.Second,
+		9: 2 * time.Second,
+	} {
+		repo := new(MockLoginAttemptRepository)
+		guard, slept := newTestLoginGuard(repo, now)
+		repo.On("RecordFailedLogin", "user:bob", now, testLoginPolicy.Window).Return(domain.LoginAttempt{Failures: failures}, nil).Once()
+		repo.On("RecordFailedLogin", "ip:192.0.2.1", now, testLoginPolicy.Window).Return(domain.LoginAttempt{Failures: 1}, nil).Once()
+
+		guard.Failed(context.Background(), "bob", "192.0.2.1")
+
+		if want == 0 {
+			assert.Empty(t, *slept, "failures=%d", failures)
+		} else {
+			assert.Equal(t, []time.Duration{want}, *slept, "failures=%d", failures)
+		}
+		repo.AssertNotCalled(t, "LockLogin", mock.Anything, mock.Anything)
+	}
+}
+
+func TestLoginGuard_Failed_LocksAtThreshold(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockLoginAttemptRepository)
+	guard, _ := newTestLoginGuard(repo, now)
+
+	repo.On("RecordFailedLogin", "user:bob", now, testLoginPolicy.Window).Return(domain.LoginAttempt{Failures: 10}, nil).Once()
+	repo.On("RecordFailedLogin", "ip:192.0.2.1", now, testLoginPolicy.Window).Return(domain.LoginAttempt{Failures: 50}, nil).Once()
+	repo.On("LockLogin", "user:bob", now.Add(testLoginPolicy.LockoutDuration)).Return(nil).Once()
+	repo.On("LockLogin", "ip:192.0.2.1", now.Add(testLoginPolicy.LockoutDuration)).Return(nil).Once()
+
+	guard.Failed(context.Background(), "bob", "192.0.2.1")
+	repo.AssertExpectations(t)
+}
+
+func TestLoginGuard_Failed_DoesNotExtendALockout(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockLoginAttemptRepository)
+	guard, _ := newTestLoginGuard(repo, now)
+
+	repo.On("RecordFailedLogin", "user:bob", now, testLoginPolicy.Window).
+		Return(domain.LoginAttempt{Failures: 11, LockedUntil: now.Add(time.Minute)}, nil).Once()
+	repo.On("RecordFailedLogin", "ip:192.0.2.1", now, testLoginPolicy.Window).Return(domain.LoginAttempt{Failures: 1}, nil).Once()
+
+	guard.Failed(context.Background(), "bob", "192.0.2.1")
+	repo.AssertNotCalled(t, "LockLogin", mock.Anything, mock.Anything)
+}
+
+func TestLoginGuard_SucceededAndUnlockClearTheUser(t *testing.T) {
+	repo := new(MockLoginAttemptRepository)
+	guard, _ := newTestLoginGuard(repo, time.Now())
+
+	repo.On("ClearLoginAttempts", "user:bob").Return(nil).Twice()
+
+	guard.Succeeded(context.Background(), "bob")
+	assert.NoError(t, guard.Unlock(context.Background(), "bob"))
+	repo.AssertExpectations(t)
+}
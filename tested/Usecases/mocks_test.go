@@ -1,59 +1,141 @@
 package usecases
 
 import (
+	"context"
 	domain "task-manager/Domain"
 	infrastructure "task-manager/Infrastructure"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 )
 
 // MockTaskRepository mocks domain.TaskRepository
 type MockTaskRepository struct{ mock.Mock }
 
-func (m *MockTaskRepository) GetAllTasks() ([]domain.Task, error) {
+func (m *MockTaskRepository) GetAllTasks(_ context.Context) ([]domain.Task, error) {
 	args := m.Called()
 	return args.Get(0).([]domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) GetTaskByID(userID int) (domain.Task, error) {
+func (m *MockTaskRepository) GetTaskByID(_ context.Context, userID int) (domain.Task, error) {
 	args := m.Called(userID)
 	return args.Get(0).(domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) CreateTask(task domain.Task) error {
+func (m *MockTaskRepository) CreateTask(_ context.Context, task domain.Task) (domain.Task, error) {
 	args := m.Called(task)
-	return args.Error(0)
+	return args.Get(0).(domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) UpdateTask(userID int, task domain.Task) (domain.Task, error) {
+func (m *MockTaskRepository) UpdateTask(_ context.Context, userID int, task domain.Task) (domain.Task, error) {
 	args := m.Called(userID, task)
 	return args.Get(0).(domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) DeleteTask(userID int) error {
+func (m *MockTaskRepository) DeleteTask(_ context.Context, userID int) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
 // MockUserRepository mocks domain.UserRepository
+type MockTaskAuthorizer struct{ mock.Mock }
+
+func (m *MockTaskAuthorizer) AuthorizeTask(_ context.Context, action domain.Permission, task domain.Task, update *domain.Task) error {
+	return m.Called(action, task, update).Error(0)
+}
+
+// allowTasks authorizes every task action.
+func allowTasks() *MockTaskAuthorizer {
+	authorizer := new(MockTaskAuthorizer)
+	authorizer.On("AuthorizeTask", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return authorizer
+}
+
 type MockUserRepository struct{ mock.Mock }
 
-func (m *MockUserRepository) RegisterUser(username, password string) error {
-	args := m.Called(username, password)
+func (m *MockUserRepository) RegisterUser(_ context.Context, username, password, email string) error {
+	args := m.Called(username, password, email)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) AuthenticateUser(username, password string) (domain.User, error) {
+func (m *MockUserRepository) AuthenticateUser(_ context.Context, username, password string) (domain.User, error) {
 	args := m.Called(username, password)
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) PromoteUser(username string) error {
+func (m *MockUserRepository) GetUserByUsername(_ context.Context, username string) (domain.User, error) {
+	args := m.Called(username)
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetAllUsers(_ context.Context) ([]domain.User, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) PromoteUser(_ context.Context, username string) error {
 	args := m.Called(username)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) SetUserRole(_ context.Context, username, role string) error {
+	args := m.Called(username, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CountUsersWithRole(_ context.Context, role string) (int64, error) {
+	args := m.Called(role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) CountEnabledUsersWithRole(_ context.Context, role string) (int64, error) {
+	args := m.Called(role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) ListUsers(_ context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
+	args := m.Called(query)
+	return args.Get(0).([]domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) SetUserDisabled(_ context.Context, username string, disabled bool) error {
+	args := m.Called(username, disabled)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteUser(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+// MockRoleRepository mocks domain.RoleRepository
+type MockRoleRepository struct{ mock.Mock }
+
+func (m *MockRoleRepository) CreateRole(_ context.Context, role domain.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) GetRole(_ context.Context, name string) (domain.Role, error) {
+	args := m.Called(name)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetAllRoles(_ context.Context) ([]domain.Role, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) UpdateRole(_ context.Context, role domain.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) DeleteRole(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
 // MockPasswordService mocks infrastructure.PasswordService
 type MockPasswordService struct{ mock.Mock }
 
@@ -70,20 +152,215 @@ func (m *MockPasswordService) ComparePassword(hashedPassword, password string) e
 // MockJWTService mocks infrastructure.JWTService
 type MockJWTService struct{ mock.Mock }
 
-func (m *MockJWTService) GenerateToken(user domain.User) (string, error) {
-	args := m.Called(user)
+func (m *MockJWTService) GenerateToken(user domain.User, sessionID string) (string, error) {
+	args := m.Called(user, sessionID)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockJWTService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
+func (m *MockJWTService) ValidateToken(tokenString string) (*infrastructure.Claims, error) {
 	args := m.Called(tokenString)
-	if claims, ok := args.Get(0).(jwt.MapClaims); ok {
+	if claims, ok := args.Get(0).(*infrastructure.Claims); ok {
 		return claims, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+// MockEventPublisher mocks domain.EventPublisher
+type MockEventPublisher struct{ mock.Mock }
+
+func (m *MockEventPublisher) Publish(event domain.Event) {
+	m.Called(event)
+}
+
+// MockWebhookRepository mocks domain.WebhookRepository
+type MockWebhookRepository struct{ mock.Mock }
+
+func (m *MockWebhookRepository) CreateWebhook(_ context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetAllWebhooks(_ context.Context) ([]domain.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookByID(_ context.Context, id string) (domain.Webhook, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) AddDeadLetter(_ context.Context, delivery domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDeadLetters(_ context.Context) ([]domain.WebhookDelivery, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeadLetterByID(_ context.Context, id string) (domain.WebhookDelivery, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteDeadLetter(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockWebhookDispatcher mocks infrastructure.WebhookDispatcher
+type MockWebhookDispatcher struct{ mock.Mock }
+
+func (m *MockWebhookDispatcher) Publish(event domain.Event) {
+	m.Called(event)
+}
+
+func (m *MockWebhookDispatcher) Run(_ context.Context) error {
+	return m.Called().Error(0)
+}
+
+func (m *MockWebhookDispatcher) Deliver(_ context.Context, webhook domain.Webhook, event domain.Event) error {
+	args := m.Called(webhook, event)
+	return args.Error(0)
+}
+
+// MockCommentRepository mocks domain.CommentRepository
+type MockCommentRepository struct{ mock.Mock }
+
+func (m *MockCommentRepository) CreateComment(_ context.Context, comment domain.Comment) (domain.Comment, error) {
+	args := m.Called(comment)
+	return args.Get(0).(domain.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentsByTaskID(_ context.Context, taskID int) ([]domain.Comment, error) {
+	args := m.Called(taskID)
+	return args.Get(0).([]domain.Comment), args.Error(1)
+}
+
+// MockNotifier mocks domain.Notifier
+type MockNotifier struct{ mock.Mock }
+
+func (m *MockNotifier) NotifyAssignment(user domain.User, task domain.Task) error {
+	args := m.Called(user, task)
+	return args.Error(0)
+}
+
+func (m *MockNotifier) NotifyDueSoon(user domain.User, task domain.Task) error {
+	args := m.Called(user, task)
+	return args.Error(0)
+}
+
+func (m *MockNotifier) NotifyComment(user domain.User, task domain.Task, comment domain.Comment) error {
+	args := m.Called(user, task, comment)
+	return args.Error(0)
+}
+
+// MockLoginRecorder mocks domain.LoginRecorder
+type MockLoginRecorder struct{ mock.Mock }
+
+func (m *MockLoginRecorder) RecordLogin(outcome string) {
+	m.Called(outcome)
+}
+
+// MockLoginGuard mocks domain.LoginGuard
+type MockLoginGuard struct{ mock.Mock }
+
+func (m *MockLoginGuard) Check(_ context.Context, username, ip string) error {
+	args := m.Called(username, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) Failed(_ context.Context, username, ip string) {
+	m.Called(username, ip)
+}
+
+func (m *MockLoginGuard) Succeeded(_ context.Context, username string) {
+	m.Called(username)
+}
+
+func (m *MockLoginGuard) Unlock(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+// MockLoginAttemptRepository mocks domain.LoginAttemptRepository
+type MockLoginAttemptRepository struct{ mock.Mock }
+
+func (m *MockLoginAttemptRepository) GetLoginAttempt(_ context.Context, key string) (domain.LoginAttempt, error) {
+	args := m.Called(key)
+	return args.Get(0).(domain.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) RecordFailedLogin(_ context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttempt, error) {
+	args := m.Called(key, now, window)
+	return args.Get(0).(domain.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) LockLogin(_ context.Context, key string, until time.Time) error {
+	args := m.Called(key, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) ClearLoginAttempts(_ context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+// MockRefreshTokenRepository mocks domain.RefreshTokenRepository
+type MockRefreshTokenRepository struct{ mock.Mock }
+
+func (m *MockRefreshTokenRepository) CreateRefreshToken(_ context.Context, token domain.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) UseRefreshToken(_ context.Context, id string, now time.Time) (domain.RefreshToken, error) {
+	args := m.Called(id, now)
+	return args.Get(0).(domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(_ context.Context, family string) error {
+	args := m.Called(family)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeUserRefreshTokens(_ context.Context, username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+// MockTokenDenylist mocks domain.TokenDenylist
+type MockTokenDenylist struct{ mock.Mock }
+
+func (m *MockTokenDenylist) RevokeToken(_ context.Context, id string, until time.Time) error {
+	args := m.Called(id, until)
+	return args.Error(0)
+}
+
+func (m *MockTokenDenylist) IsTokenRevoked(_ context.Context, ids ...string) (bool, error) {
+	args := m.Called(ids)
+	return args.Bool(0), args.Error(1)
+}
+
 var _ domain.TaskRepository = (*MockTaskRepository)(nil)
 var _ domain.UserRepository = (*MockUserRepository)(nil)
+var _ domain.RoleRepository = (*MockRoleRepository)(nil)
 var _ infrastructure.PasswordService = (*MockPasswordService)(nil)
 var _ infrastructure.JWTService = (*MockJWTService)(nil)
+var _ domain.EventPublisher = (*MockEventPublisher)(nil)
+var _ domain.CommentRepository = (*MockCommentRepository)(nil)
+var _ domain.Notifier = (*MockNotifier)(nil)
+var _ domain.LoginRecorder = (*MockLoginRecorder)(nil)
+var _ domain.LoginGuard = (*MockLoginGuard)(nil)
+var _ domain.LoginAttemptRepository = (*MockLoginAttemptRepository)(nil)
+var _ domain.RefreshTokenRepository = (*MockRefreshTokenRepository)(nil)
+var _ domain.TokenDenylist = (*MockTokenDenylist)(nil)
+var _ domain.WebhookRepository = (*MockWebhookRepository)(nil)
+var _ infrastructure.WebhookDispatcher = (*MockWebhookDispatcher)(nil)
@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+)
+
+// assigneeLookupTimeout bounds the user lookup Publish makes on the
+// publisher's goroutine.
+const assigneeLookupTimeout = 2 * time.Second
+
+type NotificationUseCaseImpl struct {
+	userRepository domain.UserRepository
+	taskRepository domain.TaskRepository
+	notifier       domain.Notifier
+	now            func() time.Time
+
+	mu sync.Mutex
+	// reminded maps the task/due-date pairs that already got a reminder to
+	// their due date, so each is announced once per process and dropped
+	// once it has passed.
+	reminded map[string]time.Time
+}
+
+func NewNotificationUseCase(
+	userRepository domain.UserRepository,
+	taskRepository domain.TaskRepository,
+	notifier domain.Notifier,
+) domain.NotificationUseCase {
+	return &NotificationUseCaseImpl{
+		userRepository: userRepository,
+		taskRepository: taskRepository,
+		notifier:       notifier,
+		now:            time.Now,
+		reminded:       map[string]time.Time{},
+	}
+}
+
+func (n *NotificationUseCaseImpl) Publish(event domain.Event) {
+	if event.Task == nil || event.Task.Assignee == "" {
+		return
+	}
+
+	// Publish runs inside EventBus.Publish, so a slow database must not hold
+	// up whoever published the event.
+	ctx, cancel := context.WithTimeout(context.Background(), assigneeLookupTimeout)
+	defer cancel()
+	var err error
+	switch event.Type {
+	case domain.EventTaskCreated:
+		err = n.notifyAssignee(ctx, *event.Task, func(user domain.User) error {
+			return n.notifier.NotifyAssignment(user, *event.Task)
+		})
+	case domain.EventTaskUpdated:
+		if event.Previous != nil && event.Previous.Assignee == event.Task.Assignee {
+			return
+		}
+		err = n.notifyAssignee(ctx, *event.Task, func(user domain.User) error {
+			return n.notifier.NotifyAssignment(user, *event.Task)
+		})
+	case domain.EventTaskCommented:
+		if event.Comment == nil || event.Comment.Author == event.Task.Assignee {
+			return
+		}
+		err = n.notifyAssignee(ctx, *event.Task, func(user domain.User) error {
+			return n.notifier.NotifyComment(user, *event.Task, *event.Comment)
+		})
+	}
+
+	if err != nil {
+		slog.Error("failed to send notification", "error", err, "event_id", event.ID, "task_id", event.Task.ID.Hex(), "task_number", event.Task.UserID)
+	}
+}
+
+func (n *NotificationUseCaseImpl) SendDueDateReminders(ctx context.Context, window time.Duration) error {
+	tasks, err := n.taskRepository.GetAllTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := n.now()
+	n.mu.Lock()
+	for key, due := range n.reminded {
+		if due.Before(now) {
+			delete(n.reminded, key)
+		}
+	}
+	n.mu.Unlock()
+
+	for _, task := range tasks {
+		if task.Assignee == "" || task.DueDate.IsZero() || task.Status == "done" {
+			continue
+		}
+		if task.DueDate.Before(now) || task.DueDate.After(now.Add(window)) {
+			continue
+		}
+
+		key := fmt.Sprintf("%d/%d", task.UserID, task.DueDate.Unix())
+		n.mu.Lock()
+		_, alreadySent := n.reminded[key]
+		n.mu.Unlock()
+		if alreadySent {
+			continue
+		}
+
+		err := n.notifyAssignee(ctx, task, func(user domain.User) error {
+			return n.notifier.NotifyDueSoon(user, task)
+		})
+		if err != nil {
+			infrastructure.LoggerFrom(ctx).Error("failed to send due date reminder", "error", err, "task_id", task.ID.Hex(), "task_number", task.UserID)
+			continue
+		}
+
+		n.mu.Lock()
+		n.reminded[key] = task.DueDate
+		n.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (n *NotificationUseCaseImpl) notifyAssignee(ctx context.Context, task domain.Task, notify func(user domain.User) error) error {
+	user, err := n.userRepository.GetUserByUsername(ctx, task.Assignee)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+	return notify(user)
+}
@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotificationUseCase_AssignmentOnCreate(t *testing.T) {
+	users := new(MockUserRepository)
+	tasks := new(MockTaskRepository)
+	notifier := new(MockNotifier)
+	uc := NewNotificationUseCase(users, tasks, notifier)
+
+	bob := domain.User{UserName: "bob", Email: "bob@example.com"}
+	task := domain.Task{UserID: 1, Title: "t", Assignee: "bob"}
+	users.On("GetUserByUsername", "bob").Return(bob, nil).Once()
+	notifier.On("NotifyAssignment", bob, task).Return(nil).Once()
+
+	uc.Publish(domain.NewTaskEvent(domain.EventTaskCreated, task))
+	notifier.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_UpdateOnlyNotifiesOnReassignment(t *testing.T) {
+	users := new(MockUserRepository)
+	tasks := new(MockTaskRepository)
+	notifier := new(MockNotifier)
+	uc := NewNotificationUseCase(users, tasks, notifier)
+
+	unchanged := domain.Task{UserID: 1, Title: "t2", Assignee: "bob"}
+	uc.Publish(domain.NewTaskUpdatedEvent(domain.Task{UserID: 1, Title: "t", Assignee: "bob"}, unchanged))
+	notifier.AssertNotCalled(t, "NotifyAssignment", mock.Anything, mock.Anything)
+
+	carol := domain.User{UserName: "carol", Email: "carol@example.com"}
+	reassigned := domain.Task{UserID: 1, Title: "t", Assignee: "carol"}
+	users.On("GetUserByUsername", "carol").Return(carol, nil).Once()
+	notifier.On("NotifyAssignment", carol, reassigned).Return(nil).Once()
+
+	uc.Publish(domain.NewTaskUpdatedEvent(unchanged, reassigned))
+	notifier.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_CommentNotifiesAssignee(t *testing.T) {
+	users := new(MockUserRepository)
+	tasks := new(MockTaskRepository)
+	notifier := new(MockNotifier)
+	uc := NewNotificationUseCase(users, tasks, notifier)
+
+	bob := domain.User{UserName: "bob", Email: "bob@example.com"}
+	task := domain.Task{UserID: 1, Assignee: "bob"}
+	comment := domain.Comment{TaskID: 1, Author: "alice", Body: "hi"}
+	users.On("GetUserByUsername", "bob").Return(bob, nil).Once()
+	notifier.On("NotifyComment", bob, task, comment).Return(nil).Once()
+
+	uc.Publish(domain.NewCommentEvent(task, comment))
+
+	// the assignee commenting on their own task is not notified
+	uc.Publish(domain.NewCommentEvent(task, domain.Comment{TaskID: 1, Author: "bob", Body: "done"}))
+	notifier.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_SkipsUsersWithoutEmail(t *testing.T) {
+	users := new(MockUserRepository)
+	tasks := new(MockTaskRepository)
+	notifier := new(MockNotifier)
+	uc := NewNotificationUseCase(users, tasks, notifier)
+
+	users.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob"}, nil).Once()
+
+	uc.Publish(domain.NewTaskEvent(domain.EventTaskCreated, domain.Task{Assignee: "bob"}))
+	notifier.AssertNotCalled(t, "NotifyAssignment", mock.Anything, mock.Anything)
+}
+
+func TestNotificationUseCase_SendDueDateReminders(t *testing.T) {
+	users := new(MockUserRepository)
+	tasks := new(MockTaskRepository)
+	notifier := new(MockNotifier)
+	uc := NewNotificationUseCase(users, tasks, notifier)
+
+	now := time.Now()
+	dueSoon := domain.Task{UserID: 1, Assignee: "bob", DueDate: now.Add(2 * time.Hour), Status: "open"}
+	dueLater := domain.Task{UserID: 2, Assignee: "bob", DueDate: now.Add(72 * time.Hour), Status: "open"}
+	overdue := domain.Task{UserID: 3, Assignee: "bob", DueDate: now.Add(-time.Hour), Status: "open"}
+	finished := domain.Task{UserID: 4, Assignee: "bob", DueDate: now.Add(time.Hour), Status: "done"}
+	unassigned := domain.Task{UserID: 5, DueDate: now.Add(time.Hour), Status: "open"}
+
+	bob := domain.User{UserName: "bob", Email: "bob@example.com"}
+	tasks.On("GetAllTasks").Return([]domain.Task{dueSoon, dueLater, overdue, finished, unassigned}, nil).Twice()
+	users.On("GetUserByUsername", "bob").Return(bob, nil).Once()
+	notifier.On("NotifyDueSoon", bob, dueSoon).Return(nil).Once()
+
+	assert.NoError(t, uc.SendDueDateReminders(context.Background(), 24*time.Hour))
+	// a second run does not remind about the same due date again
+	assert.NoError(t, uc.SendDueDateReminders(context.Background(), 24*time.Hour))
+	notifier.AssertExpectations(t)
+	tasks.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_ForgetsRemindersOncePastDue(t *testing.T) {
+	users := new(MockUserRepository)
+	tasks := new(MockTaskRepository)
+	notifier := new(MockNotifier)
+	uc := NewNotificationUseCase(users, tasks, notifier).(*NotificationUseCaseImpl)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	bob := domain.User{UserName: "bob", Email: "bob@example.com"}
+	dueSoon := domain.Task{UserID: 1, Assignee: "bob", DueDate: now.Add(2 * time.Hour), Status: "open"}
+	tasks.On("GetAllTasks").Return([]domain.Task{dueSoon}, nil).Twice()
+	users.On("GetUserByUsername", "bob").Return(bob, nil).Once()
+	notifier.On("NotifyDueSoon", bob, dueSoon).Return(nil).Once()
+
+	assert.NoError(t, uc.SendDueDateReminders(context.Background(), 24*time.Hour))
+	assert.Len(t, uc.reminded, 1)
+
+	now = now.Add(3 * time.Hour)
+	assert.NoError(t, uc.SendDueDateReminders(context.Background(), 24*time.Hour))
+	assert.Empty(t, uc.reminded)
+	notifier.AssertExpectations(t)
+}
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+)
+
+type RoleUseCaseImpl struct {
+	roleRepository domain.RoleRepository
+	userRepository domain.UserRepository
+	publisher      domain.EventPublisher
+}
+
+func NewRoleUseCase(
+	roleRepository domain.RoleRepository,
+	userRepository domain.UserRepository,
+	publisher domain.EventPublisher,
+) domain.RoleUseCase {
+	return &RoleUseCaseImpl{
+		roleRepository: roleRepository,
+		userRepository: userRepository,
+		publisher:      publisher,
+	}
+}
+
+// GetRole answers built-in roles without a database round trip, since the
+// auth middleware resolves the caller's role on every request.
+func (r *RoleUseCaseImpl) GetRole(ctx context.Context, name string) (domain.Role, error) {
+	if role, ok := domain.BuiltInRole(name); ok {
+		return role, nil
+	}
+	return r.roleRepository.GetRole(ctx, name)
+}
+
+func (r *RoleUseCaseImpl) GetAllRoles(ctx context.Context) ([]domain.Role, error) {
+	custom, err := r.roleRepository.GetAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(domain.BuiltInRoles(), custom...), nil
+}
+
+func (r *RoleUseCaseImpl) CreateRole(ctx context.Context, role domain.Role) (domain.Role, error) {
+	role.BuiltIn = false
+	if err := role.Validate(); err != nil {
+		return domain.Role{}, err
+	}
+	if err := r.roleRepository.CreateRole(ctx, role); err != nil {
+		return domain.Role{}, err
+	}
+	audit(ctx, "role.create", "role", role.Name, "permissions", role.Permissions)
+	return role, nil
+}
+
+func (r *RoleUseCaseImpl) UpdateRole(ctx context.Context, name string, permissions []domain.Permission) (domain.Role, error) {
+	role := domain.Role{Name: name, Permissions: permissions}
+	if err := role.Validate(); err != nil {
+		return domain.Role{}, err
+	}
+	if err := r.roleRepository.UpdateRole(ctx, role); err != nil {
+		return domain.Role{}, err
+	}
+	audit(ctx, "role.update", "role", role.Name, "permissions", role.Permissions)
+	return role, nil
+}
+
+func (r *RoleUseCaseImpl) DeleteRole(ctx context.Context, name string) error {
+	if _, ok := domain.BuiltInRole(name); ok {
+		return domain.ErrBuiltInRole
+	}
+	// A user left with a deleted role would silently lose every permission.
+	assigned, err := r.userRepository.CountUsersWithRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if assigned > 0 {
+		return domain.ErrRoleInUse
+	}
+	if err := r.roleRepository.DeleteRole(ctx, name); err != nil {
+		return err
+	}
+	audit(ctx, "role.delete", "role", name)
+	return nil
+}
+
+func (r *RoleUseCaseImpl) AssignRole(ctx context.Context, username, role string) error {
+	target, err := r.GetRole(ctx, role)
+	if err != nil {
+		return err
+	}
+	if err := r.checkGrant(ctx, target); err != nil {
+		return err
+	}
+	if role != domain.RoleAdmin {
+		user, err := r.userRepository.GetUserByUsername(ctx, username)
+		if err != nil {
+			return err
+		}
+		if err := keepEnabledAdmin(ctx, r.userRepository, user); err != nil {
+			return err
+		}
+	}
+	if err := r.userRepository.SetUserRole(ctx, username, role); err != nil {
+		return err
+	}
+	audit(ctx, "user.role_assign", "username", username, "role", role)
+
+	if role == domain.RoleAdmin {
+		r.publisher.Publish(domain.NewUserEvent(domain.EventUserPromoted, domain.User{UserName: username, Role: role}))
+	}
+	return nil
+}
+
+// checkGrant refuses to hand out a role with a permission the caller lacks,
+// so user:promote alone cannot be turned into Admin. Calls without a caller
+// come from the server itself and are not limited.
+func (r *RoleUseCaseImpl) checkGrant(ctx context.Context, target domain.Role) error {
+	claims, ok := infrastructure.ClaimsFrom(ctx)
+	if !ok {
+		return nil
+	}
+	caller, err := r.GetRole(ctx, claims.Role)
+	if err != nil && !errors.Is(err, domain.ErrRoleNotFound) {
+		return err
+	}
+	for _, permission := range target.Permissions {
+		if !caller.Can(permission) {
+			audit(ctx, "user.role_assign_refused", "role", target.Name, "missing", permission)
+			return domain.ErrRoleEscalation
+		}
+	}
+	return nil
+}
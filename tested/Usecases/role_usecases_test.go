@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRoleUseCase_GetRole_BuiltInWithoutRepository(t *testing.T) {
+	roles := new(MockRoleRepository)
+	uc := NewRoleUseCase(roles, new(MockUserRepository), new(MockEventPublisher))
+
+	admin, err := uc.GetRole(context.Background(), domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.True(t, admin.BuiltIn)
+	assert.True(t, admin.Can(domain.PermissionRoleManage))
+
+	roles.On("GetRole", "triager").Return(domain.Role{Name: "triager", Permissions: []domain.Permission{domain.PermissionTaskUpdate}}, nil).Once()
+	triager, err := uc.GetRole(context.Background(), "triager")
+	assert.NoError(t, err)
+	assert.True(t, triager.Can(domain.PermissionTaskUpdate))
+	roles.AssertExpectations(t)
+}
+
+func TestRoleUseCase_GetAllRoles_BuiltInFirst(t *testing.T) {
+	roles := new(MockRoleRepository)
+	uc := NewRoleUseCase(roles, new(MockUserRepository), new(MockEventPublisher))
+	roles.On("GetAllRoles").Return([]domain.Role{{Name: "triager"}}, nil).Once()
+
+	all, err := uc.GetAllRoles(context.Background())
+	assert.NoError(t, err)
+	var names []string
+	for _, role := range all {
+		names = append(names, role.Name)
+	}
+	assert.Equal(t, []string{domain.RoleAdmin, domain.RoleUser, "triager"}, names)
+}
+
+func TestRoleUseCase_CreateAndUpdate(t *testing.T) {
+	roles := new(MockRoleRepository)
+	uc := NewRoleUseCase(roles, new(MockUserRepository), new(MockEventPublisher))
+	ctx := context.Background()
+
+	_, err := uc.CreateRole(ctx, domain.Role{Name: domain.RoleAdmin})
+	assert.ErrorIs(t, err, domain.ErrBuiltInRole)
+	_, err = uc.CreateRole(ctx, domain.Role{Name: "ops", Permissions: []domain.Permission{"everything"}})
+	assert.ErrorIs(t, err, domain.ErrUnknownPermission)
+
+	want := domain.Role{Name: "ops", Permissions: []domain.Permission{domain.PermissionTaskCreate, domain.PermissionWebhookManage}}
+	roles.On("CreateRole", want).Return(nil).Once()
+	created, err := uc.CreateRole(ctx, domain.Role{Name: "ops", Permissions: []domain.Permission{domain.PermissionWebhookManage, domain.PermissionTaskCreate}, BuiltIn: true})
+	assert.NoError(t, err)
+	assert.Equal(t, want, created, "permissions sorted, built_in ignored")
+
+	roles.On("UpdateRole", domain.Role{Name: "ops", Permissions: []domain.Permission{}}).Return(domain.ErrRoleNotFound).Once()
+	_, err = uc.UpdateRole(ctx, "ops", nil)
+	assert.ErrorIs(t, err, domain.ErrRoleNotFound)
+
+	_, err = uc.UpdateRole(ctx, domain.RoleUser, []domain.Permission{domain.PermissionTaskCreate})
+	assert.ErrorIs(t, err, domain.ErrBuiltInRole)
+	roles.AssertExpectations(t)
+}
+
+func TestRoleUseCase_DeleteRole(t *testing.T) {
+	roles := new(MockRoleRepository)
+	users := new(MockUserRepository)
+	uc := NewRoleUseCase(roles, users, new(MockEventPublisher))
+	ctx := context.Background()
+
+	assert.ErrorIs(t, uc.DeleteRole(ctx, domain.RoleAdmin), domain.ErrBuiltInRole)
+
+	users.On("CountUsersWithRole", "ops").Return(int64(2), nil).Once()
+	assert.ErrorIs(t, uc.DeleteRole(ctx, "ops"), domain.ErrRoleInUse)
+	roles.AssertNotCalled(t, "DeleteRole", mock.Anything)
+
+	users.On("CountUsersWithRole", "ops").Return(int64(0), nil).Once()
+	roles.On("DeleteRole", "ops").Return(nil).Once()
+	assert.NoError(t, uc.DeleteRole(ctx, "ops"))
+	roles.AssertExpectations(t)
+}
+
+func TestRoleUseCase_AssignRole(t *testing.T) {
+	roles := new(MockRoleRepository)
+	users := new(MockUserRepository)
+	publisher := new(MockEventPublisher)
+	uc := NewRoleUseCase(roles, users, publisher)
+	ctx := context.Background()
+
+	roles.On("GetRole", "ghost").Return(domain.Role{}, domain.ErrRoleNotFound).Once()
+	assert.ErrorIs(t, uc.AssignRole(ctx, "bob", "ghost"), domain.ErrRoleNotFound)
+	users.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything)
+
+	users.On("GetUserByUsername", "nobody").Return(domain.User{}, domain.ErrUserNotFound).Once()
+	assert.ErrorIs(t, uc.AssignRole(ctx, "nobody", domain.RoleUser), domain.ErrUserNotFound)
+
+	users.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Role: domain.RoleAdmin}, nil).Once()
+	users.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(1), nil).Once()
+	assert.ErrorIs(t, uc.AssignRole(ctx, "root", domain.RoleUser), domain.ErrLastAdmin)
+	users.AssertNotCalled(t, "SetUserRole", "root", mock.Anything)
+
+	users.On("SetUserRole", "bob", domain.RoleAdmin).Return(nil).Once()
+	publisher.On("Publish", mock.MatchedBy(func(e domain.Event) bool { return e.Type == domain.EventUserPromoted })).Once()
+	assert.NoError(t, uc.AssignRole(ctx, "bob", domain.RoleAdmin))
+
+	users.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestRoleUseCase_AssignRoleRefusesPermissionsTheCallerLacks(t *testing.T) {
+	roles := new(MockRoleRepository)
+	users := new(MockUserRepository)
+	uc := NewRoleUseCase(roles, users, new(MockEventPublisher))
+	promoter := domain.Role{Name: "promoter", Permissions: []domain.Permission{domain.PermissionUserPromote, domain.PermissionUserList}}
+	viewer := domain.Role{Name: "viewer", Permissions: []domain.Permission{domain.PermissionUserList}}
+	ctx := infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "pat", Role: "promoter"})
+
+	roles.On("GetRole", "promoter").Return(promoter, nil)
+	assert.ErrorIs(t, uc.AssignRole(ctx, "pat", domain.RoleAdmin), domain.ErrRoleEscalation)
+	users.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything)
+
+	roles.On("GetRole", "viewer").Return(viewer, nil).Once()
+	users.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob", Role: domain.RoleUser}, nil).Once()
+	users.On("SetUserRole", "bob", "viewer").Return(nil).Once()
+	assert.NoError(t, uc.AssignRole(ctx, "bob", "viewer"), "a subset of the caller's permissions")
+
+	// A caller whose role has since been deleted holds nothing.
+	ctx = infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "old", Role: "gone"})
+	roles.On("GetRole", "gone").Return(domain.Role{}, domain.ErrRoleNotFound).Once()
+	roles.On("GetRole", "viewer").Return(viewer, nil).Once()
+	assert.ErrorIs(t, uc.AssignRole(ctx, "bob", "viewer"), domain.ErrRoleEscalation)
+
+	users.AssertExpectations(t)
+}
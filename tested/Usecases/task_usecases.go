@@ -1,41 +1,97 @@
 package usecases
 
 import (
+	"context"
 	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
 )
 
 type TaskUseCaseImpl struct {
 	taskRepository domain.TaskRepository
+	publisher      domain.EventPublisher
+	authorizer     domain.TaskAuthorizer
 }
 
-func NewTaskUseCase(taskRepository domain.TaskRepository) domain.TaskUseCase {
+func NewTaskUseCase(taskRepository domain.TaskRepository, publisher domain.EventPublisher, authorizer domain.TaskAuthorizer) domain.TaskUseCase {
 	return &TaskUseCaseImpl{
 		taskRepository: taskRepository,
+		publisher:      publisher,
+		authorizer:     authorizer,
 	}
 }
 
-func (t *TaskUseCaseImpl) GetAllTasks() ([]domain.Task, error) {
-	return t.taskRepository.GetAllTasks()
+func (t *TaskUseCaseImpl) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
+	return t.taskRepository.GetAllTasks(ctx)
 }
 
-func (t *TaskUseCaseImpl) GetTaskByID(userID int) (domain.Task, error) {
-	return t.taskRepository.GetTaskByID(userID)
+func (t *TaskUseCaseImpl) GetTaskByID(ctx context.Context, userID int) (domain.Task, error) {
+	return t.taskRepository.GetTaskByID(ctx, userID)
 }
 
-func (t *TaskUseCaseImpl) CreateTask(task domain.Task) error {
+func (t *TaskUseCaseImpl) CreateTask(ctx context.Context, task domain.Task) (domain.Task, error) {
 	if err := task.Validate(); err != nil {
-		return err
+		return domain.Task{}, err
+	}
+
+	task.CreatedBy = ""
+	if claims, ok := infrastructure.ClaimsFrom(ctx); ok {
+		task.CreatedBy = claims.Username
+	}
+	if err := t.authorizer.AuthorizeTask(ctx, domain.PermissionTaskCreate, task, nil); err != nil {
+		return domain.Task{}, err
+	}
+
+	created, err := t.taskRepository.CreateTask(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
 	}
-	return t.taskRepository.CreateTask(task)
+
+	audit(ctx, "task.create", "task_id", created.UserID)
+	t.publisher.Publish(domain.NewTaskEvent(domain.EventTaskCreated, created))
+	return created, nil
 }
 
-func (t *TaskUseCaseImpl) UpdateTask(userID int, task domain.Task) (domain.Task, error) {
+func (t *TaskUseCaseImpl) UpdateTask(ctx context.Context, userID int, task domain.Task) (domain.Task, error) {
 	if err := task.Validate(); err != nil {
 		return domain.Task{}, err
 	}
-	return t.taskRepository.UpdateTask(userID, task)
+
+	previous, err := t.taskRepository.GetTaskByID(ctx, userID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	task.CreatedBy = previous.CreatedBy
+	if err := t.authorizer.AuthorizeTask(ctx, domain.PermissionTaskUpdate, previous, &task); err != nil {
+		return domain.Task{}, err
+	}
+
+	updated, err := t.taskRepository.UpdateTask(ctx, userID, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	audit(ctx, "task.update", "task_id", userID)
+	published := updated
+	published.ID = previous.ID
+	published.UserID = userID
+	t.publisher.Publish(domain.NewTaskUpdatedEvent(previous, published))
+	return updated, nil
 }
 
-func (t *TaskUseCaseImpl) DeleteTask(userID int) error {
-	return t.taskRepository.DeleteTask(userID)
+func (t *TaskUseCaseImpl) DeleteTask(ctx context.Context, userID int) error {
+	task, err := t.taskRepository.GetTaskByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := t.authorizer.AuthorizeTask(ctx, domain.PermissionTaskDelete, task, nil); err != nil {
+		return err
+	}
+
+	if err := t.taskRepository.DeleteTask(ctx, userID); err != nil {
+		return err
+	}
+
+	audit(ctx, "task.delete", "task_id", userID)
+	t.publisher.Publish(domain.NewTaskEvent(domain.EventTaskDeleted, domain.Task{UserID: userID}))
+	return nil
 }
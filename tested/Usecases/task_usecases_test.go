@@ -1,24 +1,28 @@
 package usecases
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTaskUseCase_GetAllTasks(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
 	expected := []domain.Task{{UserID: 1, Title: "t1"}, {UserID: 2, Title: "t2"}}
 	repo.On("GetAllTasks").Return(expected, nil).Once()
 
-	got, err := uc.GetAllTasks()
+	got, err := uc.GetAllTasks(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, got)
 	repo.AssertExpectations(t)
@@ -26,11 +30,12 @@ func TestTaskUseCase_GetAllTasks(t *testing.T) {
 
 func TestTaskUseCase_GetTaskByID(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
 	repo.On("GetTaskByID", 7).Return(domain.Task{UserID: 7, Title: "x"}, nil).Once()
 
-	got, err := uc.GetTaskByID(7)
+	got, err := uc.GetTaskByID(context.Background(), 7)
 	assert.NoError(t, err)
 	assert.Equal(t, 7, got.UserID)
 	repo.AssertExpectations(t)
@@ -38,80 +43,183 @@ func TestTaskUseCase_GetTaskByID(t *testing.T) {
 
 func TestTaskUseCase_GetTaskByID_NotFound(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
 	repo.On("GetTaskByID", 999).Return(domain.Task{}, errors.New("not found")).Once()
 
-	_, err := uc.GetTaskByID(999)
+	_, err := uc.GetTaskByID(context.Background(), 999)
 	assert.Error(t, err)
 	repo.AssertExpectations(t)
 }
 
 func TestTaskUseCase_CreateTask_Validation(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
-	err := uc.CreateTask(domain.Task{Title: "", Description: "d"})
+	_, err := uc.CreateTask(context.Background(), domain.Task{Title: "", Description: "d"})
 	assert.ErrorIs(t, err, domain.ErrInvalidTaskTitle)
 	repo.AssertNotCalled(t, "CreateTask", mock.Anything)
 
-	err = uc.CreateTask(domain.Task{Title: "t", Description: ""})
+	_, err = uc.CreateTask(context.Background(), domain.Task{Title: "t", Description: ""})
 	assert.ErrorIs(t, err, domain.ErrInvalidTaskDescription)
 	repo.AssertNotCalled(t, "CreateTask", mock.Anything)
 }
 
 func TestTaskUseCase_CreateTask_Success(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
 	task := domain.Task{UserID: 1, Title: "title", Description: "desc", DueDate: time.Now(), Status: "open"}
-	repo.On("CreateTask", mock.Anything).Return(nil).Once()
+	repo.On("CreateTask", mock.Anything).Return(task, nil).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskCreated && e.Task.UserID == 1 && e.ID != ""
+	})).Once()
 
-	err := uc.CreateTask(task)
+	created, err := uc.CreateTask(context.Background(), task)
 	assert.NoError(t, err)
+	assert.Equal(t, task, created)
 	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
 }
 
 func TestTaskUseCase_UpdateTask_Validation(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
-	_, err := uc.UpdateTask(1, domain.Task{Title: "", Description: "d"})
+	_, err := uc.UpdateTask(context.Background(), 1, domain.Task{Title: "", Description: "d"})
 	assert.ErrorIs(t, err, domain.ErrInvalidTaskTitle)
 	repo.AssertNotCalled(t, "UpdateTask", mock.Anything)
 
-	_, err = uc.UpdateTask(1, domain.Task{Title: "t", Description: ""})
+	_, err = uc.UpdateTask(context.Background(), 1, domain.Task{Title: "t", Description: ""})
 	assert.ErrorIs(t, err, domain.ErrInvalidTaskDescription)
 	repo.AssertNotCalled(t, "UpdateTask", mock.Anything)
 }
 
 func TestTaskUseCase_UpdateTask_Success(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
-	upd := domain.Task{UserID: 1, Title: "new", Description: "d", Status: "done"}
+	upd := domain.Task{UserID: 1, Title: "new", Description: "d", Status: "done", Assignee: "bob"}
+	repo.On("GetTaskByID", 1).Return(domain.Task{UserID: 1, Title: "old", Assignee: "alice"}, nil).Once()
 	repo.On("UpdateTask", 1, upd).Return(upd, nil).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskUpdated && e.Task.Title == "new" && e.Previous.Assignee == "alice"
+	})).Once()
 
-	got, err := uc.UpdateTask(1, upd)
+	got, err := uc.UpdateTask(context.Background(), 1, upd)
 	assert.NoError(t, err)
 	assert.Equal(t, upd, got)
 	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestTaskUseCase_UpdateTask_NotFound(t *testing.T) {
+	repo := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
+
+	repo.On("GetTaskByID", 9).Return(domain.Task{}, errors.New("task not found")).Once()
+
+	_, err := uc.UpdateTask(context.Background(), 9, domain.Task{Title: "t", Description: "d"})
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
 }
 
 func TestTaskUseCase_DeleteTask(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
 
+	repo.On("GetTaskByID", 2).Return(domain.Task{UserID: 2}, nil).Once()
 	repo.On("DeleteTask", 2).Return(nil).Once()
-	assert.NoError(t, uc.DeleteTask(2))
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskDeleted && e.Task.UserID == 2
+	})).Once()
+	assert.NoError(t, uc.DeleteTask(context.Background(), 2))
 	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
 }
 
 func TestTaskUseCase_DeleteTask_Error(t *testing.T) {
 	repo := new(MockTaskRepository)
-	uc := NewTaskUseCase(repo)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, allowTasks())
+
+	repo.On("GetTaskByID", 3).Return(domain.Task{}, domain.ErrTaskNotFound).Once()
+	assert.ErrorIs(t, uc.DeleteTask(context.Background(), 3), domain.ErrTaskNotFound)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "DeleteTask", 3)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestTaskUseCase_CreateTask_RecordsTheCreator(t *testing.T) {
+	repo := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	authorizer := new(MockTaskAuthorizer)
+	uc := NewTaskUseCase(repo, pub, authorizer)
+
+	ctx := infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "alice"})
+	want := domain.Task{Title: "t", Description: "d", CreatedBy: "alice"}
+	authorizer.On("AuthorizeTask", domain.PermissionTaskCreate, want, (*domain.Task)(nil)).Return(nil).Once()
+	repo.On("CreateTask", want).Return(want, nil).Once()
+	pub.On("Publish", mock.Anything).Once()
+
+	// a creator sent by the client is ignored
+	_, err := uc.CreateTask(ctx, domain.Task{Title: "t", Description: "d", CreatedBy: "mallory"})
+	assert.NoError(t, err)
+	authorizer.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskUseCase_UpdateTask_DeniedByPolicy(t *testing.T) {
+	repo := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	authorizer := new(MockTaskAuthorizer)
+	uc := NewTaskUseCase(repo, pub, authorizer)
+
+	previous := domain.Task{UserID: 4, Title: "t", Description: "d", CreatedBy: "alice"}
+	upd := domain.Task{Title: "t", Description: "d", Status: "done"}
+	repo.On("GetTaskByID", 4).Return(previous, nil).Once()
+	authorizer.On("AuthorizeTask", domain.PermissionTaskUpdate, previous, mock.MatchedBy(func(update *domain.Task) bool {
+		return update.Status == "done" && update.CreatedBy == "alice"
+	})).Return(domain.ErrTaskForbidden).Once()
+
+	_, err := uc.UpdateTask(context.Background(), 4, upd)
+	assert.ErrorIs(t, err, domain.ErrTaskForbidden)
+	authorizer.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestTaskUseCase_DeleteTask_OnlyTheCreatorUnderPolicy(t *testing.T) {
+	policies, err := infrastructure.ParsePolicies([]byte(`
+policies:
+  - name: only-creator-deletes
+    effect: deny
+    actions: [task:delete]
+    when: ["subject.username != resource.created_by"]
+`))
+	require.NoError(t, err)
+
+	repo := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := NewTaskUseCase(repo, pub, infrastructure.NewPolicyEngine(policies))
+
+	repo.On("GetTaskByID", 5).Return(domain.Task{UserID: 5, CreatedBy: "alice"}, nil)
+	repo.On("DeleteTask", 5).Return(nil).Once()
+	pub.On("Publish", mock.Anything).Once()
+
+	bob := infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "bob"})
+	assert.ErrorIs(t, uc.DeleteTask(bob, 5), domain.ErrTaskForbidden)
+	repo.AssertNotCalled(t, "DeleteTask", 5)
 
-	repo.On("DeleteTask", 3).Return(errors.New("not found")).Once()
-	assert.Error(t, uc.DeleteTask(3))
+	alice := infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "alice"})
+	assert.NoError(t, uc.DeleteTask(alice, 5))
 	repo.AssertExpectations(t)
 }
@@ -0,0 +1,260 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the use case spans.
+const tracerName = "task-manager/Usecases"
+
+// startSpan opens a span for a use case call; the returned function ends it,
+// marking it failed when the call returned an error.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+type tracedTaskUseCase struct {
+	next domain.TaskUseCase
+}
+
+// TraceTaskUseCase wraps every call to next in a span.
+func TraceTaskUseCase(next domain.TaskUseCase) domain.TaskUseCase {
+	return &tracedTaskUseCase{next: next}
+}
+
+func (u *tracedTaskUseCase) GetAllTasks(ctx context.Context) (result []domain.Task, err error) {
+	ctx, done := startSpan(ctx, "TaskUseCase.GetAllTasks")
+	defer func() { done(err) }()
+	return u.next.GetAllTasks(ctx)
+}
+
+func (u *tracedTaskUseCase) GetTaskByID(ctx context.Context, userID int) (result domain.Task, err error) {
+	ctx, done := startSpan(ctx, "TaskUseCase.GetTaskByID", attribute.Int("task.id", userID))
+	defer func() { done(err) }()
+	return u.next.GetTaskByID(ctx, userID)
+}
+
+func (u *tracedTaskUseCase) CreateTask(ctx context.Context, task domain.Task) (result domain.Task, err error) {
+	ctx, done := startSpan(ctx, "TaskUseCase.CreateTask")
+	defer func() { done(err) }()
+	return u.next.CreateTask(ctx, task)
+}
+
+func (u *tracedTaskUseCase) UpdateTask(ctx context.Context, userID int, task domain.Task) (result domain.Task, err error) {
+	ctx, done := startSpan(ctx, "TaskUseCase.UpdateTask", attribute.Int("task.id", userID))
+	defer func() { done(err) }()
+	return u.next.UpdateTask(ctx, userID, task)
+}
+
+func (u *tracedTaskUseCase) DeleteTask(ctx context.Context, userID int) (err error) {
+	ctx, done := startSpan(ctx, "TaskUseCase.DeleteTask", attribute.Int("task.id", userID))
+	defer func() { done(err) }()
+	return u.next.DeleteTask(ctx, userID)
+}
+
+type tracedUserUseCase struct {
+	next domain.UserUseCase
+}
+
+// TraceUserUseCase wraps every call to next in a span.
+func TraceUserUseCase(next domain.UserUseCase) domain.UserUseCase {
+	return &tracedUserUseCase{next: next}
+}
+
+func (u *tracedUserUseCase) RegisterUser(ctx context.Context, username, password, email string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.RegisterUser")
+	defer func() { done(err) }()
+	return u.next.RegisterUser(ctx, username, password, email)
+}
+
+func (u *tracedUserUseCase) LoginUser(ctx context.Context, username, password, ip string) (result domain.TokenPair, err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.LoginUser")
+	defer func() { done(err) }()
+	return u.next.LoginUser(ctx, username, password, ip)
+}
+
+func (u *tracedUserUseCase) RefreshToken(ctx context.Context, refreshToken string) (result domain.TokenPair, err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.RefreshToken")
+	defer func() { done(err) }()
+	return u.next.RefreshToken(ctx, refreshToken)
+}
+
+func (u *tracedUserUseCase) Logout(ctx context.Context, tokenID, sessionID string, expiresAt time.Time) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.Logout")
+	defer func() { done(err) }()
+	return u.next.Logout(ctx, tokenID, sessionID, expiresAt)
+}
+
+func (u *tracedUserUseCase) GetUser(ctx context.Context, username string) (result domain.User, err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.GetUser")
+	defer func() { done(err) }()
+	return u.next.GetUser(ctx, username)
+}
+
+func (u *tracedUserUseCase) GetAllUsers(ctx context.Context) (result []domain.User, err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.GetAllUsers")
+	defer func() { done(err) }()
+	return u.next.GetAllUsers(ctx)
+}
+
+func (u *tracedUserUseCase) PromoteUser(ctx context.Context, username string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.PromoteUser")
+	defer func() { done(err) }()
+	return u.next.PromoteUser(ctx, username)
+}
+
+func (u *tracedUserUseCase) UnlockUser(ctx context.Context, username string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.UnlockUser")
+	defer func() { done(err) }()
+	return u.next.UnlockUser(ctx, username)
+}
+
+func (u *tracedUserUseCase) ListUsers(ctx context.Context, query domain.UserQuery) (result domain.UserPage, err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.ListUsers")
+	defer func() { done(err) }()
+	return u.next.ListUsers(ctx, query)
+}
+
+func (u *tracedUserUseCase) DemoteUser(ctx context.Context, username string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.DemoteUser")
+	defer func() { done(err) }()
+	return u.next.DemoteUser(ctx, username)
+}
+
+func (u *tracedUserUseCase) DisableUser(ctx context.Context, username string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.DisableUser")
+	defer func() { done(err) }()
+	return u.next.DisableUser(ctx, username)
+}
+
+func (u *tracedUserUseCase) EnableUser(ctx context.Context, username string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.EnableUser")
+	defer func() { done(err) }()
+	return u.next.EnableUser(ctx, username)
+}
+
+func (u *tracedUserUseCase) DeleteUser(ctx context.Context, username string) (err error) {
+	ctx, done := startSpan(ctx, "UserUseCase.DeleteUser")
+	defer func() { done(err) }()
+	return u.next.DeleteUser(ctx, username)
+}
+
+type tracedCommentUseCase struct {
+	next domain.CommentUseCase
+}
+
+// TraceCommentUseCase wraps every call to next in a span.
+func TraceCommentUseCase(next domain.CommentUseCase) domain.CommentUseCase {
+	return &tracedCommentUseCase{next: next}
+}
+
+func (u *tracedCommentUseCase) AddComment(ctx context.Context, taskID int, author, body string) (result domain.Comment, err error) {
+	ctx, done := startSpan(ctx, "CommentUseCase.AddComment", attribute.Int("task.id", taskID))
+	defer func() { done(err) }()
+	return u.next.AddComment(ctx, taskID, author, body)
+}
+
+func (u *tracedCommentUseCase) GetComments(ctx context.Context, taskID int) (result []domain.Comment, err error) {
+	ctx, done := startSpan(ctx, "CommentUseCase.GetComments", attribute.Int("task.id", taskID))
+	defer func() { done(err) }()
+	return u.next.GetComments(ctx, taskID)
+}
+
+type tracedWebhookUseCase struct {
+	next domain.WebhookUseCase
+}
+
+// TraceWebhookUseCase wraps every call to next in a span.
+func TraceWebhookUseCase(next domain.WebhookUseCase) domain.WebhookUseCase {
+	return &tracedWebhookUseCase{next: next}
+}
+
+func (u *tracedWebhookUseCase) CreateWebhook(ctx context.Context, webhook domain.Webhook) (result domain.Webhook, err error) {
+	ctx, done := startSpan(ctx, "WebhookUseCase.CreateWebhook")
+	defer func() { done(err) }()
+	return u.next.CreateWebhook(ctx, webhook)
+}
+
+func (u *tracedWebhookUseCase) GetAllWebhooks(ctx context.Context) (result []domain.Webhook, err error) {
+	ctx, done := startSpan(ctx, "WebhookUseCase.GetAllWebhooks")
+	defer func() { done(err) }()
+	return u.next.GetAllWebhooks(ctx)
+}
+
+func (u *tracedWebhookUseCase) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, done := startSpan(ctx, "WebhookUseCase.DeleteWebhook", attribute.String("webhook.id", id))
+	defer func() { done(err) }()
+	return u.next.DeleteWebhook(ctx, id)
+}
+
+func (u *tracedWebhookUseCase) GetDeadLetters(ctx context.Context) (result []domain.WebhookDelivery, err error) {
+	ctx, done := startSpan(ctx, "WebhookUseCase.GetDeadLetters")
+	defer func() { done(err) }()
+	return u.next.GetDeadLetters(ctx)
+}
+
+func (u *tracedWebhookUseCase) RedeliverDeadLetter(ctx context.Context, id string) (err error) {
+	ctx, done := startSpan(ctx, "WebhookUseCase.RedeliverDeadLetter", attribute.String("dead_letter.id", id))
+	defer func() { done(err) }()
+	return u.next.RedeliverDeadLetter(ctx, id)
+}
+
+type tracedRoleUseCase struct {
+	next domain.RoleUseCase
+}
+
+// TraceRoleUseCase wraps every call to next in a span.
+func TraceRoleUseCase(next domain.RoleUseCase) domain.RoleUseCase {
+	return &tracedRoleUseCase{next: next}
+}
+
+func (u *tracedRoleUseCase) GetRole(ctx context.Context, name string) (result domain.Role, err error) {
+	ctx, done := startSpan(ctx, "RoleUseCase.GetRole", attribute.String("role", name))
+	defer func() { done(err) }()
+	return u.next.GetRole(ctx, name)
+}
+
+func (u *tracedRoleUseCase) GetAllRoles(ctx context.Context) (result []domain.Role, err error) {
+	ctx, done := startSpan(ctx, "RoleUseCase.GetAllRoles")
+	defer func() { done(err) }()
+	return u.next.GetAllRoles(ctx)
+}
+
+func (u *tracedRoleUseCase) CreateRole(ctx context.Context, role domain.Role) (result domain.Role, err error) {
+	ctx, done := startSpan(ctx, "RoleUseCase.CreateRole", attribute.String("role", role.Name))
+	defer func() { done(err) }()
+	return u.next.CreateRole(ctx, role)
+}
+
+func (u *tracedRoleUseCase) UpdateRole(ctx context.Context, name string, permissions []domain.Permission) (result domain.Role, err error) {
+	ctx, done := startSpan(ctx, "RoleUseCase.UpdateRole", attribute.String("role", name))
+	defer func() { done(err) }()
+	return u.next.UpdateRole(ctx, name, permissions)
+}
+
+func (u *tracedRoleUseCase) DeleteRole(ctx context.Context, name string) (err error) {
+	ctx, done := startSpan(ctx, "RoleUseCase.DeleteRole", attribute.String("role", name))
+	defer func() { done(err) }()
+	return u.next.DeleteRole(ctx, name)
+}
+
+func (u *tracedRoleUseCase) AssignRole(ctx context.Context, username, role string) (err error) {
+	ctx, done := startSpan(ctx, "RoleUseCase.AssignRole", attribute.String("role", role))
+	defer func() { done(err) }()
+	return u.next.AssignRole(ctx, username, role)
+}
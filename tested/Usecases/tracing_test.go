@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	previous := otel.GetTracerProvider()
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func TestTraceTaskUseCase_SpansNestUnderTheCaller(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	repo := new(MockTaskRepository)
+	pub := new(MockEventPublisher)
+	uc := TraceTaskUseCase(NewTaskUseCase(repo, pub, allowTasks()))
+
+	repo.On("GetTaskByID", 7).Return(domain.Task{}, domain.ErrTaskNotFound).Once()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "PUT /v1/tasks/:id")
+	_, err := uc.UpdateTask(ctx, 7, domain.Task{Title: "t", Description: "d"})
+	parent.End()
+	assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "TaskUseCase.UpdateTask", span.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	assert.Contains(t, span.Attributes, attribute.Int("task.id", 7))
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, domain.ErrTaskNotFound.Error(), span.Status.Description)
+}
+
+func TestTraceUserUseCase_SucceedsWithoutRecordingCredentials(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	jwt := new(MockJWTService)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	uc := TraceUserUseCase(NewUserUseCase(repo, pass, jwt, new(MockEventPublisher), logins, guard, refresh, new(MockTokenDenylist), time.Hour))
+
+	user := domain.User{UserName: "bob", Password: "hashed"}
+	repo.On("AuthenticateUser", "bob", mock.Anything).Return(user, nil).Once()
+	pass.On("ComparePassword", "hashed", "plain").Return(nil).Once()
+	jwt.On("GenerateToken", user, mock.Anything).Return("token", nil).Once()
+	refresh.On("CreateRefreshToken", mock.Anything).Return(nil).Once()
+	logins.On("RecordLogin", domain.LoginSucceeded).Once()
+	guard.On("Check", "bob", "192.0.2.1").Return(nil).Once()
+	guard.On("Succeeded", "bob").Once()
+
+	_, err := uc.LoginUser(context.Background(), "bob", "plain", "192.0.2.1")
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "UserUseCase.LoginUser", spans[0].Name)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Empty(t, spans[0].Attributes)
+}
@@ -1,58 +1,348 @@
 package usecases
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
 	domain "task-manager/Domain"
 	infrastructure "task-manager/Infrastructure"
+	"time"
 )
 
 type UserUseCaseImpl struct {
 	userRepository  domain.UserRepository
 	passwordService infrastructure.PasswordService
 	jwtService      infrastructure.JWTService
+	publisher       domain.EventPublisher
+	logins          domain.LoginRecorder
+	guard           domain.LoginGuard
+	refreshTokens   domain.RefreshTokenRepository
+	denylist        domain.TokenDenylist
+	refreshTTL      time.Duration
+	now             func() time.Time
+
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
 func NewUserUseCase(
 	userRepository domain.UserRepository,
 	passwordService infrastructure.PasswordService,
 	jwtService infrastructure.JWTService,
+	publisher domain.EventPublisher,
+	logins domain.LoginRecorder,
+	guard domain.LoginGuard,
+	refreshTokens domain.RefreshTokenRepository,
+	denylist domain.TokenDenylist,
+	refreshTTL time.Duration,
 ) domain.UserUseCase {
 	return &UserUseCaseImpl{
 		userRepository:  userRepository,
 		passwordService: passwordService,
 		jwtService:      jwtService,
+		publisher:       publisher,
+		logins:          logins,
+		guard:           guard,
+		refreshTokens:   refreshTokens,
+		denylist:        denylist,
+		refreshTTL:      refreshTTL,
+		now:             time.Now,
 	}
 }
 
-func (u *UserUseCaseImpl) RegisterUser(username, password string) error {
+func (u *UserUseCaseImpl) RegisterUser(ctx context.Context, username, password, email string) error {
+	if err := domain.ValidateEmail(email); err != nil {
+		return err
+	}
 
 	hashedPassword, err := u.passwordService.HashPassword(password)
 	if err != nil {
 		return err
 	}
 
-	return u.userRepository.RegisterUser(username, hashedPassword)
+	if err := u.userRepository.RegisterUser(ctx, username, hashedPassword, email); err != nil {
+		return err
+	}
+
+	audit(ctx, "user.register", "username", username)
+	u.publisher.Publish(domain.NewUserEvent(domain.EventUserRegistered, domain.User{UserName: username, Role: "user", Email: email}))
+	return nil
 }
 
-func (u *UserUseCaseImpl) LoginUser(username, password string) (string, error) {
+func (u *UserUseCaseImpl) LoginUser(ctx context.Context, username, password, ip string) (domain.TokenPair, error) {
+	if err := u.guard.Check(ctx, username, ip); err != nil {
+		if errors.Is(err, domain.ErrTooManyLoginAttempts) {
+			audit(ctx, "user.login_failed", "username", username, "reason", "locked")
+			u.logins.RecordLogin(domain.LoginLocked)
+		}
+		return domain.TokenPair{}, err
+	}
 
-	user, err := u.userRepository.AuthenticateUser(username, password)
+	user, err := u.userRepository.AuthenticateUser(ctx, username, password)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		// Hash anyway so unknown usernames take as long as wrong passwords.
+		u.passwordService.ComparePassword(u.dummyPasswordHash(), password)
+		audit(ctx, "user.login_failed", "username", username, "reason", "unknown user")
+		u.logins.RecordLogin(domain.LoginUnknownUser)
+		u.guard.Failed(ctx, username, ip)
+		return domain.TokenPair{}, domain.ErrInvalidCredentials
 	}
 
 	err = u.passwordService.ComparePassword(user.Password, password)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		audit(ctx, "user.login_failed", "username", username, "reason", "wrong password")
+		u.logins.RecordLogin(domain.LoginWrongPassword)
+		u.guard.Failed(ctx, username, ip)
+		return domain.TokenPair{}, domain.ErrInvalidCredentials
+	}
+
+	// Checked only once the password matched, so the response does not tell
+	// anyone else whether the account exists.
+	if user.Disabled {
+		audit(ctx, "user.login_failed", "username", username, "reason", "disabled")
+		u.logins.RecordLogin(domain.LoginDisabled)
+		return domain.TokenPair{}, domain.ErrUserDisabled
+	}
+
+	family, err := infrastructure.NewTokenID()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	tokens, err := u.issueTokens(ctx, user, family)
+	if err != nil {
+		return domain.TokenPair{}, err
 	}
 
-	token, err := u.jwtService.GenerateToken(user)
+	audit(ctx, "user.login", "username", username)
+	u.logins.RecordLogin(domain.LoginSucceeded)
+	u.guard.Succeeded(ctx, username)
+	return tokens, nil
+}
+
+func (u *UserUseCaseImpl) RefreshToken(ctx context.Context, refreshToken string) (domain.TokenPair, error) {
+	now := u.now()
+	stored, err := u.refreshTokens.UseRefreshToken(ctx, hashRefreshToken(refreshToken), now)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	if stored.Revoked || !stored.ExpiresAt.After(now) {
+		return domain.TokenPair{}, domain.ErrInvalidRefreshToken
+	}
+	if !stored.UsedAt.IsZero() {
+		// Either the client or whoever stole the token used it already, and
+		// there is no telling which, so neither keeps the session.
+		audit(ctx, "user.refresh_token_reused", "username", stored.Username, "session_id", stored.Family)
+		if err := u.revokeSession(ctx, stored.Family); err != nil {
+			return domain.TokenPair{}, err
+		}
+		return domain.TokenPair{}, domain.ErrInvalidRefreshToken
+	}
+
+	user, err := u.userRepository.GetUserByUsername(ctx, stored.Username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.TokenPair{}, domain.ErrInvalidRefreshToken
+	}
 	if err != nil {
-		return "", err
+		return domain.TokenPair{}, err
+	}
+	if user.Disabled {
+		return domain.TokenPair{}, domain.ErrInvalidRefreshToken
 	}
+	return u.issueTokens(ctx, user, stored.Family)
+}
 
-	return token, nil
+func (u *UserUseCaseImpl) Logout(ctx context.Context, tokenID, sessionID string, expiresAt time.Time) error {
+	if err := u.denylist.RevokeToken(ctx, tokenID, expiresAt); err != nil {
+		return err
+	}
+	if err := u.refreshTokens.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return err
+	}
+	audit(ctx, "user.logout", "session_id", sessionID)
+	return nil
 }
 
-func (u *UserUseCaseImpl) PromoteUser(username string) error {
-	return u.userRepository.PromoteUser(username)
+// issueTokens signs an access token for the session family and stores the
+// refresh token that rotates it.
+func (u *UserUseCaseImpl) issueTokens(ctx context.Context, user domain.User, family string) (domain.TokenPair, error) {
+	accessToken, err := u.jwtService.GenerateToken(user, family)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	refreshToken, err := infrastructure.NewTokenID()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	now := u.now()
+	err = u.refreshTokens.CreateRefreshToken(ctx, domain.RefreshToken{
+		ID:        hashRefreshToken(refreshToken),
+		Family:    family,
+		Username:  user.UserName,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(u.refreshTTL),
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return domain.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// revokeSession stops the family's refresh tokens and, through the "sid"
+// claim, the access tokens already issued in it. Those live shorter than a
+// refresh token, so refreshTTL bounds the denylist entry.
+func (u *UserUseCaseImpl) revokeSession(ctx context.Context, family string) error {
+	if err := u.refreshTokens.RevokeRefreshTokenFamily(ctx, family); err != nil {
+		return err
+	}
+	return u.denylist.RevokeToken(ctx, family, u.now().Add(u.refreshTTL))
+}
+
+// hashRefreshToken is what gets stored, so a leaked collection cannot be
+// replayed.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (u *UserUseCaseImpl) dummyPasswordHash() string {
+	u.dummyHashOnce.Do(func() {
+		u.dummyHash, _ = u.passwordService.HashPassword("not a real password")
+	})
+	return u.dummyHash
+}
+
+func (u *UserUseCaseImpl) GetUser(ctx context.Context, username string) (domain.User, error) {
+	user, err := u.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return domain.User{}, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+func (u *UserUseCaseImpl) GetAllUsers(ctx context.Context) ([]domain.User, error) {
+	return u.userRepository.GetAllUsers(ctx)
+}
+
+func (u *UserUseCaseImpl) PromoteUser(ctx context.Context, username string) error {
+	// Admin holds every permission, so only an Admin may hand it out.
+	if claims, ok := infrastructure.ClaimsFrom(ctx); ok && !claims.IsAdmin() {
+		audit(ctx, "user.promote_refused", "username", username)
+		return domain.ErrRoleEscalation
+	}
+	if err := u.userRepository.PromoteUser(ctx, username); err != nil {
+		return err
+	}
+	audit(ctx, "user.promote", "username", username)
+
+	u.publisher.Publish(domain.NewUserEvent(domain.EventUserPromoted, domain.User{UserName: username, Role: domain.RoleAdmin}))
+	return nil
+}
+
+func (u *UserUseCaseImpl) UnlockUser(ctx context.Context, username string) error {
+	if _, err := u.userRepository.GetUserByUsername(ctx, username); err != nil {
+		return err
+	}
+	if err := u.guard.Unlock(ctx, username); err != nil {
+		return err
+	}
+	audit(ctx, "user.unlock", "username", username)
+	return nil
+}
+
+func (u *UserUseCaseImpl) ListUsers(ctx context.Context, query domain.UserQuery) (domain.UserPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = domain.DefaultUserPageSize
+	}
+	query.Limit = min(query.Limit, domain.MaxUserPageSize)
+	query.Offset = max(query.Offset, 0)
+
+	users, total, err := u.userRepository.ListUsers(ctx, query)
+	if err != nil {
+		return domain.UserPage{}, err
+	}
+	return domain.UserPage{Users: users, Total: total, Offset: query.Offset, Limit: query.Limit}, nil
+}
+
+func (u *UserUseCaseImpl) DemoteUser(ctx context.Context, username string) error {
+	user, err := u.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := keepEnabledAdmin(ctx, u.userRepository, user); err != nil {
+		return err
+	}
+	if err := u.userRepository.SetUserRole(ctx, username, domain.RoleUser); err != nil {
+		return err
+	}
+	audit(ctx, "user.demote", "username", username, "previous_role", user.Role)
+	return nil
+}
+
+func (u *UserUseCaseImpl) DisableUser(ctx context.Context, username string) error {
+	user, err := u.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := keepEnabledAdmin(ctx, u.userRepository, user); err != nil {
+		return err
+	}
+	if err := u.userRepository.SetUserDisabled(ctx, username, true); err != nil {
+		return err
+	}
+	if err := u.refreshTokens.RevokeUserRefreshTokens(ctx, username); err != nil {
+		return err
+	}
+	audit(ctx, "user.disable", "username", username)
+	return nil
+}
+
+func (u *UserUseCaseImpl) EnableUser(ctx context.Context, username string) error {
+	if err := u.userRepository.SetUserDisabled(ctx, username, false); err != nil {
+		return err
+	}
+	audit(ctx, "user.enable", "username", username)
+	return nil
+}
+
+func (u *UserUseCaseImpl) DeleteUser(ctx context.Context, username string) error {
+	user, err := u.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := keepEnabledAdmin(ctx, u.userRepository, user); err != nil {
+		return err
+	}
+	if err := u.userRepository.DeleteUser(ctx, username); err != nil {
+		return err
+	}
+	// Families are keyed by username, so a surviving one would hand tokens
+	// to whoever registers the name next.
+	if err := u.refreshTokens.RevokeUserRefreshTokens(ctx, username); err != nil {
+		return err
+	}
+	audit(ctx, "user.delete", "username", username)
+	return nil
+}
+
+// keepEnabledAdmin returns ErrLastAdmin if user is the only enabled Admin,
+// who must not be demoted, disabled or deleted: nobody would be left to
+// manage users, and only the admin CLI could recover. It counts before the
+// caller writes, without a transaction, so it guards against mistakes rather
+// than against concurrent removals of the last two Admins.
+func keepEnabledAdmin(ctx context.Context, users domain.UserRepository, user domain.User) error {
+	if user.Role != domain.RoleAdmin || user.Disabled {
+		return nil
+	}
+	admins, err := users.CountEnabledUsersWithRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return domain.ErrLastAdmin
+	}
+	return nil
 }
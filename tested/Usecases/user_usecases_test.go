@@ -1,10 +1,16 @@
 package usecases
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"testing"
+	"time"
 
 	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -14,72 +20,419 @@ func TestUserUseCase_RegisterUser_PersistsHashed(t *testing.T) {
 	repo := new(MockUserRepository)
 	pass := new(MockPasswordService)
 	jwt := new(MockJWTService)
-	uc := NewUserUseCase(repo, pass, jwt)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
 
 	pass.On("HashPassword", "plain").Return("hashed", nil).Once()
-	repo.On("RegisterUser", "bob", "hashed").Return(nil).Once()
+	repo.On("RegisterUser", "bob", "hashed", "bob@example.com").Return(nil).Once()
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventUserRegistered && e.User.UserName == "bob" && e.User.Password == ""
+	})).Once()
 
-	err := uc.RegisterUser("bob", "plain")
+	err := uc.RegisterUser(context.Background(), "bob", "plain", "bob@example.com")
 	assert.NoError(t, err)
 	pass.AssertExpectations(t)
 	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestUserUseCase_RegisterUser_InvalidEmail(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	jwt := new(MockJWTService)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
+
+	err := uc.RegisterUser(context.Background(), "bob", "plain", "not-an-email")
+	assert.ErrorIs(t, err, domain.ErrInvalidEmail)
+	pass.AssertNotCalled(t, "HashPassword", mock.Anything)
+	repo.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestUserUseCase_LoginUser_Success(t *testing.T) {
 	repo := new(MockUserRepository)
 	pass := new(MockPasswordService)
 	jwt := new(MockJWTService)
-	uc := NewUserUseCase(repo, pass, jwt)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
 
 	storedUser := domain.User{UserName: "bob", Password: "hashed", Role: "user"}
 	repo.On("AuthenticateUser", "bob", mock.Anything).Return(storedUser, nil).Once()
 	pass.On("ComparePassword", "hashed", "plain").Return(nil).Once()
-	jwt.On("GenerateToken", storedUser).Return("token123", nil).Once()
+	jwt.On("GenerateToken", storedUser, mock.Anything).Return("token123", nil).Once()
+	logins.On("RecordLogin", domain.LoginSucceeded).Once()
+	guard.On("Check", "bob", "192.0.2.1").Return(nil).Once()
+	guard.On("Succeeded", "bob").Once()
+	var stored domain.RefreshToken
+	refresh.On("CreateRefreshToken", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(domain.RefreshToken)
+	}).Return(nil).Once()
 
-	token, err := uc.LoginUser("bob", "plain")
+	tokens, err := uc.LoginUser(context.Background(), "bob", "plain", "192.0.2.1")
 	assert.NoError(t, err)
-	assert.Equal(t, "token123", token)
+	assert.Equal(t, "token123", tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, hashRefreshToken(tokens.RefreshToken), stored.ID, "only the hash is stored")
+	assert.Equal(t, "bob", stored.Username)
+	assert.NotEmpty(t, stored.Family)
+	jwt.AssertCalled(t, "GenerateToken", storedUser, stored.Family)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
 	repo.AssertExpectations(t)
 	pass.AssertExpectations(t)
 	jwt.AssertExpectations(t)
+	refresh.AssertExpectations(t)
+	logins.AssertExpectations(t)
+	guard.AssertExpectations(t)
 }
 
 func TestUserUseCase_LoginUser_InvalidPassword(t *testing.T) {
 	repo := new(MockUserRepository)
 	pass := new(MockPasswordService)
 	jwt := new(MockJWTService)
-	uc := NewUserUseCase(repo, pass, jwt)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
 
 	storedUser := domain.User{UserName: "bob", Password: "hashed", Role: "user"}
 	repo.On("AuthenticateUser", "bob", mock.Anything).Return(storedUser, nil).Once()
 	pass.On("ComparePassword", "hashed", "wrong").Return(errors.New("mismatch")).Once()
+	logins.On("RecordLogin", domain.LoginWrongPassword).Once()
+	guard.On("Check", "bob", "192.0.2.1").Return(nil).Once()
+	guard.On("Failed", "bob", "192.0.2.1").Once()
 
-	_, err := uc.LoginUser("bob", "wrong")
+	var logs bytes.Buffer
+	ctx := infrastructure.WithLogger(context.Background(), infrastructure.NewLogger(&logs, slog.LevelInfo))
+	_, err := uc.LoginUser(ctx, "bob", "wrong", "192.0.2.1")
 	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
-	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything)
+	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+	assert.Equal(t, "user.login_failed", line["action"])
+	assert.Equal(t, "bob", line["username"])
+	assert.NotContains(t, logs.String(), "wrong\"")
+	logins.AssertExpectations(t)
+	guard.AssertExpectations(t)
 }
 
 func TestUserUseCase_LoginUser_UserNotFound(t *testing.T) {
 	repo := new(MockUserRepository)
 	pass := new(MockPasswordService)
 	jwt := new(MockJWTService)
-	uc := NewUserUseCase(repo, pass, jwt)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
 
-	repo.On("AuthenticateUser", "alice", mock.Anything).Return(domain.User{}, errors.New("not found")).Once()
+	repo.On("AuthenticateUser", "alice", mock.Anything).Return(domain.User{}, errors.New("not found")).Twice()
+	logins.On("RecordLogin", domain.LoginUnknownUser).Twice()
+	guard.On("Check", "alice", "192.0.2.1").Return(nil).Twice()
+	guard.On("Failed", "alice", "192.0.2.1").Twice()
+	// The dummy hash is computed once and compared on every miss, so unknown
+	// users cost as much as wrong passwords.
+	pass.On("HashPassword", mock.Anything).Return("dummy", nil).Once()
+	pass.On("ComparePassword", "dummy", "whatever").Return(errors.New("mismatch")).Twice()
 
-	_, err := uc.LoginUser("alice", "whatever")
-	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
-	pass.AssertNotCalled(t, "ComparePassword", mock.Anything, mock.Anything)
-	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything)
+	for range 2 {
+		_, err := uc.LoginUser(context.Background(), "alice", "whatever", "192.0.2.1")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+	pass.AssertExpectations(t)
+	logins.AssertExpectations(t)
+	guard.AssertExpectations(t)
+}
+
+func TestUserUseCase_LoginUser_Locked(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	jwt := new(MockJWTService)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
+
+	guard.On("Check", "bob", "192.0.2.1").Return(domain.ErrTooManyLoginAttempts).Once()
+	logins.On("RecordLogin", domain.LoginLocked).Once()
+
+	_, err := uc.LoginUser(context.Background(), "bob", "plain", "192.0.2.1")
+	assert.ErrorIs(t, err, domain.ErrTooManyLoginAttempts)
+	repo.AssertNotCalled(t, "AuthenticateUser", mock.Anything, mock.Anything)
+	guard.AssertNotCalled(t, "Failed", mock.Anything, mock.Anything)
+	logins.AssertExpectations(t)
+}
+
+func TestUserUseCase_UnlockUser(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	jwt := new(MockJWTService)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
+
+	repo.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob"}, nil).Once()
+	repo.On("GetUserByUsername", "ghost").Return(domain.User{}, domain.ErrUserNotFound).Once()
+	guard.On("Unlock", "bob").Return(nil).Once()
+
+	assert.NoError(t, uc.UnlockUser(context.Background(), "bob"))
+	assert.ErrorIs(t, uc.UnlockUser(context.Background(), "ghost"), domain.ErrUserNotFound)
+	guard.AssertExpectations(t)
 }
 
 func TestUserUseCase_PromoteUser(t *testing.T) {
 	repo := new(MockUserRepository)
 	pass := new(MockPasswordService)
 	jwt := new(MockJWTService)
-	uc := NewUserUseCase(repo, pass, jwt)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
 
 	repo.On("PromoteUser", "bob").Return(nil).Once()
-	assert.NoError(t, uc.PromoteUser("bob"))
+	pub.On("Publish", mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventUserPromoted && e.User.Role == "Admin"
+	})).Once()
+	assert.NoError(t, uc.PromoteUser(context.Background(), "bob"))
+	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestUserUseCase_PromoteUserIsReservedToAdmins(t *testing.T) {
+	repo := new(MockUserRepository)
+	pub := new(MockEventPublisher)
+	uc := NewUserUseCase(repo, new(MockPasswordService), new(MockJWTService), pub, new(MockLoginRecorder), new(MockLoginGuard), new(MockRefreshTokenRepository), new(MockTokenDenylist), time.Hour)
+
+	ctx := infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "pat", Role: "promoter"})
+	assert.ErrorIs(t, uc.PromoteUser(ctx, "pat"), domain.ErrRoleEscalation)
+	repo.AssertNotCalled(t, "PromoteUser", mock.Anything)
+	pub.AssertNotCalled(t, "Publish", mock.Anything)
+
+	repo.On("PromoteUser", "bob").Return(nil).Once()
+	pub.On("Publish", mock.Anything).Once()
+	ctx = infrastructure.WithClaims(context.Background(), &infrastructure.Claims{Username: "root", Role: domain.RoleAdmin})
+	assert.NoError(t, uc.PromoteUser(ctx, "bob"))
+	repo.AssertExpectations(t)
+}
+
+func TestUserUseCase_GetUser_HidesPassword(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	jwt := new(MockJWTService)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
+
+	repo.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob", Password: "hashed"}, nil).Once()
+
+	user, err := uc.GetUser(context.Background(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", user.UserName)
+	assert.Empty(t, user.Password)
+}
+
+func newSessionTestUseCase() (*UserUseCaseImpl, *MockUserRepository, *MockJWTService, *MockRefreshTokenRepository, *MockTokenDenylist) {
+	repo := new(MockUserRepository)
+	jwt := new(MockJWTService)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, new(MockPasswordService), jwt, new(MockEventPublisher), new(MockLoginRecorder), new(MockLoginGuard),
+		refresh, denylist, time.Hour).(*UserUseCaseImpl)
+	return uc, repo, jwt, refresh, denylist
+}
+
+func TestUserUseCase_RefreshToken_Rotates(t *testing.T) {
+	uc, repo, jwt, refresh, _ := newSessionTestUseCase()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	user := domain.User{UserName: "bob", Role: "Admin"}
+	refresh.On("UseRefreshToken", hashRefreshToken("old"), now).
+		Return(domain.RefreshToken{Family: "family-1", Username: "bob", ExpiresAt: now.Add(time.Minute)}, nil).Once()
+	repo.On("GetUserByUsername", "bob").Return(user, nil).Once()
+	jwt.On("GenerateToken", user, "family-1").Return("access", nil).Once()
+	refresh.On("CreateRefreshToken", mock.MatchedBy(func(token domain.RefreshToken) bool {
+		return token.Family == "family-1" && token.ExpiresAt.Equal(now.Add(time.Hour))
+	})).Return(nil).Once()
+
+	tokens, err := uc.RefreshToken(context.Background(), "old")
+	assert.NoError(t, err)
+	assert.Equal(t, "access", tokens.AccessToken)
+	assert.NotEqual(t, "old", tokens.RefreshToken)
+	refresh.AssertExpectations(t)
+	jwt.AssertExpectations(t)
+}
+
+func TestUserUseCase_RefreshToken_ReuseRevokesTheFamily(t *testing.T) {
+	uc, _, jwt, refresh, denylist := newSessionTestUseCase()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	refresh.On("UseRefreshToken", hashRefreshToken("stolen"), now).
+		Return(domain.RefreshToken{Family: "family-1", Username: "bob", ExpiresAt: now.Add(time.Minute), UsedAt: now.Add(-time.Second)}, nil).Once()
+	refresh.On("RevokeRefreshTokenFamily", "family-1").Return(nil).Once()
+	denylist.On("RevokeToken", "family-1", now.Add(time.Hour)).Return(nil).Once()
+
+	_, err := uc.RefreshToken(context.Background(), "stolen")
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	refresh.AssertExpectations(t)
+	denylist.AssertExpectations(t)
+	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+}
+
+func TestUserUseCase_RefreshToken_RejectsExpiredAndRevoked(t *testing.T) {
+	uc, _, jwt, refresh, _ := newSessionTestUseCase()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	refresh.On("UseRefreshToken", hashRefreshToken("expired"), now).
+		Return(domain.RefreshToken{Family: "f", Username: "bob", ExpiresAt: now}, nil).Once()
+	refresh.On("UseRefreshToken", hashRefreshToken("revoked"), now).
+		Return(domain.RefreshToken{Family: "f", Username: "bob", ExpiresAt: now.Add(time.Minute), Revoked: true}, nil).Once()
+	refresh.On("UseRefreshToken", hashRefreshToken("unknown"), now).
+		Return(domain.RefreshToken{}, domain.ErrInvalidRefreshToken).Once()
+
+	for _, token := range []string{"expired", "revoked", "unknown"} {
+		_, err := uc.RefreshToken(context.Background(), token)
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken, token)
+	}
+	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+}
+
+func TestUserUseCase_Logout(t *testing.T) {
+	uc, _, _, refresh, denylist := newSessionTestUseCase()
+	expiresAt := time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC)
+
+	denylist.On("RevokeToken", "jti-1", expiresAt).Return(nil).Once()
+	refresh.On("RevokeRefreshTokenFamily", "family-1").Return(nil).Once()
+
+	assert.NoError(t, uc.Logout(context.Background(), "jti-1", "family-1", expiresAt))
+	denylist.AssertExpectations(t)
+	refresh.AssertExpectations(t)
+}
+
+func TestUserUseCase_LoginUser_Disabled(t *testing.T) {
+	repo := new(MockUserRepository)
+	pass := new(MockPasswordService)
+	jwt := new(MockJWTService)
+	pub := new(MockEventPublisher)
+	logins := new(MockLoginRecorder)
+	guard := new(MockLoginGuard)
+	refresh := new(MockRefreshTokenRepository)
+	denylist := new(MockTokenDenylist)
+	uc := NewUserUseCase(repo, pass, jwt, pub, logins, guard, refresh, denylist, time.Hour)
+
+	repo.On("AuthenticateUser", "bob", mock.Anything).Return(domain.User{UserName: "bob", Password: "hashed", Disabled: true}, nil).Once()
+	pass.On("ComparePassword", "hashed", "plain").Return(nil).Once()
+	logins.On("RecordLogin", domain.LoginDisabled).Once()
+	guard.On("Check", "bob", "192.0.2.1").Return(nil).Once()
+
+	_, err := uc.LoginUser(context.Background(), "bob", "plain", "192.0.2.1")
+	assert.ErrorIs(t, err, domain.ErrUserDisabled)
+	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+	refresh.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+	logins.AssertExpectations(t)
+}
+
+func TestUserUseCase_RefreshToken_RejectsDisabledUsers(t *testing.T) {
+	uc, repo, jwt, refresh, _ := newSessionTestUseCase()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	refresh.On("UseRefreshToken", hashRefreshToken("old"), now).
+		Return(domain.RefreshToken{Family: "f", Username: "bob", ExpiresAt: now.Add(time.Minute)}, nil).Once()
+	repo.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob", Disabled: true}, nil).Once()
+
+	_, err := uc.RefreshToken(context.Background(), "old")
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	jwt.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+}
+
+func TestUserUseCase_ListUsers_ClampsThePage(t *testing.T) {
+	uc, repo, _, _, _ := newSessionTestUseCase()
+	users := []domain.User{{UserName: "bob"}}
+
+	repo.On("ListUsers", domain.UserQuery{Search: "bo", Offset: 0, Limit: domain.DefaultUserPageSize}).Return(users, int64(1), nil).Once()
+	page, err := uc.ListUsers(context.Background(), domain.UserQuery{Search: "bo", Offset: -5})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.UserPage{Users: users, Total: 1, Offset: 0, Limit: domain.DefaultUserPageSize}, page)
+
+	repo.On("ListUsers", domain.UserQuery{Offset: 40, Limit: domain.MaxUserPageSize}).Return([]domain.User{}, int64(41), nil).Once()
+	page, err = uc.ListUsers(context.Background(), domain.UserQuery{Offset: 40, Limit: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MaxUserPageSize, page.Limit)
+	repo.AssertExpectations(t)
+}
+
+func TestUserUseCase_KeepsTheLastEnabledAdmin(t *testing.T) {
+	uc, repo, _, refresh, _ := newSessionTestUseCase()
+	ctx := context.Background()
+
+	repo.On("GetUserByUsername", "root").Return(domain.User{UserName: "root", Role: domain.RoleAdmin}, nil)
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(1), nil)
+	assert.ErrorIs(t, uc.DemoteUser(ctx, "root"), domain.ErrLastAdmin)
+	assert.ErrorIs(t, uc.DisableUser(ctx, "root"), domain.ErrLastAdmin)
+	assert.ErrorIs(t, uc.DeleteUser(ctx, "root"), domain.ErrLastAdmin)
+	repo.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "SetUserDisabled", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "DeleteUser", mock.Anything)
+
+	// a disabled admin no longer counts, so it can go
+	repo.On("GetUserByUsername", "old-root").Return(domain.User{UserName: "old-root", Role: domain.RoleAdmin, Disabled: true}, nil)
+	repo.On("DeleteUser", "old-root").Return(nil).Once()
+	refresh.On("RevokeUserRefreshTokens", "old-root").Return(nil).Once()
+	assert.NoError(t, uc.DeleteUser(ctx, "old-root"))
+	repo.AssertExpectations(t)
+	refresh.AssertNotCalled(t, "RevokeUserRefreshTokens", "root")
+}
+
+func TestUserUseCase_DemoteDisableEnableDelete(t *testing.T) {
+	uc, repo, _, refresh, _ := newSessionTestUseCase()
+	ctx := context.Background()
+
+	repo.On("GetUserByUsername", "alice").Return(domain.User{UserName: "alice", Role: domain.RoleAdmin}, nil)
+	repo.On("CountEnabledUsersWithRole", domain.RoleAdmin).Return(int64(2), nil)
+	repo.On("SetUserRole", "alice", domain.RoleUser).Return(nil).Once()
+	assert.NoError(t, uc.DemoteUser(ctx, "alice"))
+
+	repo.On("GetUserByUsername", "bob").Return(domain.User{UserName: "bob", Role: domain.RoleUser}, nil)
+	repo.On("SetUserDisabled", "bob", true).Return(nil).Once()
+	repo.On("SetUserDisabled", "bob", false).Return(nil).Once()
+	repo.On("DeleteUser", "bob").Return(nil).Once()
+	// both disabling and deleting end every session bob still has
+	refresh.On("RevokeUserRefreshTokens", "bob").Return(nil).Twice()
+	assert.NoError(t, uc.DisableUser(ctx, "bob"))
+	assert.NoError(t, uc.EnableUser(ctx, "bob"))
+	assert.NoError(t, uc.DeleteUser(ctx, "bob"))
+
+	repo.On("GetUserByUsername", "ghost").Return(domain.User{}, domain.ErrUserNotFound)
+	assert.ErrorIs(t, uc.DisableUser(ctx, "ghost"), domain.ErrUserNotFound)
 	repo.AssertExpectations(t)
+	refresh.AssertExpectations(t)
 }
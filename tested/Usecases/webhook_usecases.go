@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+)
+
+type WebhookUseCaseImpl struct {
+	webhookRepository domain.WebhookRepository
+	dispatcher        infrastructure.WebhookDispatcher
+}
+
+func NewWebhookUseCase(
+	webhookRepository domain.WebhookRepository,
+	dispatcher infrastructure.WebhookDispatcher,
+) domain.WebhookUseCase {
+	return &WebhookUseCaseImpl{
+		webhookRepository: webhookRepository,
+		dispatcher:        dispatcher,
+	}
+}
+
+func (w *WebhookUseCaseImpl) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.CreatedAt = time.Now().UTC()
+	created, err := w.webhookRepository.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	audit(ctx, "webhook.create", "webhook_id", created.ID.Hex(), "url", created.URL)
+	return created, nil
+}
+
+func (w *WebhookUseCaseImpl) GetAllWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return w.webhookRepository.GetAllWebhooks(ctx)
+}
+
+func (w *WebhookUseCaseImpl) DeleteWebhook(ctx context.Context, id string) error {
+	if err := w.webhookRepository.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+	audit(ctx, "webhook.delete", "webhook_id", id)
+	return nil
+}
+
+func (w *WebhookUseCaseImpl) GetDeadLetters(ctx context.Context) ([]domain.WebhookDelivery, error) {
+	return w.webhookRepository.GetDeadLetters(ctx)
+}
+
+func (w *WebhookUseCaseImpl) RedeliverDeadLetter(ctx context.Context, id string) error {
+	delivery, err := w.webhookRepository.GetDeadLetterByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := w.webhookRepository.GetWebhookByID(ctx, delivery.WebhookID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if err := w.dispatcher.Deliver(ctx, webhook, delivery.Event); err != nil {
+		return err
+	}
+
+	return w.webhookRepository.DeleteDeadLetter(ctx, id)
+}
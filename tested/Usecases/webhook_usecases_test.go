@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "task-manager/Domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWebhookUseCase_CreateWebhook_Validation(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	dispatcher := new(MockWebhookDispatcher)
+	uc := NewWebhookUseCase(repo, dispatcher)
+
+	_, err := uc.CreateWebhook(context.Background(), domain.Webhook{URL: "not a url", Secret: "s"})
+	assert.ErrorIs(t, err, domain.ErrInvalidWebhookURL)
+
+	_, err = uc.CreateWebhook(context.Background(), domain.Webhook{URL: "https://example.com/hook", Secret: " "})
+	assert.ErrorIs(t, err, domain.ErrInvalidWebhookSecret)
+
+	_, err = uc.CreateWebhook(context.Background(), domain.Webhook{URL: "https://example.com/hook", Secret: "s", Events: []string{"task.exploded"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidWebhookEvent)
+
+	repo.AssertNotCalled(t, "CreateWebhook", mock.Anything)
+}
+
+func TestWebhookUseCase_CreateWebhook_Success(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	dispatcher := new(MockWebhookDispatcher)
+	uc := NewWebhookUseCase(repo, dispatcher)
+
+	webhook := domain.Webhook{URL: "https://example.com/hook", Secret: "s", Events: []string{domain.EventTaskCreated}}
+	repo.On("CreateWebhook", mock.MatchedBy(func(w domain.Webhook) bool {
+		return w.URL == webhook.URL && !w.CreatedAt.IsZero()
+	})).Return(webhook, nil).Once()
+
+	got, err := uc.CreateWebhook(context.Background(), webhook)
+	assert.NoError(t, err)
+	assert.Equal(t, webhook.URL, got.URL)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookUseCase_RedeliverDeadLetter_Success(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	dispatcher := new(MockWebhookDispatcher)
+	uc := NewWebhookUseCase(repo, dispatcher)
+
+	webhook := domain.Webhook{ID: primitive.NewObjectID(), URL: "https://example.com/hook", Secret: "s"}
+	delivery := domain.WebhookDelivery{WebhookID: webhook.ID, Event: domain.NewEvent(domain.EventTaskDeleted)}
+
+	repo.On("GetDeadLetterByID", "dl1").Return(delivery, nil).Once()
+	repo.On("GetWebhookByID", webhook.ID.Hex()).Return(webhook, nil).Once()
+	dispatcher.On("Deliver", webhook, delivery.Event).Return(nil).Once()
+	repo.On("DeleteDeadLetter", "dl1").Return(nil).Once()
+
+	assert.NoError(t, uc.RedeliverDeadLetter(context.Background(), "dl1"))
+	repo.AssertExpectations(t)
+	dispatcher.AssertExpectations(t)
+}
+
+func TestWebhookUseCase_RedeliverDeadLetter_FailureKeepsDeadLetter(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	dispatcher := new(MockWebhookDispatcher)
+	uc := NewWebhookUseCase(repo, dispatcher)
+
+	webhook := domain.Webhook{ID: primitive.NewObjectID(), URL: "https://example.com/hook", Secret: "s"}
+	delivery := domain.WebhookDelivery{WebhookID: webhook.ID, Event: domain.NewEvent(domain.EventTaskDeleted)}
+
+	repo.On("GetDeadLetterByID", "dl1").Return(delivery, nil).Once()
+	repo.On("GetWebhookByID", webhook.ID.Hex()).Return(webhook, nil).Once()
+	dispatcher.On("Deliver", webhook, delivery.Event).Return(errors.New("connection refused")).Once()
+
+	assert.Error(t, uc.RedeliverDeadLetter(context.Background(), "dl1"))
+	repo.AssertNotCalled(t, "DeleteDeadLetter", mock.Anything)
+}
+
+func TestWebhookUseCase_RedeliverDeadLetter_NotFound(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	dispatcher := new(MockWebhookDispatcher)
+	uc := NewWebhookUseCase(repo, dispatcher)
+
+	repo.On("GetDeadLetterByID", "missing").Return(domain.WebhookDelivery{}, domain.ErrDeadLetterNotFound).Once()
+
+	assert.ErrorIs(t, uc.RedeliverDeadLetter(context.Background(), "missing"), domain.ErrDeadLetterNotFound)
+	dispatcher.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+}
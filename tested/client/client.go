@@ -0,0 +1,280 @@
+// Package client is a Go SDK for the task manager HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiry a stored token is renewed.
+const tokenRefreshMargin = time.Minute
+
+type Config struct {
+	// BaseURL is the server root, e.g. http://localhost:8080.
+	BaseURL string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+	// MaxRetries is how many times idempotent requests (GET, PUT, DELETE)
+	// are retried after a network error or a 502, 503 or 504.
+	MaxRetries int
+	// RetryDelay is the first backoff delay; it doubles on every retry.
+	RetryDelay time.Duration
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	username  string
+	password  string
+}
+
+func New(config Config) *Client {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	retryDelay := config.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = 100 * time.Millisecond
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		httpClient: httpClient,
+		maxRetries: config.MaxRetries,
+		retryDelay: retryDelay,
+	}
+}
+
+// Login exchanges credentials for a token and keeps both, so that the
+// client can log in again by itself when the token expires or is rejected.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	token, err := c.login(ctx, username, password)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.username, c.password = username, password
+	c.setTokenLocked(token)
+	return nil
+}
+
+// SetToken uses an existing token instead of logging in. The client cannot
+// renew such a token unless Login has been called as well.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setTokenLocked(token)
+}
+
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) login(ctx context.Context, username, password string) (string, error) {
+	var resp struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	body := map[string]string{"username": username, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/v2/login", body, &resp, false); err != nil {
+		return "", err
+	}
+	return resp.Data.Token, nil
+}
+
+func (c *Client) setTokenLocked(token string) {
+	c.token = token
+	c.expiresAt = tokenExpiry(token)
+}
+
+// currentToken returns the stored token, logging in again first when it is
+// about to expire and credentials are known.
+func (c *Client) currentToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, username := c.token, c.username
+	stale := !c.expiresAt.IsZero() && time.Until(c.expiresAt) < tokenRefreshMargin
+	c.mu.Unlock()
+
+	if !stale || username == "" {
+		return token, nil
+	}
+	return c.relogin(ctx)
+}
+
+func (c *Client) relogin(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	username, password := c.username, c.password
+	c.mu.Unlock()
+
+	token, err := c.login(ctx, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setTokenLocked(token)
+	return token, nil
+}
+
+func (c *Client) canRelogin() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.username != ""
+}
+
+// do sends a JSON request and decodes a 2xx response into out. Failures are
+// returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}, authenticated bool) error {
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete {
+		attempts += c.maxRetries
+	}
+	reauthenticated := false
+
+	for attempt := 0; ; attempt++ {
+		token := ""
+		if authenticated {
+			var err error
+			if token, err = c.currentToken(ctx); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.send(ctx, method, path, payload, token)
+		if err != nil {
+			if ctx.Err() != nil || attempt+1 >= attempts {
+				return err
+			}
+			if err := c.backoff(ctx, attempt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && authenticated && !reauthenticated && c.canRelogin() {
+			resp.Body.Close()
+			reauthenticated = true
+			if _, err := c.relogin(ctx); err != nil {
+				return err
+			}
+			attempt--
+			continue
+		}
+
+		if isRetryableStatus(resp.StatusCode) && attempt+1 < attempts {
+			resp.Body.Close()
+			if err := c.backoff(ctx, attempt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return decodeResponse(resp, out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, token string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) backoff(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(c.retryDelay << attempt)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp.StatusCode, body)
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// tokenExpiry reads the exp claim without verifying the token; the server
+// remains the only judge of validity.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
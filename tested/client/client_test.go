@@ -0,0 +1,587 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"task-manager/Delivery/controllers"
+	"task-manager/Delivery/graph"
+	"task-manager/Delivery/openapi"
+	"task-manager/Delivery/routers"
+	domain "task-manager/Domain"
+	infrastructure "task-manager/Infrastructure"
+	usecases "task-manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTaskRepository struct {
+	mu    sync.Mutex
+	tasks map[int]domain.Task
+	next  int
+}
+
+func (r *memoryTaskRepository) GetAllTasks(_ context.Context) ([]domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tasks := []domain.Task{}
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].UserID < tasks[j].UserID })
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) GetTaskByID(_ context.Context, userID int) (domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[userID]
+	if !ok {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (r *memoryTaskRepository) CreateTask(_ context.Context, task domain.Task) (domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	task.UserID = r.next
+	r.tasks[task.UserID] = task
+	return task, nil
+}
+
+func (r *memoryTaskRepository) UpdateTask(_ context.Context, userID int, task domain.Task) (domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[userID]; !ok {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+	task.UserID = userID
+	r.tasks[userID] = task
+	return task, nil
+}
+
+func (r *memoryTaskRepository) DeleteTask(_ context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[userID]; !ok {
+		return domain.ErrTaskNotFound
+	}
+	delete(r.tasks, userID)
+	return nil
+}
+
+type memoryUserRepository struct {
+	mu    sync.Mutex
+	users map[string]domain.User
+}
+
+func (r *memoryUserRepository) RegisterUser(_ context.Context, username, password, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[username]; ok {
+		return domain.ErrUsernameTaken
+	}
+	r.users[username] = domain.User{ID: primitive.NewObjectID(), UserName: username, Password: password, Role: "user", Email: email}
+	return nil
+}
+
+func (r *memoryUserRepository) AuthenticateUser(ctx context.Context, username, password string) (domain.User, error) {
+	return r.GetUserByUsername(ctx, username)
+}
+
+func (r *memoryUserRepository) GetUserByUsername(_ context.Context, username string) (domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) GetAllUsers(_ context.Context) ([]domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := []domain.User{}
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) PromoteUser(_ context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Role = "Admin"
+	user.TokenVersion++
+	r.users[username] = user
+	return nil
+}
+
+func (r *memoryUserRepository) SetUserRole(_ context.Context, username, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Role = role
+	user.TokenVersion++
+	r.users[username] = user
+	return nil
+}
+
+func (r *memoryUserRepository) CountUsersWithRole(_ context.Context, role string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, user := range r.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepository) CountEnabledUsersWithRole(_ context.Context, role string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, user := range r.users {
+		if user.Role == role && !user.Disabled {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepository) ListUsers(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
+	all, _ := r.GetAllUsers(ctx)
+	users := []domain.User{}
+	for _, user := range all {
+		if strings.Contains(strings.ToLower(user.UserName), strings.ToLower(query.Search)) {
+			user.Password = ""
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserName < users[j].UserName })
+	total := int64(len(users))
+	users = users[min(query.Offset, len(users)):]
+	return users[:min(query.Limit, len(users))], total, nil
+}
+
+func (r *memoryUserRepository) SetUserDisabled(_ context.Context, username string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Disabled = disabled
+	user.TokenVersion++
+	r.users[username] = user
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteUser(_ context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[username]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.users, username)
+	return nil
+}
+
+type discardPublisher struct{}
+
+func (discardPublisher) Publish(domain.Event) {}
+
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]domain.RefreshToken
+}
+
+func (r *memoryRefreshTokenRepository) CreateRefreshToken(_ context.Context, token domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.ID] = token
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) UseRefreshToken(_ context.Context, id string, now time.Time) (domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok {
+		return domain.RefreshToken{}, domain.ErrInvalidRefreshToken
+	}
+	if token.UsedAt.IsZero() {
+		used := token
+		used.UsedAt = now
+		r.tokens[id] = used
+	}
+	return token, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeRefreshTokenFamily(_ context.Context, family string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.Family == family {
+			token.Revoked = true
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeUserRefreshTokens(_ context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, token := range r.tokens {
+		if token.Username == username {
+			token.Revoked = true
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// allowLogins never slows down or locks out a login.
+type allowLogins struct{}
+
+func (allowLogins) Check(context.Context, string, string) error { return nil }
+func (allowLogins) Failed(context.Context, string, string)      {}
+func (allowLogins) Succeeded(context.Context, string)           {}
+func (allowLogins) Unlock(context.Context, string) error        { return nil }
+
+// newTestServer runs the real router over in-memory repositories, with an
+// "admin" / "adminpass" account already promoted.
+func newTestServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+
+	passwordService := infrastructure.NewPasswordService()
+	_, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := infrastructure.NewKeySet(time.Hour, infrastructure.SigningKey{ID: "test", Algorithm: infrastructure.AlgorithmEdDSA, Key: signingKey})
+	require.NoError(t, err)
+	jwtService := infrastructure.NewKeySetJWTService(keys, infrastructure.JWTOptions{Expiry: time.Hour})
+	taskRepository := &memoryTaskRepository{tasks: map[int]domain.Task{}}
+	userRepository := &memoryUserRepository{users: map[string]domain.User{}}
+
+	hashed, err := passwordService.HashPassword("adminpass")
+	require.NoError(t, err)
+	userRepository.users["admin"] = domain.User{ID: primitive.NewObjectID(), UserName: "admin", Password: hashed, Role: "Admin"}
+
+	taskUseCase := usecases.NewTaskUseCase(taskRepository, discardPublisher{}, infrastructure.NewPolicyEngine(nil))
+	denylist := infrastructure.NewMemoryTokenDenylist()
+	refreshTokens := &memoryRefreshTokenRepository{tokens: map[string]domain.RefreshToken{}}
+	userUseCase := usecases.NewUserUseCase(userRepository, passwordService, jwtService, discardPublisher{}, infrastructure.NewMetrics(), allowLogins{},
+		refreshTokens, denylist, 24*time.Hour)
+	broker := infrastructure.NewTaskEventBroker(10)
+	auth := infrastructure.NewAuthMiddleware(jwtService, denylist, domain.BuiltInRoleResolver, userRepository)
+
+	router := routers.NewRouter(
+		controllers.NewTaskController(taskUseCase),
+		controllers.NewUserController(userUseCase),
+		controllers.NewCommentController(nil),
+		controllers.NewWebhookController(nil),
+		controllers.NewEventController(broker, auth, time.Second),
+		controllers.NewWebSocketController(taskUseCase, broker, auth, time.Second),
+		controllers.NewTaskControllerV2(taskUseCase),
+		controllers.NewUserControllerV2(userUseCase),
+		controllers.NewCommentControllerV2(nil),
+		controllers.NewHealthController(time.Second),
+		controllers.NewJWKSController(keys),
+		controllers.NewRoleController(nil),
+		controllers.NewPolicyController(nil, nil),
+		graph.NewHandler(graphql.Schema{}, graph.Limits{}),
+		openapi.NewHandler(),
+		nil,
+		nil,
+		infrastructure.NewMetrics(),
+		routers.RateLimits{},
+		auth,
+	)
+
+	server := httptest.NewServer(router.SetupRoutes())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_TaskCRUD(t *testing.T) {
+	server := newTestServer(t)
+	c := New(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	require.NoError(t, c.Login(ctx, "admin", "adminpass"))
+	assert.NotEmpty(t, c.Token())
+
+	due := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	created, err := c.CreateTask(ctx, TaskInput{Title: "Ship", Description: "the SDK", DueDate: &due, Project: "api"})
+	require.NoError(t, err)
+	assert.Equal(t, "1", created.ID)
+	assert.Equal(t, "api", created.Project)
+	require.NotNil(t, created.DueDate)
+	assert.True(t, due.Equal(*created.DueDate))
+
+	fetched, err := c.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	updated, err := c.UpdateTask(ctx, created.ID, TaskInput{Title: "Ship", Description: "the SDK", Status: "done"})
+	require.NoError(t, err)
+	assert.Equal(t, "done", updated.Status)
+
+	tasks, err := c.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "done", tasks[0].Status)
+
+	require.NoError(t, c.DeleteTask(ctx, created.ID))
+	_, err = c.GetTask(ctx, created.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "not_found", apiErr.Code)
+}
+
+func TestServer_RefreshRotatesAndLogoutRevokes(t *testing.T) {
+	server := newTestServer(t)
+	c := New(Config{BaseURL: server.URL})
+	ctx := context.Background()
+	type tokens struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	var login tokens
+	require.NoError(t, c.do(ctx, http.MethodPost, "/v1/login", map[string]string{"username": "admin", "password": "adminpass"}, &login, false))
+	require.NotEmpty(t, login.RefreshToken)
+
+	var rotated tokens
+	require.NoError(t, c.do(ctx, http.MethodPost, "/v1/token/refresh", map[string]string{"refresh_token": login.RefreshToken}, &rotated, false))
+	assert.NotEqual(t, login.RefreshToken, rotated.RefreshToken)
+
+	c.SetToken(rotated.Token)
+	_, err := c.ListTasks(ctx)
+	require.NoError(t, err)
+
+	// Replaying the first refresh token ends the session, including the
+	// access token the legitimate refresh produced.
+	err = c.do(ctx, http.MethodPost, "/v1/token/refresh", map[string]string{"refresh_token": login.RefreshToken}, nil, false)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	err = c.do(ctx, http.MethodPost, "/v1/token/refresh", map[string]string{"refresh_token": rotated.RefreshToken}, nil, false)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = c.ListTasks(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	require.NoError(t, c.do(ctx, http.MethodPost, "/v1/login", map[string]string{"username": "admin", "password": "adminpass"}, &login, false))
+	c.SetToken(login.Token)
+	require.NoError(t, c.do(ctx, http.MethodPost, "/v1/logout", nil, nil, true))
+	_, err = c.ListTasks(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	err = c.do(ctx, http.MethodPost, "/v1/token/refresh", map[string]string{"refresh_token": login.RefreshToken}, nil, false)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	server := newTestServer(t)
+	c := New(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	err := c.Login(ctx, "admin", "wrong")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = c.ListTasks(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "missing or invalid token", apiErr.Message)
+
+	_, err = c.Register(ctx, "admin", "password123", "")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	user, err := c.Register(ctx, "bob", "password123", "bob@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, User{Username: "bob", Role: "user", Email: "bob@example.com"}, user)
+
+	require.NoError(t, c.Login(ctx, "bob", "password123"))
+	_, err = c.CreateTask(ctx, TaskInput{Title: "t", Description: "d"})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	admin := New(Config{BaseURL: server.URL})
+	require.NoError(t, admin.Login(ctx, "admin", "adminpass"))
+	_, err = admin.CreateTask(ctx, TaskInput{Description: "d"})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, admin.PromoteUser(ctx, "nobody"), ErrNotFound)
+}
+
+func TestClient_PromoteUser(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	bob := New(Config{BaseURL: server.URL})
+	_, err := bob.Register(ctx, "bob", "password123", "")
+	require.NoError(t, err)
+	require.NoError(t, bob.Login(ctx, "bob", "password123"))
+	userToken := bob.Token()
+
+	admin := New(Config{BaseURL: server.URL})
+	require.NoError(t, admin.Login(ctx, "admin", "adminpass"))
+	require.NoError(t, admin.PromoteUser(ctx, "bob"))
+
+	// The token from before the promotion stops working straight away, and
+	// the client logs in again to get one carrying the new role.
+	_, err = bob.CreateTask(ctx, TaskInput{Title: "t", Description: "d"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, userToken, bob.Token())
+
+	stale := New(Config{BaseURL: server.URL})
+	stale.SetToken(userToken)
+	_, err = stale.ListTasks(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, infrastructure.ErrStaleToken.Error(), apiErr.Message)
+}
+
+func TestServer_TokenDiesWithItsUser(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	bob := New(Config{BaseURL: server.URL})
+	_, err := bob.Register(ctx, "bob", "password123", "")
+	require.NoError(t, err)
+	admin := New(Config{BaseURL: server.URL})
+	require.NoError(t, admin.Login(ctx, "admin", "adminpass"))
+	require.NoError(t, admin.PromoteUser(ctx, "bob"))
+	var login struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	require.NoError(t, bob.do(ctx, http.MethodPost, "/v1/login", map[string]string{"username": "bob", "password": "password123"}, &login, false))
+
+	require.NoError(t, admin.do(ctx, http.MethodDelete, "/v1/users/bob", nil, nil, true))
+	_, err = New(Config{BaseURL: server.URL}).Register(ctx, "bob", "otherpass1", "")
+	require.NoError(t, err)
+
+	// The new bob starts over at token version 0, below the old token's, so
+	// only the user id tells the two apart.
+	old := New(Config{BaseURL: server.URL})
+	old.SetToken(login.Token)
+	_, err = old.CreateTask(ctx, TaskInput{Title: "t", Description: "d"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// Nor does the old refresh token mint tokens for the new bob.
+	err = old.do(ctx, http.MethodPost, "/v1/token/refresh", map[string]string{"refresh_token": login.RefreshToken}, nil, false)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestClient_LogsInAgainWhenTokenIsRejected(t *testing.T) {
+	server := newTestServer(t)
+	c := New(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	require.NoError(t, c.Login(ctx, "admin", "adminpass"))
+	c.SetToken("not-a-jwt")
+
+	_, err := c.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, "not-a-jwt", c.Token())
+}
+
+func TestClient_RenewsTokenBeforeExpiry(t *testing.T) {
+	server := newTestServer(t)
+	c := New(Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	require.NoError(t, c.Login(ctx, "admin", "adminpass"))
+	original := c.Token()
+
+	c.mu.Lock()
+	c.expiresAt = time.Now().Add(time.Second)
+	c.mu.Unlock()
+
+	// Tokens minted within the same second are identical, so wait for the
+	// next one before checking that a new token was fetched.
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	_, err := c.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, original, c.Token())
+}
+
+func TestClient_RetriesIdempotentRequests(t *testing.T) {
+	server := newTestServer(t)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	var failures, posts atomic.Int32
+	failures.Store(2)
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v2/tasks" {
+			posts.Add(1)
+		}
+		if r.URL.Path != "/v2/login" && failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	c := New(Config{BaseURL: flaky.URL, MaxRetries: 2, RetryDelay: time.Millisecond})
+	ctx := context.Background()
+	require.NoError(t, c.Login(ctx, "admin", "adminpass"))
+
+	tasks, err := c.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	assert.Equal(t, int32(-1), failures.Load())
+
+	failures.Store(1)
+	_, err = c.CreateTask(ctx, TaskInput{Title: "t", Description: "d"})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int32(1), posts.Load())
+}
+
+func TestClient_HonoursContextCancellation(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	c := New(Config{BaseURL: unavailable.URL, MaxRetries: 10, RetryDelay: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.ListTasks(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
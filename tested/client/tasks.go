@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Task mirrors the v2 task representation.
+type Task struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      string     `json:"status"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Project     string     `json:"project,omitempty"`
+}
+
+type TaskInput struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Project     string     `json:"project,omitempty"`
+}
+
+type User struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Email    string `json:"email,omitempty"`
+}
+
+func (c *Client) ListTasks(ctx context.Context) ([]Task, error) {
+	var resp struct {
+		Data []Task `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v2/tasks", nil, &resp, true); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (c *Client) GetTask(ctx context.Context, id string) (Task, error) {
+	var resp struct {
+		Data Task `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/v2/tasks/"+url.PathEscape(id), nil, &resp, true)
+	return resp.Data, err
+}
+
+// CreateTask is not retried, since a retry could create the task twice.
+func (c *Client) CreateTask(ctx context.Context, input TaskInput) (Task, error) {
+	var resp struct {
+		Data Task `json:"data"`
+	}
+	err := c.do(ctx, http.MethodPost, "/v2/tasks", input, &resp, true)
+	return resp.Data, err
+}
+
+func (c *Client) UpdateTask(ctx context.Context, id string, input TaskInput) (Task, error) {
+	var resp struct {
+		Data Task `json:"data"`
+	}
+	err := c.do(ctx, http.MethodPut, "/v2/tasks/"+url.PathEscape(id), input, &resp, true)
+	return resp.Data, err
+}
+
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/v2/tasks/"+url.PathEscape(id), nil, nil, true)
+}
+
+func (c *Client) Register(ctx context.Context, username, password, email string) (User, error) {
+	var resp struct {
+		Data User `json:"data"`
+	}
+	body := map[string]string{"username": username, "password": password, "email": email}
+	err := c.do(ctx, http.MethodPost, "/v2/register", body, &resp, false)
+	return resp.Data, err
+}
+
+// PromoteUser makes username an Admin. It is only available under /v1.
+func (c *Client) PromoteUser(ctx context.Context, username string) error {
+	return c.do(ctx, http.MethodPost, "/v1/promote/"+url.PathEscape(username), nil, nil, true)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"task-manager/client"
+)
+
+var errEditAborted = errors.New("edit aborted: the file was left empty")
+
+// editTask opens input as JSON in $VISUAL or $EDITOR and returns what the
+// user saved.
+func editTask(input client.TaskInput) (client.TaskInput, error) {
+	data, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return input, err
+	}
+
+	file, err := os.CreateTemp("", "taskctl-*.json")
+	if err != nil {
+		return input, err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return input, err
+	}
+	if err := file.Close(); err != nil {
+		return input, err
+	}
+
+	if err := runEditor(file.Name()); err != nil {
+		return input, err
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return input, err
+	}
+	if len(bytes.TrimSpace(edited)) == 0 {
+		return input, errEditAborted
+	}
+
+	var result client.TaskInput
+	if err := json.Unmarshal(edited, &result); err != nil {
+		return input, fmt.Errorf("parsing edited task: %w", err)
+	}
+	return result, nil
+}
+
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// Go through the shell so that EDITOR="code --wait" works.
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", editor+" "+path)
+	} else {
+		cmd = exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running editor %q: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Command taskctl is a command-line client for the task manager API.
+//
+//	taskctl [-server URL] [-config FILE] <command> [flags] [args]
+//
+// Run "taskctl help" for the list of commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"task-manager/client"
+
+	"golang.org/x/term"
+)
+
+const usage = `usage: taskctl [-server URL] [-config FILE] <command> [flags] [args]
+
+commands:
+  login [-username NAME] [-password PASS]  log in and cache the token
+  logout                                   forget the cached token
+  list [-status S] [-assignee A] [-project P] [-search TEXT] [-o table|json]
+  get [-o table|json] ID
+  create [task flags] [-edit]              create a task from flags or $EDITOR
+  update [task flags] [-edit] ID           change a task from flags or $EDITOR
+  delete ID
+  promote USERNAME                         make a user an Admin
+
+task flags: -title, -description, -due (YYYY-MM-DD or RFC 3339),
+            -status, -assignee, -project
+`
+
+var errUsage = errors.New("invalid usage")
+
+type app struct {
+	configPath string
+	config     config
+	client     *client.Client
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("taskctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	server := fs.String("server", os.Getenv("TASKCTL_SERVER"), "API base URL")
+	configPath := fs.String("config", "", "config file (default $TASKCTL_CONFIG or the user config dir)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	a := &app{configPath: *configPath, stdin: stdin, stdout: stdout, stderr: stderr}
+	if err := a.init(*server); err != nil {
+		fmt.Fprintln(stderr, "taskctl:", err)
+		return 1
+	}
+
+	err := a.dispatch(ctx, fs.Arg(0), fs.Args()[1:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, "taskctl:", err)
+		}
+		fmt.Fprint(stderr, usage)
+		return 2
+	case errors.Is(err, client.ErrUnauthorized):
+		fmt.Fprintln(stderr, "taskctl: not logged in or the session expired; run \"taskctl login\"")
+		return 1
+	default:
+		fmt.Fprintln(stderr, "taskctl:", err)
+		return 1
+	}
+}
+
+func (a *app) init(server string) error {
+	if a.configPath == "" {
+		path, err := defaultConfigPath()
+		if err != nil {
+			return err
+		}
+		a.configPath = path
+	}
+
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", a.configPath, err)
+	}
+	if server != "" {
+		cfg.Server = server
+	}
+	if cfg.Server == "" {
+		cfg.Server = defaultServer
+	}
+	a.config = cfg
+
+	a.client = client.New(client.Config{BaseURL: cfg.Server, MaxRetries: 2})
+	a.client.SetToken(cfg.Token)
+	return nil
+}
+
+func (a *app) dispatch(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "login":
+		return a.login(ctx, args)
+	case "logout":
+		return a.logout(args)
+	case "list":
+		return a.list(ctx, args)
+	case "get":
+		return a.get(ctx, args)
+	case "create":
+		return a.create(ctx, args)
+	case "update":
+		return a.update(ctx, args)
+	case "delete":
+		return a.delete(ctx, args)
+	case "promote":
+		return a.promote(ctx, args)
+	case "help":
+		return flag.ErrHelp
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, command)
+}
+
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses args and checks that exactly nargs positional
+// arguments remain.
+func parseFlags(fs *flag.FlagSet, args []string, nargs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %s: %v", errUsage, fs.Name(), err)
+	}
+	if fs.NArg() != nargs {
+		return fmt.Errorf("%w: %s expects %d argument(s), got %d", errUsage, fs.Name(), nargs, fs.NArg())
+	}
+	return nil
+}
+
+func (a *app) login(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("login")
+	username := fs.String("username", a.config.Username, "")
+	password := fs.String("password", os.Getenv("TASKCTL_PASSWORD"), "")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(a.stdin)
+	var err error
+	if *username == "" {
+		if *username, err = a.prompt(in, "Username: "); err != nil {
+			return err
+		}
+	}
+	if *password == "" {
+		if *password, err = a.promptPassword(in, "Password: "); err != nil {
+			return err
+		}
+	}
+
+	if err := a.client.Login(ctx, *username, *password); err != nil {
+		// Not wrapped: run would otherwise tell the user to log in.
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("login failed: %s", apiErr.Message)
+		}
+		return err
+	}
+	a.config.Username = *username
+	a.config.Token = a.client.Token()
+	if err := saveConfig(a.configPath, a.config); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Logged in to %s as %s\n", a.config.Server, *username)
+	return nil
+}
+
+func (a *app) logout(args []string) error {
+	if err := parseFlags(a.newFlagSet("logout"), args, 0); err != nil {
+		return err
+	}
+	a.config.Token = ""
+	return saveConfig(a.configPath, a.config)
+}
+
+func (a *app) promote(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("promote")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if err := a.client.PromoteUser(ctx, fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Promoted %s to Admin\n", fs.Arg(0))
+	return nil
+}
+
+func (a *app) prompt(in *bufio.Reader, label string) (string, error) {
+	fmt.Fprint(a.stderr, label)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// promptPassword does not echo when stdin is a terminal.
+func (a *app) promptPassword(in *bufio.Reader, label string) (string, error) {
+	if f, ok := a.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(a.stderr, label)
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(a.stderr)
+		return string(password), err
+	}
+	return a.prompt(in, label)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI serves the handful of v1/v2 endpoints taskctl uses and records
+// the task bodies it receives.
+type fakeAPI struct {
+	received []map[string]interface{}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path == "/v2/login" {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":"unauthorized","message":"invalid credentials"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"token":"tok-` + body["username"] + `"}}`))
+		return
+	}
+
+	if r.Header.Get("Authorization") != "token tok-alice" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Authorization header is required"}`))
+		return
+	}
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /v2/tasks":
+		w.Write([]byte(`{"data":[
+			{"id":"1","title":"Write docs","description":"","status":"open","project":"api"},
+			{"id":"2","title":"Fix login","description":"","status":"done","assignee":"bob","due_date":"2030-01-02T00:00:00Z"}
+		]}`))
+	case "GET /v2/tasks/2":
+		w.Write([]byte(`{"data":{"id":"2","title":"Fix login","description":"old","status":"done","assignee":"bob"}}`))
+	case "POST /v2/tasks", "PUT /v2/tasks/2":
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.received = append(f.received, body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":"2","title":"","description":"","status":""}}`))
+	case "POST /v1/promote/bob":
+		w.Write([]byte(`{"message":"User promoted to admin"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"not_found","message":"task not found"}}`))
+	}
+}
+
+type harness struct {
+	api        *fakeAPI
+	server     *httptest.Server
+	configPath string
+}
+
+func newHarness(t *testing.T) *harness {
+	api := &fakeAPI{}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return &harness{api: api, server: server, configPath: filepath.Join(t.TempDir(), "config.json")}
+}
+
+func (h *harness) run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-config", h.configPath, "-server", h.server.URL}, args...)
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func (h *harness) login(t *testing.T) {
+	code, _, stderr := h.run("alice\nsecret\n", "login")
+	require.Equal(t, 0, code, stderr)
+}
+
+func TestLogin_CachesTokenInConfigFile(t *testing.T) {
+	h := newHarness(t)
+
+	code, stdout, _ := h.run("alice\nsecret\n", "login")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "as alice")
+
+	cfg, err := loadConfig(h.configPath)
+	require.NoError(t, err)
+	assert.Equal(t, config{Server: h.server.URL, Username: "alice", Token: "tok-alice"}, cfg)
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(h.configPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	// The cached server and token are used without any flags.
+	var out bytes.Buffer
+	code = run(context.Background(), []string{"-config", h.configPath, "list"}, strings.NewReader(""), &out, &bytes.Buffer{})
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out.String(), "Write docs")
+}
+
+func TestLogin_WrongPassword(t *testing.T) {
+	h := newHarness(t)
+
+	code, _, stderr := h.run("", "login", "-username", "alice", "-password", "nope")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "invalid credentials")
+	_, err := os.Stat(h.configPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestList_WithoutLoginSuggestsLoggingIn(t *testing.T) {
+	h := newHarness(t)
+
+	code, _, stderr := h.run("", "list")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `run "taskctl login"`)
+}
+
+func TestList_TableAndFilters(t *testing.T) {
+	h := newHarness(t)
+	h.login(t)
+
+	code, stdout, _ := h.run("", "list")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^ID\s+STATUS\s+DUE\s+ASSIGNEE\s+PROJECT\s+TITLE$`, lines[0])
+	assert.Regexp(t, `^1\s+open\s+-\s+-\s+api\s+Write docs$`, lines[1])
+	assert.Regexp(t, `^2\s+done\s+2030-01-02\s+bob\s+-\s+Fix login$`, lines[2])
+
+	_, stdout, _ = h.run("", "list", "-status", "DONE", "-o", "json")
+	var tasks []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "2", tasks[0]["id"])
+
+	_, stdout, _ = h.run("", "list", "-search", "docs", "-o", "json")
+	require.NoError(t, json.Unmarshal([]byte(stdout), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "1", tasks[0]["id"])
+
+	_, stdout, _ = h.run("", "list", "-project", "none", "-o", "json")
+	assert.JSONEq(t, `[]`, stdout)
+}
+
+func TestCreate_FromFlags(t *testing.T) {
+	h := newHarness(t)
+	h.login(t)
+
+	code, stdout, stderr := h.run("", "create", "-title", "Ship", "-description", "it", "-due", "2030-05-06")
+
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Created task 2")
+	require.Len(t, h.api.received, 1)
+	assert.Equal(t, map[string]interface{}{
+		"title": "Ship", "description": "it", "due_date": "2030-05-06T00:00:00Z",
+	}, h.api.received[0])
+}
+
+func TestUpdate_KeepsFieldsThatWereNotGiven(t *testing.T) {
+	h := newHarness(t)
+	h.login(t)
+
+	code, _, stderr := h.run("", "update", "-status", "open", "2")
+
+	require.Equal(t, 0, code, stderr)
+	require.Len(t, h.api.received, 1)
+	assert.Equal(t, map[string]interface{}{
+		"title": "Fix login", "description": "old", "status": "open", "assignee": "bob",
+	}, h.api.received[0])
+}
+
+func TestUpdate_WithEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake editor is a shell command")
+	}
+	h := newHarness(t)
+	h.login(t)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", `sed -i.bak 's/"old"/"rewritten"/'`)
+
+	code, _, stderr := h.run("", "update", "-edit", "2")
+
+	require.Equal(t, 0, code, stderr)
+	require.Len(t, h.api.received, 1)
+	assert.Equal(t, "rewritten", h.api.received[0]["description"])
+	assert.Equal(t, "Fix login", h.api.received[0]["title"])
+}
+
+func TestUsageErrors(t *testing.T) {
+	h := newHarness(t)
+
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"delete"},
+		{"update", "2"},
+		{"create", "-due", "tomorrow"},
+		{"list", "-o", "yaml"},
+	} {
+		code, _, stderr := h.run("", args...)
+		assert.Equal(t, 2, code, "%v: %s", args, stderr)
+		assert.Contains(t, stderr, "usage: taskctl", "%v", args)
+	}
+}
+
+func TestPromoteAndDelete(t *testing.T) {
+	h := newHarness(t)
+	h.login(t)
+
+	code, stdout, _ := h.run("", "promote", "bob")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "Promoted bob")
+
+	code, _, stderr := h.run("", "delete", "9")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "task not found")
+}
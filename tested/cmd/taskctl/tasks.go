@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"task-manager/client"
+)
+
+// taskFlags are the flags shared by create and update.
+type taskFlags struct {
+	title, description, due, status, assignee, project string
+	edit                                               bool
+}
+
+func (f *taskFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.title, "title", "", "")
+	fs.StringVar(&f.description, "description", "", "")
+	fs.StringVar(&f.due, "due", "", "")
+	fs.StringVar(&f.status, "status", "", "")
+	fs.StringVar(&f.assignee, "assignee", "", "")
+	fs.StringVar(&f.project, "project", "", "")
+	fs.BoolVar(&f.edit, "edit", false, "")
+}
+
+// apply copies the flags given on the command line onto input.
+func (f *taskFlags) apply(fs *flag.FlagSet, input *client.TaskInput) error {
+	var err error
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "title":
+			input.Title = f.title
+		case "description":
+			input.Description = f.description
+		case "status":
+			input.Status = f.status
+		case "assignee":
+			input.Assignee = f.assignee
+		case "project":
+			input.Project = f.project
+		case "due":
+			input.DueDate, err = parseDue(f.due)
+		}
+	})
+	return err
+}
+
+// parseDue accepts a date, an RFC 3339 timestamp, or "" to clear the date.
+func parseDue(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if due, err := time.Parse(time.RFC3339, value); err == nil {
+		return &due, nil
+	}
+	due, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: -due must be YYYY-MM-DD or RFC 3339, got %q", errUsage, value)
+	}
+	return &due, nil
+}
+
+func inputFromTask(task client.Task) client.TaskInput {
+	return client.TaskInput{
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     task.DueDate,
+		Status:      task.Status,
+		Assignee:    task.Assignee,
+		Project:     task.Project,
+	}
+}
+
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("list")
+	status := fs.String("status", "", "")
+	assignee := fs.String("assignee", "", "")
+	project := fs.String("project", "", "")
+	search := fs.String("search", "", "")
+	output := fs.String("o", "table", "")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+
+	tasks, err := a.client.ListTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	filtered := []client.Task{}
+	needle := strings.ToLower(*search)
+	for _, task := range tasks {
+		if *status != "" && !strings.EqualFold(task.Status, *status) ||
+			*assignee != "" && task.Assignee != *assignee ||
+			*project != "" && task.Project != *project {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(task.Title+"\n"+task.Description), needle) {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+	return printTasks(a.stdout, *output, filtered)
+}
+
+func (a *app) get(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("get")
+	output := fs.String("o", "table", "")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+
+	task, err := a.client.GetTask(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return writeJSON(a.stdout, task)
+	}
+	return printTasks(a.stdout, *output, []client.Task{task})
+}
+
+func (a *app) create(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("create")
+	var flags taskFlags
+	flags.register(fs)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	var input client.TaskInput
+	if err := flags.apply(fs, &input); err != nil {
+		return err
+	}
+	if flags.edit {
+		var err error
+		if input, err = editTask(input); err != nil {
+			return err
+		}
+	}
+
+	task, err := a.client.CreateTask(ctx, input)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Created task %s\n", task.ID)
+	return nil
+}
+
+func (a *app) update(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("update")
+	var flags taskFlags
+	flags.register(fs)
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if !flags.edit && fs.NFlag() == 0 {
+		return fmt.Errorf("%w: update needs at least one task flag or -edit", errUsage)
+	}
+	id := fs.Arg(0)
+
+	// The API replaces the whole task, so start from its current state.
+	current, err := a.client.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	input := inputFromTask(current)
+	if err := flags.apply(fs, &input); err != nil {
+		return err
+	}
+	if flags.edit {
+		if input, err = editTask(input); err != nil {
+			return err
+		}
+	}
+
+	if _, err := a.client.UpdateTask(ctx, id, input); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Updated task %s\n", id)
+	return nil
+}
+
+func (a *app) delete(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("delete")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if err := a.client.DeleteTask(ctx, fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deleted task %s\n", fs.Arg(0))
+	return nil
+}
+
+func checkFormat(format string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("%w: unknown output format %q", errUsage, format)
+	}
+	return nil
+}
+
+func printTasks(w io.Writer, format string, tasks []client.Task) error {
+	if format == "json" {
+		return writeJSON(w, tasks)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDUE\tASSIGNEE\tPROJECT\tTITLE")
+	for _, task := range tasks {
+		due := ""
+		if task.DueDate != nil {
+			due = task.DueDate.Format(time.DateOnly)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			task.ID, dash(task.Status), dash(due), dash(task.Assignee), dash(task.Project), task.Title)
+	}
+	return tw.Flush()
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}